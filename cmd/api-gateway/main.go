@@ -1,130 +1,624 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
-	"flag"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	_ "github.com/lib/pq"
+	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	expertpb "portal.com/portal/pkg/expert/v1"
 	orchpb "portal.com/portal/pkg/orchestrator/v1"
+
+	"portal.com/portal/internal/auth"
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/crawl"
+	"portal.com/portal/internal/health"
+	"portal.com/portal/internal/llm"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/ratelimit"
+	"portal.com/portal/internal/rest"
+	"portal.com/portal/internal/shutdown"
+	"portal.com/portal/internal/telemetry"
+	"portal.com/portal/internal/tlsconfig"
+	"portal.com/portal/internal/urlnorm"
 )
 
 // config holds all the configuration for the service.
 type config struct {
-	httpPort      string
-	expertSvcAddr string
-	orchSvcAddr   string
+	httpPort          string
+	expertSvcAddr     string
+	orchSvcAddr       string
+	dbConn            string
+	natsURL           string
+	jwks              string
+	jwtIssuer         string
+	jwtAudience       string
+	anonymousRoutes   string
+	adminSubjects     string
+	rateLimitStore    string
+	keyRate           float64
+	keyBurst          int
+	ipRate            float64
+	ipBurst           int
+	dailyTokenQuota   int
+	trustForwardedFor bool
+	logLevel          string
+	logFormat         string
+	traceExporter     string
+	otlpEndpoint      string
+	metricsAddr       string
+	shutdownTimeout   time.Duration
+	tls               tlsconfig.Config
 }
 
-// apiServer holds the clients for the backend gRPC services.
+// apiServer holds the state shared by the gateway's handlers.
 type apiServer struct {
 	expertSvcClient expertpb.ExpertServiceClient
-	orchSvcClient   orchpb.QueryOrchestratorServiceClient
+	auth            *auth.Authenticator
+	// limiter may be nil, which disables rate limiting and quotas.
+	limiter           *ratelimit.Limiter
+	trustForwardedFor bool
+	// nc may be nil, which disables crawl requests.
+	nc *nats.Conn
+	// admins are the subjects allowed to call the /api/v1/admin routes.
+	admins map[string]bool
 }
 
-// searchHandler handles requests to the /search endpoint.
-func (s *apiServer) searchHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
-		return
+// authenticate verifies the caller's credentials and stores their identity
+// in the request context before calling next. Requests without credentials
+// are only let through if anonymous is set; invalid credentials are always
+// rejected.
+func (s *apiServer) authenticate(next http.Handler, anonymous bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := s.auth.Authenticate(r)
+		switch {
+		case err == nil:
+			r = r.WithContext(auth.WithIdentity(r.Context(), id))
+		case errors.Is(err, auth.ErrNoCredentials) && anonymous:
+		case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials), errors.Is(err, auth.ErrNotConfigured):
+			slog.InfoContext(r.Context(), "Rejected request", "path", r.URL.Path, "error", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="portal"`)
+			rest.WriteError(w, r, http.StatusUnauthorized, codes.Unauthenticated, err.Error())
+			return
+		default:
+			slog.ErrorContext(r.Context(), "Failed to authenticate request", "error", err)
+			rest.WriteError(w, r, http.StatusServiceUnavailable, codes.Unavailable, "authentication is unavailable")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAdmin rejects callers that are not administrators with 403
+// Forbidden. It must run after authenticate.
+func (s *apiServer) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := auth.FromContext(r.Context())
+		if id == nil || !s.admins[id.Subject] {
+			slog.InfoContext(r.Context(), "Rejected admin request", "path", r.URL.Path)
+			rest.WriteError(w, r, http.StatusForbidden, codes.PermissionDenied, "administrator access is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiService is a public gRPC service whose REST routes the gateway serves.
+type apiService struct {
+	desc protoreflect.ServiceDescriptor
+	conn grpc.ClientConnInterface
+}
+
+// registerAPI registers the REST routes of services with handle, rate
+// limited, and returns them. The /api/v1/admin routes, which change experts
+// and the content answers are drawn from, are limited to administrators.
+func (s *apiServer) registerAPI(handle func(pattern string, h http.Handler), services []apiService) ([]rest.Route, error) {
+	var routes []rest.Route
+	for _, svc := range services {
+		svcRoutes, err := rest.Routes(svc.desc)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range svcRoutes {
+			conn := svc.conn
+			if route.FullMethod() == expertpb.ExpertService_QueryExpert_FullMethodName {
+				conn = authenticatedFetch{conn}
+			}
+			var h http.Handler = &rest.Handler{Route: route, Conn: conn, OnTrailer: s.charge, Status: queryStatus}
+			if strings.HasPrefix(route.Path, "/api/v1/admin/") {
+				h = s.requireAdmin(h)
+			}
+			handle(route.Pattern(), s.limit(h))
+			if route.FullMethod() == orchpb.QueryOrchestratorService_Search_FullMethodName && route.Method == http.MethodPost {
+				handle("POST /search", deprecated(route.Path, s.limit(h)))
+			}
+		}
+		routes = append(routes, svcRoutes...)
 	}
+	return routes, nil
+}
 
-	var req orchpb.SearchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+// authenticatedFetch is a connection on which only authenticated callers
+// may set fetch_if_missing, as on the /e/{url} route: it is cleared from the
+// QueryExpert requests of anonymous callers.
+type authenticatedFetch struct {
+	grpc.ClientConnInterface
+}
+
+func (c authenticatedFetch) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if req, ok := args.(*expertpb.QueryExpertRequest); ok && auth.FromContext(ctx) == nil {
+		req.FetchIfMissing = false
 	}
+	return c.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+}
 
-	grpcRes, err := s.orchSvcClient.Search(r.Context(), &req)
+// clientIP returns the address of the caller of r. Behind a trusted proxy,
+// it is the last X-Forwarded-For entry, the one the proxy appended: the
+// entries before it come from the client and can be anything.
+func (s *apiServer) clientIP(r *http.Request) string {
+	if s.trustForwardedFor {
+		if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+			last := xff[len(xff)-1]
+			if i := strings.LastIndex(last, ","); i >= 0 {
+				last = last[i+1:]
+			}
+			if ip := strings.TrimSpace(last); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		// In a real app, inspect gRPC error code for better HTTP status mapping.
-		http.Error(w, "backend service error", http.StatusInternalServerError)
-		log.Printf("Error from orchestrator service: %v", err)
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientKey returns the key the caller of r is rate limited under.
+func (s *apiServer) clientKey(r *http.Request) string {
+	subject := ""
+	if id := auth.FromContext(r.Context()); id != nil {
+		subject = id.Subject
+	}
+	return ratelimit.ClientKey(subject, s.clientIP(r))
+}
+
+// limit rejects requests from callers over their rate limit or daily quota
+// with 429 Too Many Requests. If the limit store fails, requests are let
+// through rather than failing the whole API.
+func (s *apiServer) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		d, err := s.limiter.Allow(r.Context(), s.clientKey(r), auth.FromContext(r.Context()) != nil)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to check rate limit", "error", err)
+		} else if !d.Allowed {
+			slog.InfoContext(r.Context(), "Rate limited request", "client", s.clientKey(r), "reason", d.Reason)
+			w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(d.RetryAfter.Seconds())))))
+			rest.WriteError(w, r, http.StatusTooManyRequests, codes.ResourceExhausted, d.Reason)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// charge counts the LLM tokens a backend reported in its trailer against the
+// caller's daily quota.
+func (s *apiServer) charge(r *http.Request, trailer metadata.MD) {
+	if s.limiter == nil {
 		return
 	}
+	if err := s.limiter.Charge(r.Context(), s.clientKey(r), llm.UsageFromTrailer(trailer)); err != nil {
+		slog.WarnContext(r.Context(), "Failed to charge LLM usage", "error", err)
+	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(grpcRes)
+// collapsedScheme matches a scheme whose "//" was collapsed by a proxy or
+// client that cleaned the path, e.g. "https:/example.com".
+var collapsedScheme = regexp.MustCompile(`^(?i)(https?):/([^/])`)
+
+// expertTarget returns the URL addressed by a /e/{url} request. The URL may
+// be written out (/e/https://example.com/a?b=1, in which case the request's
+// query string belongs to it), percent-encoded as a single path segment
+// (/e/https%3A%2F%2Fexample.com%2Fa%3Fb%3D1), or a bare domain
+// (/e/example.com). Fragments are never sent by browsers and are ignored.
+func expertTarget(r *http.Request) (string, error) {
+	target := strings.TrimPrefix(r.URL.EscapedPath(), "/e/")
+	if !strings.Contains(target, "/") {
+		unescaped, err := url.PathUnescape(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL %q: %w", target, err)
+		}
+		target = unescaped
+	}
+	if target == "" {
+		return "", errors.New("URL path parameter is missing")
+	}
+	target = collapsedScheme.ReplaceAllString(target, "$1://$2")
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	if _, err := urlnorm.Canonical(target); err != nil {
+		return "", err
+	}
+	return target, nil
 }
 
-// expertHandler handles requests to the /e/{url} endpoint.
+// expertHandler handles requests to the /e/{url} endpoint, which queries the
+// expert for the URL in the path. It must be called with the raw request
+// path, before http.ServeMux collapses the "//" in the URL.
 func (s *apiServer) expertHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", http.MethodPost)
+		rest.WriteError(w, r, http.StatusMethodNotAllowed, codes.Unimplemented, "only POST method is allowed")
 		return
 	}
-
-	// Extract URL from path, e.g., /e/https://example.com
-	url := strings.TrimPrefix(r.URL.Path, "/e/")
-	if url == "" {
-		http.Error(w, "URL path parameter is missing", http.StatusBadRequest)
+	target, err := expertTarget(r)
+	if err != nil {
+		rest.WriteError(w, r, http.StatusBadRequest, codes.InvalidArgument, err.Error())
 		return
 	}
 
 	var req expertpb.QueryExpertRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := rest.ReadBody(r, &req); err != nil {
+		rest.WriteError(w, r, http.StatusBadRequest, codes.InvalidArgument, err.Error())
+		return
+	}
+	// Pages without an expert get one on the spot for authenticated callers:
+	// the response is PENDING until it is indexed. Anonymous callers cannot
+	// make the Expert service fetch pages and store experts, and are pointed
+	// to a crawl request instead.
+	req.Url = target
+	req.FetchIfMissing = auth.FromContext(r.Context()) != nil
+
+	var trailer metadata.MD
+	grpcRes, err := s.expertSvcClient.QueryExpert(r.Context(), &req, grpc.Trailer(&trailer))
+	s.charge(r, trailer)
+	if status.Code(err) == codes.NotFound && s.nc != nil {
+		// Tell the caller how to get an expert for the page.
+		w.Header().Set("Link", `</api/v1/crawls>; rel="crawl"`)
+	}
+	if err != nil {
+		rest.WriteRPCError(w, r, expertpb.ExpertService_QueryExpert_FullMethodName, err)
+		return
+	}
+	rest.WriteMessage(w, queryStatus(grpcRes, w.Header()), grpcRes)
+}
+
+// queryStatus answers 202 Accepted, with a Retry-After header, while the
+// expert queried is being created, and 200 OK otherwise.
+func queryStatus(res proto.Message, header http.Header) int {
+	if r, ok := res.(*expertpb.QueryExpertResponse); ok && r.Status == expertpb.ExpertStatus_EXPERT_STATUS_PENDING {
+		header.Set("Retry-After", strconv.Itoa(max(1, int(r.RetryAfterSeconds))))
+		return http.StatusAccepted
+	}
+	return http.StatusOK
+}
+
+// crawlHandler handles requests to the /api/v1/crawls endpoint, which asks
+// the crawler to fetch a page so an expert is created for it.
+func (s *apiServer) crawlHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		rest.WriteError(w, r, http.StatusBadRequest, codes.InvalidArgument, "invalid request body: "+err.Error())
+		return
+	}
+	page, err := urlnorm.Canonical(req.URL)
+	if err != nil {
+		rest.WriteError(w, r, http.StatusBadRequest, codes.InvalidArgument, err.Error())
 		return
 	}
-	req.Url = url // Set the URL from the path
 
-	grpcRes, err := s.expertSvcClient.QueryExpert(r.Context(), &req)
+	msg, err := crawl.NewRequestMsg(r.Context(), page)
 	if err != nil {
-		http.Error(w, "backend service error", http.StatusInternalServerError)
-		log.Printf("Error from expert service: %v", err)
+		rest.WriteError(w, r, http.StatusInternalServerError, codes.Internal, "internal error")
 		return
 	}
+	if err := s.nc.PublishMsg(msg); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to publish crawl request", "url", page, "error", err)
+		rest.WriteError(w, r, http.StatusServiceUnavailable, codes.Unavailable, "crawl requests are unavailable")
+		return
+	}
+	slog.InfoContext(r.Context(), "Requested crawl", "url", page)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(grpcRes)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"url": page})
+}
+
+// routeExperts sends /e/ requests to experts with their raw path, and every
+// other request to next.
+func routeExperts(experts, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/e/") {
+			experts.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deprecated marks responses of a legacy route, pointing clients to the
+// route that replaces it.
+func deprecated(successor string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
 }
 
 func main() {
 	var cfg config
-	flag.StringVar(&cfg.httpPort, "http-port", "8080", "The HTTP port to listen on")
-	flag.StringVar(&cfg.expertSvcAddr, "expert-svc-addr", "expert-service:50052", "The address of the Expert service")
-	flag.StringVar(&cfg.orchSvcAddr, "orch-svc-addr", "query-orchestrator:50053", "The address of the Query Orchestrator service")
-	flag.Parse()
+	loader := conf.New("api-gateway")
+	loader.StringVar(&cfg.httpPort, "http-port", "8080", "The HTTP port to listen on")
+	loader.StringVar(&cfg.expertSvcAddr, "expert-svc-addr", "expert-service:50052", "The address of the Expert service")
+	loader.StringVar(&cfg.orchSvcAddr, "orch-svc-addr", "query-orchestrator:50053", "The address of the Query Orchestrator service")
+	loader.StringVar(&cfg.dbConn, "db-conn", "", "PostgreSQL connection string for API keys (empty to disable API keys)")
+	loader.StringVar(&cfg.natsURL, "nats-url", "", "The URL of the NATS server crawl requests are published to (empty to disable crawl requests)")
+	loader.StringVar(&cfg.jwks, "jwks", "", "The file or http(s) URL of the JWKS used to verify bearer tokens (empty to disable tokens)")
+	loader.StringVar(&cfg.jwtIssuer, "jwt-issuer", "", "The required iss claim of bearer tokens (empty to accept any)")
+	loader.StringVar(&cfg.jwtAudience, "jwt-audience", "", "The required aud claim of bearer tokens (empty to accept any)")
+	loader.StringVar(&cfg.anonymousRoutes, "anonymous-routes", "/,/search,/e/,/api/v1/search,/api/v1/experts:query,/api/v1/crawls,/api/v1/openapi.json", "A comma-separated list of route paths that can be called without credentials")
+	loader.StringVar(&cfg.adminSubjects, "admin-subjects", "", "A comma-separated list of the authenticated subjects allowed to call the /api/v1/admin routes")
+	loader.StringVar(&cfg.rateLimitStore, "rate-limit-store", "memory", "Where rate limits and quotas are kept (memory, or postgres to share them between replicas)")
+	loader.Float64Var(&cfg.keyRate, "key-rate", 5, "Requests per second allowed per authenticated caller (0 to disable)")
+	loader.IntVar(&cfg.keyBurst, "key-burst", 20, "Requests an authenticated caller may make at once")
+	loader.Float64Var(&cfg.ipRate, "ip-rate", 1, "Requests per second allowed per anonymous client IP (0 to disable)")
+	loader.IntVar(&cfg.ipBurst, "ip-burst", 10, "Requests an anonymous client IP may make at once")
+	loader.IntVar(&cfg.dailyTokenQuota, "daily-token-quota", 0, "LLM tokens each caller may consume per UTC day (0 for no quota)")
+	loader.BoolVar(&cfg.trustForwardedFor, "trust-forwarded-for", false, "Identify anonymous clients by the last X-Forwarded-For entry, appended by a trusted proxy")
+	loader.StringVar(&cfg.logLevel, "log-level", "info", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "json", "The log output format (json or text)")
+	loader.StringVar(&cfg.traceExporter, "trace-exporter", "none", "The trace exporter to use (none, stdout or otlp)")
+	loader.StringVar(&cfg.otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP gRPC collector (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	loader.StringVar(&cfg.metricsAddr, "metrics-addr", ":9090", "The address to serve Prometheus metrics on (empty to disable)")
+	loader.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 25*time.Second, "How long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.HTTPServer)
+	loader.Required("http-port", "expert-svc-addr", "orch-svc-addr")
+	loader.Secret("db-conn")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("api-gateway", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	// --- Telemetry ---
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   "api-gateway",
+		TraceExporter: cfg.traceExporter,
+		OTLPEndpoint:  cfg.otlpEndpoint,
+		MetricsAddr:   cfg.metricsAddr,
+		Certs:         certs,
+	})
+	if err != nil {
+		logger.Fatal("failed to set up telemetry", "error", err)
+	}
+	defer shutdown.Run("telemetry", shutdownTelemetry, cfg.shutdownTimeout)
+
+	// --- Authentication ---
+	authenticator := &auth.Authenticator{Issuer: cfg.jwtIssuer, Audience: cfg.jwtAudience}
+	checker := health.New()
+	var db *sql.DB
+	if cfg.dbConn != "" {
+		dsn, err := certs.PostgresDSN(cfg.dbConn)
+		if err != nil {
+			logger.Fatal("invalid configuration", "error", err)
+		}
+		db, err = sql.Open("postgres", dsn)
+		if err != nil {
+			logger.Fatal("failed to connect to database", "error", err)
+		}
+		defer db.Close()
+
+		if err := db.Ping(); err != nil {
+			logger.Fatal("failed to ping database", "error", err)
+		}
+		slog.Info("Successfully connected to the database")
+
+		// `api-gateway [flags] apikey create SUBJECT [NAME]|revoke ID|list`
+		// manages API keys and exits.
+		if len(loader.Args()) > 0 && loader.Args()[0] == "apikey" {
+			if err := auth.Command(context.Background(), db, loader.Args()[1:], os.Stdout); err != nil {
+				logger.Fatal("apikey command failed", "error", err)
+			}
+			return
+		}
+		authenticator.Keys = auth.NewDBKeys(db)
+		checker.Add("database", health.DB(db))
+	}
+	if len(loader.Args()) > 0 {
+		logger.Fatal("unknown command, or -db-conn is not set", "args", loader.Args())
+	}
+	if cfg.jwks != "" {
+		jwks, err := auth.NewJWKS(context.Background(), cfg.jwks)
+		if err != nil {
+			logger.Fatal("failed to load JWKS", "error", err)
+		}
+		authenticator.JWKS = jwks
+	}
+	slog.Info("Authentication configured", "api_keys", authenticator.Keys != nil, "jwt", authenticator.JWKS != nil)
+
+	// --- NATS Connection for Crawl Requests ---
+	var nc *nats.Conn
+	if cfg.natsURL != "" {
+		nc, err = nats.Connect(cfg.natsURL, certs.NATSOptions()...)
+		if err != nil {
+			logger.Fatal("failed to connect to NATS", "error", err)
+		}
+		defer nc.Close()
+		checker.Add("nats", health.NATS(nc))
+		slog.Info("Successfully connected to NATS")
+	}
+
+	// --- Rate Limiting ---
+	var limitStore ratelimit.Store
+	switch cfg.rateLimitStore {
+	case "memory":
+		limitStore = ratelimit.NewMemoryStore()
+	case "postgres":
+		if db == nil {
+			logger.Fatal("invalid configuration: -rate-limit-store=postgres requires -db-conn")
+		}
+		limitStore = ratelimit.NewPostgresStore(db)
+	default:
+		logger.Fatal("invalid configuration: unknown rate limit store", "store", cfg.rateLimitStore)
+	}
+	limiter := ratelimit.New(limitStore, ratelimit.Config{
+		Key:         ratelimit.Limit{Rate: cfg.keyRate, Burst: cfg.keyBurst},
+		IP:          ratelimit.Limit{Rate: cfg.ipRate, Burst: cfg.ipBurst},
+		DailyTokens: int64(cfg.dailyTokenQuota),
+	})
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(certs.ClientCredentials()),
+		grpc.WithChainUnaryInterceptor(logger.UnaryClientInterceptor(), auth.UnaryClientInterceptor()),
+	}, telemetry.DialOptions()...)
 
 	// --- gRPC Client for Expert Service ---
-	expertConn, err := grpc.NewClient(cfg.expertSvcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	expertConn, err := grpc.NewClient(cfg.expertSvcAddr, dialOpts...)
 	if err != nil {
-		log.Fatalf("did not connect to Expert service: %v", err)
+		logger.Fatal("did not connect to Expert service", "error", err)
 	}
 	defer expertConn.Close()
 	expertSvcClient := expertpb.NewExpertServiceClient(expertConn)
-	log.Println("Successfully connected to Expert service")
+	slog.Info("Successfully connected to Expert service")
 
 	// --- gRPC Client for Query Orchestrator Service ---
-	orchConn, err := grpc.NewClient(cfg.orchSvcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	orchConn, err := grpc.NewClient(cfg.orchSvcAddr, dialOpts...)
 	if err != nil {
-		log.Fatalf("did not connect to Query Orchestrator service: %v", err)
+		logger.Fatal("did not connect to Query Orchestrator service", "error", err)
 	}
 	defer orchConn.Close()
-	orchSvcClient := orchpb.NewQueryOrchestratorServiceClient(orchConn)
-	log.Println("Successfully connected to Query Orchestrator service")
+	slog.Info("Successfully connected to Query Orchestrator service")
 
 	// --- HTTP Server Setup ---
 	server := &apiServer{
 		expertSvcClient: expertSvcClient,
-		orchSvcClient:   orchSvcClient,
+		auth:            authenticator,
+		limiter:         limiter,
+
+		trustForwardedFor: cfg.trustForwardedFor,
+		nc:                nc,
+		admins:            map[string]bool{},
+	}
+	for _, subject := range strings.Split(cfg.adminSubjects, ",") {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			server.admins[subject] = true
+		}
 	}
 
+	anonymous := map[string]bool{}
+	for _, route := range strings.Split(cfg.anonymousRoutes, ",") {
+		anonymous[strings.TrimSpace(route)] = true
+	}
 	mux := http.NewServeMux()
-	mux.HandleFunc("/search", server.searchHandler)
-	mux.HandleFunc("/e/", server.expertHandler)
+	// handle registers h behind authentication. Patterns may start with an
+	// HTTP method; anonymous routes are listed by path alone.
+	handle := func(pattern string, h http.Handler) {
+		path := pattern
+		if _, p, ok := strings.Cut(pattern, " "); ok {
+			path = p
+		}
+		mux.Handle(pattern, server.authenticate(h, anonymous[path]))
+	}
+
+	// The /api/v1 routes are generated from the google.api.http annotations
+	// of the public services. The RAG service stays internal.
+	routes, err := server.registerAPI(handle, []apiService{
+		{orchpb.File_api_orchestrator_v1_orchestrator_proto.Services().ByName("QueryOrchestratorService"), orchConn},
+		{expertpb.File_api_expert_v1_expert_proto.Services().ByName("ExpertService"), expertConn},
+	})
+	if err != nil {
+		logger.Fatal("invalid HTTP annotations", "error", err)
+	}
+	experts := server.authenticate(server.limit(http.HandlerFunc(server.expertHandler)), anonymous["/e/"])
+	if nc != nil {
+		handle("POST /api/v1/crawls", server.limit(http.HandlerFunc(server.crawlHandler)))
+	}
+
+	openAPI, err := rest.OpenAPI(rest.Info{
+		Title:       "Portal API",
+		Version:     "v1",
+		Description: "Search the Portal expert network and query individual experts.",
+	}, routes)
+	if err != nil {
+		logger.Fatal("failed to generate OpenAPI document", "error", err)
+	}
+	handle("GET /api/v1/openapi.json", rest.ServeOpenAPI(openAPI))
+
+	checker.Add("query-orchestrator", health.GRPC(orchConn, orchpb.QueryOrchestratorService_ServiceDesc.ServiceName))
+	checker.Add("expert-service", health.GRPC(expertConn, expertpb.ExpertService_ServiceDesc.ServiceName))
+	health.RegisterHTTP(mux, checker)
 
 	// Serve the frontend files
 	fs := http.FileServer(http.Dir("./frontend"))
-	mux.Handle("/", fs)
+	handle("/", fs)
+
+	httpServer := &http.Server{
+		Addr:      ":" + cfg.httpPort,
+		Handler:   logger.HTTPMiddleware(routeExperts(experts, mux)),
+		TLSConfig: certs.Server(),
+	}
+
+	// --- Serve until SIGINT or SIGTERM ---
+	ctx, stop := shutdown.Signals()
+	defer stop()
+	go func() {
+		slog.Info("API Gateway listening", "port", cfg.httpPort, "https", httpServer.TLSConfig != nil)
+		serve := httpServer.ListenAndServe
+		if httpServer.TLSConfig != nil {
+			// The certificate comes from TLSConfig, which reloads it.
+			serve = func() error { return httpServer.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("failed to start server", "error", err)
+		}
+	}()
+	<-ctx.Done()
+	stop()
 
-	log.Printf("API Gateway listening on :%s", cfg.httpPort)
-	if err := http.ListenAndServe(":"+cfg.httpPort, mux); err != nil {
-		log.Fatalf("failed to start server: %v", err)
+	// Fail /readyz first so the load balancer moves away, then let active
+	// requests finish.
+	slog.Info("Shutting down", "timeout", cfg.shutdownTimeout)
+	checker.Shutdown()
+	shutdown.Run("http server", httpServer.Shutdown, cfg.shutdownTimeout)
+	if nc != nil {
+		if err := shutdown.NATS(nc, cfg.shutdownTimeout); err != nil {
+			slog.Error("Failed to drain NATS connection", "error", err)
+		}
 	}
+	slog.Info("Server stopped")
 }
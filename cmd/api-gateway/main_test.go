@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"portal.com/portal/internal/auth"
+	"portal.com/portal/internal/ratelimit"
+	"portal.com/portal/internal/rest"
+	expertpb "portal.com/portal/pkg/expert/v1"
+)
+
+// mockExpertClient records the QueryExpert request and returns a fixed
+// response or error.
+type mockExpertClient struct {
+	expertpb.ExpertServiceClient
+	res *expertpb.QueryExpertResponse
+	err error
+	req *expertpb.QueryExpertRequest
+}
+
+func (m *mockExpertClient) QueryExpert(ctx context.Context, in *expertpb.QueryExpertRequest, opts ...grpc.CallOption) (*expertpb.QueryExpertResponse, error) {
+	m.req = in
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.res != nil {
+		return m.res, nil
+	}
+	return &expertpb.QueryExpertResponse{Answer: "answer", Status: expertpb.ExpertStatus_EXPERT_STATUS_READY}, nil
+}
+
+// fakeKeys accepts a single API key.
+type fakeKeys struct{}
+
+func (fakeKeys) LookupKey(ctx context.Context, hash string) (*auth.Identity, error) {
+	if hash == auth.HashKey("portal_good") {
+		return &auth.Identity{Subject: "team-a", Method: auth.MethodAPIKey}, nil
+	}
+	return nil, auth.ErrInvalidCredentials
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := &apiServer{auth: &auth.Authenticator{Keys: fakeKeys{}}}
+	var seen *auth.Identity
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = auth.FromContext(r.Context())
+	})
+
+	tests := []struct {
+		name       string
+		anonymous  bool
+		key        string
+		wantStatus int
+		wantID     bool
+	}{
+		{"valid key", false, "portal_good", http.StatusOK, true},
+		{"missing key", false, "", http.StatusUnauthorized, false},
+		{"invalid key", false, "portal_bad", http.StatusUnauthorized, false},
+		{"anonymous route", true, "", http.StatusOK, false},
+		{"anonymous route with valid key", true, "portal_good", http.StatusOK, true},
+		{"anonymous route with invalid key", true, "portal_bad", http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		seen = nil
+		req := httptest.NewRequest(http.MethodPost, "/search", nil)
+		if tt.key != "" {
+			req.Header.Set(auth.APIKeyHeader, tt.key)
+		}
+		rec := httptest.NewRecorder()
+		s.authenticate(next, tt.anonymous).ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, rec.Code)
+		}
+		if (seen != nil) != tt.wantID {
+			t.Errorf("%s: unexpected identity %+v", tt.name, seen)
+		}
+		if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: expected a WWW-Authenticate header", tt.name)
+		}
+	}
+}
+
+func TestLimitRejectsWithRetryAfter(t *testing.T) {
+	s := &apiServer{
+		limiter: ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Config{IP: ratelimit.Limit{Rate: 0.5, Burst: 1}}),
+	}
+	h := s.limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(`{"query": "q"}`))
+		req.RemoteAddr = "203.0.113.7:4321"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := post(); rec.Code != http.StatusOK {
+		t.Fatalf("expected the first request to pass, got %d", rec.Code)
+	}
+	rec := post()
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("expected 429 with Retry-After 2, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var body rest.ErrorBody
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != "RESOURCE_EXHAUSTED" {
+		t.Errorf("expected a RESOURCE_EXHAUSTED body, got %+v, %v", body, err)
+	}
+}
+
+func TestExpertHandler(t *testing.T) {
+	client := &mockExpertClient{}
+	s := &apiServer{expertSvcClient: client}
+
+	// The handler runs before http.ServeMux, which would collapse the "//".
+	h := routeExperts(http.HandlerFunc(s.expertHandler), http.NotFoundHandler())
+	req := httptest.NewRequest(http.MethodPost, "/e/https://example.com/?page=2", strings.NewReader(`{"query": "q"}`))
+	req = req.WithContext(auth.WithIdentity(req.Context(), &auth.Identity{Subject: "team-a"}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || client.req.GetUrl() != "https://example.com/?page=2" || client.req.GetQuery() != "q" || !client.req.GetFetchIfMissing() {
+		t.Errorf("unexpected request %v with status %d", client.req, rec.Code)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["answer"] != "answer" || body["status"] != "EXPERT_STATUS_READY" {
+		t.Errorf("unexpected body %s", rec.Body)
+	}
+
+	// Anonymous callers only query existing experts.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/e/https://example.com/", strings.NewReader(`{"query": "q"}`)))
+	if rec.Code != http.StatusOK || client.req.GetFetchIfMissing() {
+		t.Errorf("unexpected anonymous request %v with status %d", client.req, rec.Code)
+	}
+
+	// An expert still being created is polled.
+	client.res = &expertpb.QueryExpertResponse{Status: expertpb.ExpertStatus_EXPERT_STATUS_PENDING, RetryAfterSeconds: 2}
+	rec = httptest.NewRecorder()
+	s.expertHandler(rec, httptest.NewRequest(http.MethodPost, "/e/https://new.example/", strings.NewReader(`{"query": "q"}`)))
+	if rec.Code != http.StatusAccepted || rec.Header().Get("Retry-After") != "2" {
+		t.Errorf("expected 202 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+
+	client.err = status.Error(codes.NotFound, "no expert")
+	rec = httptest.NewRecorder()
+	s.expertHandler(rec, httptest.NewRequest(http.MethodPost, "/e/https://example.com/", strings.NewReader(`{"query": "q"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.expertHandler(rec, httptest.NewRequest(http.MethodPost, "/e/https://example.com/", strings.NewReader(`{"question": "q"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown field, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.expertHandler(rec, httptest.NewRequest(http.MethodGet, "/e/example.com", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", rec.Code)
+	}
+}
+
+func TestExpertTarget(t *testing.T) {
+	tests := []struct {
+		uri, want string
+	}{
+		{"/e/https://example.com/docs/a?b=1&c=2", "https://example.com/docs/a?b=1&c=2"},
+		{"/e/https%3A%2F%2Fexample.com%2Fdocs%2Fa%3Fb%3D1", "https://example.com/docs/a?b=1"},
+		{"/e/example.com", "example.com"},
+		{"/e/example.com/docs", "example.com/docs"},
+		{"/e/https:/example.com/docs", "https://example.com/docs"},
+		{"/e/http%3A%2F%2Fexample.com%2Fa%2520b", "http://example.com/a%20b"},
+	}
+	for _, tt := range tests {
+		got, err := expertTarget(httptest.NewRequest(http.MethodPost, tt.uri, nil))
+		if err != nil || got != tt.want {
+			t.Errorf("expertTarget(%s) = %q, %v; want %q", tt.uri, got, err, tt.want)
+		}
+	}
+
+	for _, uri := range []string{"/e/", "/e/ftp%3A%2F%2Fexample.com", "/e/http%3A%2F%2F"} {
+		if got, err := expertTarget(httptest.NewRequest(http.MethodPost, uri, nil)); err == nil {
+			t.Errorf("expertTarget(%s) = %q; want an error", uri, got)
+		}
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	s := &apiServer{admins: map[string]bool{"ops": true}}
+	h := s.requireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tt := range []struct {
+		id         *auth.Identity
+		wantStatus int
+	}{
+		{nil, http.StatusForbidden},
+		{&auth.Identity{Subject: "team-a"}, http.StatusForbidden},
+		{&auth.Identity{Subject: "ops"}, http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/experts/x", nil)
+		if tt.id != nil {
+			req = req.WithContext(auth.WithIdentity(req.Context(), tt.id))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%v: expected status %d, got %d", tt.id, tt.wantStatus, rec.Code)
+		}
+	}
+}
+
+func TestClientIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	s := &apiServer{
+		trustForwardedFor: true,
+		limiter:           ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Config{IP: ratelimit.Limit{Rate: 0.5, Burst: 1}}),
+	}
+	h := s.limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// The client rotates the entries it sends; the proxy appends the address
+	// it saw, which stays the same.
+	for i, spoofed := range []string{"198.51.100.1", "198.51.100.2, 192.0.2.9"} {
+		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(`{"query": "q"}`))
+		req.RemoteAddr = "10.0.0.2:4321"
+		req.Header.Set("X-Forwarded-For", spoofed+", 203.0.113.7")
+		if ip := s.clientIP(req); ip != "203.0.113.7" {
+			t.Errorf("clientIP() = %q, want the entry appended by the proxy", ip)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if want := []int{http.StatusOK, http.StatusTooManyRequests}[i]; rec.Code != want {
+			t.Errorf("request %d: expected status %d, got %d", i, want, rec.Code)
+		}
+	}
+
+	// A proxy adding its own header rather than appending to the client's.
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req.Header.Add("X-Forwarded-For", "198.51.100.1")
+	req.Header.Add("X-Forwarded-For", "203.0.113.8")
+	if ip := s.clientIP(req); ip != "203.0.113.8" {
+		t.Errorf("clientIP() = %q, want the last header", ip)
+	}
+}
+
+// fakeConn records the methods invoked over it, and their requests.
+type fakeConn struct {
+	grpc.ClientConnInterface
+	methods []string
+	reqs    []any
+}
+
+func (c *fakeConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	c.methods = append(c.methods, method)
+	c.reqs = append(c.reqs, args)
+	return nil
+}
+
+func TestQueryExpertFetchRequiresAuthentication(t *testing.T) {
+	s := &apiServer{auth: &auth.Authenticator{Keys: fakeKeys{}}}
+	conn := &fakeConn{}
+	mux := http.NewServeMux()
+	handle := func(pattern string, h http.Handler) { mux.Handle(pattern, s.authenticate(h, true)) }
+	if _, err := s.registerAPI(handle, []apiService{{expertpb.File_api_expert_v1_expert_proto.Services().ByName("ExpertService"), conn}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "portal_good"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/experts:query", strings.NewReader(`{"url": "https://example.com/", "query": "q", "fetchIfMissing": true}`))
+		if key != "" {
+			req.Header.Set(auth.APIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || len(conn.reqs) == 0 {
+			t.Fatalf("key %q: expected a call, got status %d: %s", key, rec.Code, rec.Body)
+		}
+		got := conn.reqs[len(conn.reqs)-1].(*expertpb.QueryExpertRequest)
+		if want := key != ""; got.FetchIfMissing != want {
+			t.Errorf("key %q: fetch_if_missing = %t, want %t", key, got.FetchIfMissing, want)
+		}
+	}
+}
+
+func TestCreateOrUpdateExpertRequiresAdmin(t *testing.T) {
+	for _, tt := range []struct {
+		admins     map[string]bool
+		wantStatus int
+	}{
+		{map[string]bool{"ops": true}, http.StatusForbidden},
+		{map[string]bool{"team-a": true}, http.StatusOK},
+	} {
+		s := &apiServer{auth: &auth.Authenticator{Keys: fakeKeys{}}, admins: tt.admins}
+		conn := &fakeConn{}
+		mux := http.NewServeMux()
+		handle := func(pattern string, h http.Handler) { mux.Handle(pattern, s.authenticate(h, false)) }
+		if _, err := s.registerAPI(handle, []apiService{{expertpb.File_api_expert_v1_expert_proto.Services().ByName("ExpertService"), conn}}); err != nil {
+			t.Fatal(err)
+		}
+
+		body := `{"url": "https://example.com/", "content": "injected", "expertType": "EXPERT_TYPE_SIMPLE"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/experts", strings.NewReader(body))
+		req.Header.Set(auth.APIKeyHeader, "portal_good")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("admins %v: expected status %d, got %d: %s", tt.admins, tt.wantStatus, rec.Code, rec.Body)
+		}
+		if called := len(conn.methods) > 0; called != (tt.wantStatus == http.StatusOK) {
+			t.Errorf("admins %v: unexpected calls %v", tt.admins, conn.methods)
+		}
+	}
+}
@@ -1,74 +1,168 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"flag"
 	"log"
+	"log/slog"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/crawl"
+	"portal.com/portal/internal/health"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/shutdown"
+	"portal.com/portal/internal/telemetry"
+	"portal.com/portal/internal/tlsconfig"
+	"portal.com/portal/internal/urlnorm"
 )
 
 // CrawledContentMessage defines the structure of the message sent to NATS.
 type CrawledContentMessage struct {
-	URL       string    `json:"url"`
-	Content   string    `json:"content"`
+	URL     string `json:"url"`
+	Content string `json:"content"`
+	// Language is the language the page declares in its lang attribute,
+	// if any.
+	Language  string    `json:"language,omitempty"`
 	CrawledAt time.Time `json:"crawled_at"`
 }
 
+// requestIDKey is the colly request context key holding the page's request ID.
+const requestIDKey = "request_id"
+
 // config holds all the configuration for the service.
 type config struct {
-	natsURL        string
-	allowedDomains string
-	startURL       string
+	natsURL         string
+	allowedDomains  string
+	crawlRequests   bool
+	startURL        string
+	healthAddr      string
+	logLevel        string
+	logFormat       string
+	traceExporter   string
+	otlpEndpoint    string
+	metricsAddr     string
+	shutdownTimeout time.Duration
+	tls             tlsconfig.Config
 }
 
 func main() {
 	var cfg config
-	flag.StringVar(&cfg.natsURL, "nats-url", "nats://nats:4222", "The URL of the NATS server")
-	flag.StringVar(&cfg.allowedDomains, "allowed-domains", "gocolly.dev", "A comma-separated list of domains to allow crawling")
-	flag.StringVar(&cfg.startURL, "start-url", "http://gocolly.dev/", "The initial URL to start crawling from")
-	flag.Parse()
+	loader := conf.New("crawler-service")
+	loader.StringVar(&cfg.natsURL, "nats-url", "nats://nats:4222", "The URL of the NATS server")
+	loader.StringVar(&cfg.allowedDomains, "allowed-domains", "gocolly.dev", "A comma-separated list of domains to allow crawling")
+	loader.BoolVar(&cfg.crawlRequests, "crawl-requests", true, "Fetch pages requested through the API Gateway, if they are in the allowed domains")
+	loader.StringVar(&cfg.startURL, "start-url", "http://gocolly.dev/", "The initial URL to start crawling from")
+	loader.StringVar(&cfg.healthAddr, "health-addr", ":8081", "The address to serve the /healthz and /readyz endpoints on")
+	loader.StringVar(&cfg.logLevel, "log-level", "info", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "json", "The log output format (json or text)")
+	loader.StringVar(&cfg.traceExporter, "trace-exporter", "none", "The trace exporter to use (none, stdout or otlp)")
+	loader.StringVar(&cfg.otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP gRPC collector (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	loader.StringVar(&cfg.metricsAddr, "metrics-addr", ":9090", "The address to serve Prometheus metrics on (empty to disable)")
+	loader.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 25*time.Second, "How long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.Client)
+	loader.Required("nats-url", "start-url")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("crawler-service", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	// --- Telemetry ---
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   "crawler-service",
+		TraceExporter: cfg.traceExporter,
+		OTLPEndpoint:  cfg.otlpEndpoint,
+		MetricsAddr:   cfg.metricsAddr,
+		Certs:         certs,
+	})
+	if err != nil {
+		logger.Fatal("failed to set up telemetry", "error", err)
+	}
+	defer shutdown.Run("telemetry", shutdownTelemetry, cfg.shutdownTimeout)
 
 	// --- NATS Connection ---
-	nc, err := nats.Connect(cfg.natsURL)
+	nc, err := nats.Connect(cfg.natsURL, certs.NATSOptions()...)
 	if err != nil {
-		log.Fatalf("failed to connect to NATS: %v", err)
+		logger.Fatal("failed to connect to NATS", "error", err)
 	}
 	defer nc.Close()
-	log.Println("Successfully connected to NATS")
+	slog.Info("Successfully connected to NATS")
+
+	// --- Health Endpoints ---
+	checker := health.New()
+	checker.Add("nats", health.NATS(nc))
+	healthServer, err := health.Serve(cfg.healthAddr, checker)
+	if err != nil {
+		logger.Fatal("failed to serve health endpoints", "error", err)
+	}
+	defer shutdown.Run("health server", healthServer.Shutdown, cfg.shutdownTimeout)
+
+	ctx, stop := shutdown.Signals()
+	defer stop()
 
 	// Instantiate default collector
+	allowedDomains := strings.Split(cfg.allowedDomains, ",")
 	c := colly.NewCollector(
-		colly.AllowedDomains(strings.Split(cfg.allowedDomains, ",")...),
+		colly.AllowedDomains(allowedDomains...),
 	)
 
 	// publisher creates and sends a message to the NATS queue.
-	publisher := func(url, content string) {
+	publisher := func(ctx context.Context, url, content, language string) {
 		if content == "" {
-			log.Printf("Skipping empty content for URL: %s", url)
+			slog.InfoContext(ctx, "Skipping empty content", "url", url)
+			telemetry.PagesCrawled.WithLabelValues("empty").Inc()
 			return
 		}
 
+		ctx, span := telemetry.Tracer("crawler-service").Start(ctx, "crawled-content publish",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(attribute.String("url.full", url)),
+		)
+		defer span.End()
+
 		msg := CrawledContentMessage{
 			URL:       url,
 			Content:   content,
+			Language:  language,
 			CrawledAt: time.Now().UTC(),
 		}
 
 		msgBytes, err := json.Marshal(msg)
 		if err != nil {
-			log.Printf("failed to marshal message for url %s: %v", url, err)
+			slog.ErrorContext(ctx, "Failed to marshal message", "url", url, "error", err)
+			telemetry.PagesCrawled.WithLabelValues("failed").Inc()
 			return
 		}
 
-		// Publish the message to the "crawled-content" subject.
-		if err := nc.Publish("crawled-content", msgBytes); err != nil {
-			log.Printf("failed to publish message for url %s: %v", url, err)
+		// Publish the message to the "crawled-content" subject, carrying the
+		// page's request ID and trace context so the indexing can be traced
+		// back to the crawl.
+		natsMsg := nats.NewMsg("crawled-content")
+		natsMsg.Data = msgBytes
+		logger.InjectNATS(ctx, natsMsg)
+		telemetry.InjectNATS(ctx, natsMsg)
+		if err := nc.PublishMsg(natsMsg); err != nil {
+			slog.ErrorContext(ctx, "Failed to publish message", "url", url, "error", err)
+			span.SetStatus(codes.Error, err.Error())
+			telemetry.PagesCrawled.WithLabelValues("failed").Inc()
 		} else {
-			log.Printf("Published content for URL: %s", url)
+			slog.InfoContext(ctx, "Published content", "url", url)
+			telemetry.PagesCrawled.WithLabelValues("published").Inc()
 		}
 	}
 
@@ -77,23 +171,86 @@ func main() {
 		e.Request.Visit(e.Attr("href"))
 	})
 
-	c.OnRequest(func(r *colly.Request) {
-		log.Println("Visiting", r.URL.String())
-	})
+	// Every page visit gets its own request ID. Once shutdown starts no new
+	// pages are fetched, but pages already being fetched are still published.
+	onRequest := func(r *colly.Request) {
+		if ctx.Err() != nil {
+			r.Abort()
+			return
+		}
+		id := logger.NewRequestID()
+		r.Ctx.Put(requestIDKey, id)
+		slog.InfoContext(logger.WithRequestID(context.Background(), id), "Visiting", "url", r.URL.String())
+	}
+	c.OnRequest(onRequest)
 
-	// When a page is scraped, extract its text and publish it.
-	c.OnHTML("body", func(e *colly.HTMLElement) {
+	// When a page is scraped, extract its text and publish it under its
+	// canonical URL, which is what experts are stored under.
+	onBody := func(e *colly.HTMLElement) {
 		// This is a naive content extraction. A real implementation would use
 		// a library like go-readability to get only the main article text.
-		publisher(e.Request.URL.String(), e.Text)
-	})
+		ctx := logger.WithRequestID(context.Background(), e.Request.Ctx.Get(requestIDKey))
+		url := e.Request.URL.String()
+		if canonical, err := urlnorm.Canonical(url); err == nil {
+			url = canonical
+		}
+		language := strings.TrimSpace(e.DOM.ParentsFiltered("html").AttrOr("lang", ""))
+		publisher(ctx, url, e.Text, language)
+	}
+	c.OnHTML("body", onBody)
 
-	log.Printf("Starting crawl at %s", cfg.startURL)
-	if err := c.Visit(cfg.startURL); err != nil {
-		log.Fatalf("failed to start crawl: %v", err)
+	// Requested pages are fetched on their own, without following links, and
+	// may be fetched again to refresh their expert.
+	if cfg.crawlRequests {
+		requested := colly.NewCollector(
+			colly.AllowedDomains(allowedDomains...),
+			colly.AllowURLRevisit(),
+		)
+		requested.OnRequest(onRequest)
+		requested.OnHTML("body", onBody)
+		_, err := nc.Subscribe(crawl.RequestSubject, func(m *nats.Msg) {
+			ctx := logger.ExtractNATS(context.Background(), m)
+			ctx = telemetry.ExtractNATS(ctx, m)
+			var req crawl.RequestMessage
+			if err := json.Unmarshal(m.Data, &req); err != nil {
+				slog.ErrorContext(ctx, "Failed to unmarshal crawl request", "error", err)
+				return
+			}
+			slog.InfoContext(ctx, "Crawling requested page", "url", req.URL)
+			if err := requested.Visit(req.URL); err != nil {
+				slog.WarnContext(ctx, "Failed to crawl requested page", "url", req.URL, "error", err)
+			}
+		})
+		if err != nil {
+			logger.Fatal("failed to subscribe to crawl requests", "error", err)
+		}
+		slog.Info("Accepting crawl requests", "subject", crawl.RequestSubject)
 	}
 
-	// The collector runs asynchronously, so we block forever.
-	// In a real app, you'd handle signals for graceful shutdown.
-	select {}
+	crawlDone := make(chan struct{})
+	go func() {
+		defer close(crawlDone)
+		slog.Info("Starting crawl", "url", cfg.startURL)
+		if err := c.Visit(cfg.startURL); err != nil {
+			slog.Error("Failed to start crawl", "url", cfg.startURL, "error", err)
+			return
+		}
+		slog.Info("Crawl finished", "url", cfg.startURL)
+	}()
+
+	// --- Run until SIGINT or SIGTERM ---
+	<-ctx.Done()
+	stop()
+
+	slog.Info("Shutting down", "timeout", cfg.shutdownTimeout)
+	checker.Shutdown()
+	select {
+	case <-crawlDone:
+	case <-time.After(cfg.shutdownTimeout):
+		slog.Warn("Timed out waiting for in-flight pages", "timeout", cfg.shutdownTimeout)
+	}
+	if err := shutdown.NATS(nc, cfg.shutdownTimeout); err != nil {
+		slog.Error("Failed to drain NATS connection", "error", err)
+	}
+	slog.Info("Crawler stopped")
 }
@@ -3,24 +3,77 @@ package main
 import (
 	"context"
 	"database/sql"
-	"flag"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"portal.com/portal/internal/auth"
+	"portal.com/portal/internal/cache"
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/crawl"
+	"portal.com/portal/internal/fetch"
+	"portal.com/portal/internal/grpcclient"
+	"portal.com/portal/internal/health"
+	"portal.com/portal/internal/llm"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/migrations"
+	"portal.com/portal/internal/passage"
+	"portal.com/portal/internal/rpcerr"
+	"portal.com/portal/internal/shutdown"
+	"portal.com/portal/internal/telemetry"
+	"portal.com/portal/internal/tlsconfig"
+	"portal.com/portal/internal/urlnorm"
+	"portal.com/portal/internal/verify"
 	pb "portal.com/portal/pkg/expert/v1" // This service's generated code
 	ragpb "portal.com/portal/pkg/rag/v1" // RAG service's generated code
 )
 
 // config holds all the configuration for the service.
 type config struct {
-	grpcPort   string
-	dbConn     string
-	ragSvcAddr string
+	grpcPort        string
+	dbConn          string
+	ragSvcAddr      string
+	natsURL         string
+	autoMigrate     bool
+	logLevel        string
+	logFormat       string
+	traceExporter   string
+	otlpEndpoint    string
+	metricsAddr     string
+	shutdownTimeout time.Duration
+	tls             tlsconfig.Config
+	onDemand        bool
+	onDemandBudget  time.Duration
+	onDemandTimeout time.Duration
+	fetchMaxBytes   int
+	ragThreshold    int
+	cacheSize       int
+	cacheTTL        time.Duration
+	sharedCache     bool
+	maxAttempts     int
+	breakerFailures int
+	breakerOpenFor  time.Duration
+	verifier        string
+	verifyThreshold float64
 }
 
 // server implements the ExpertService.
@@ -28,61 +81,964 @@ type server struct {
 	pb.UnimplementedExpertServiceServer
 	db           *sql.DB
 	ragSvcClient ragpb.RAGServiceClient
+	llm          llm.Client
+	// nc may be nil, which disables ReindexExpert.
+	nc *nats.Conn
+	// onDemand may be nil, which disables creating experts in QueryExpert.
+	onDemand *onDemand
+	// cache may be nil, which disables caching answers.
+	cache    cache.Cache
+	cacheTTL time.Duration
+	// verifier may be nil, which disables verifying answers.
+	verifier verify.Verifier
 }
 
 // CreateOrUpdateExpert implements expert.v1.ExpertServiceServer
 func (s *server) CreateOrUpdateExpert(ctx context.Context, in *pb.CreateOrUpdateExpertRequest) (*pb.CreateOrUpdateExpertResponse, error) {
-	log.Printf("Received CreateOrUpdateExpert for URL: %v", in.Url)
-	// TODO: Check if expert exists in DB.
-	// TODO: If it's a RAG expert, call the RAG service's IndexContent method.
-	// s.ragSvcClient.IndexContent(...)
-	// TODO: Insert/update expert metadata in the 'experts' table.
-	return &pb.CreateOrUpdateExpertResponse{ExpertId: "mock-expert-id"}, nil
+	slog.InfoContext(ctx, "Received CreateOrUpdateExpert", "url", in.Url, "type", in.ExpertType)
+	if in.Url == "" || in.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "url and content are required")
+	}
+	url, err := urlnorm.Canonical(in.Url)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// Pages declare all sorts of tags; one that is not valid is dropped
+	// rather than failing the indexing.
+	language := normalizeLanguage(in.Language)
+
+	var expertID string
+	switch in.ExpertType {
+	case pb.ExpertType_EXPERT_TYPE_SIMPLE:
+		// Simple experts answer from their raw content. An expert that used
+		// to be RAG based no longer needs its chunks.
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, rpcerr.Wrap(err, "failed to begin transaction")
+		}
+		defer tx.Rollback()
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO experts (type, name, url, is_rag_based, raw_content, language)
+			VALUES ('LEAF', $1, $1, FALSE, $2, NULLIF($3, ''))
+			ON CONFLICT (url) DO UPDATE SET is_rag_based = FALSE, raw_content = EXCLUDED.raw_content, language = EXCLUDED.language,
+				content_version = nextval('expert_content_versions'), updated_at = NOW()
+			RETURNING id`, url, in.Content, language).Scan(&expertID)
+		if err != nil {
+			return nil, rpcerr.Wrap(err, "failed to upsert expert")
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM document_chunks WHERE expert_id = $1`, expertID); err != nil {
+			return nil, rpcerr.Wrap(err, "failed to delete old chunks")
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, rpcerr.Wrap(err, "failed to commit expert")
+		}
+
+	case pb.ExpertType_EXPERT_TYPE_RAG:
+		// The RAG service chunks the content and creates the expert row; the
+		// raw content is not kept since answers come from the chunks.
+		if _, err := s.ragSvcClient.IndexContent(ctx, &ragpb.IndexContentRequest{Url: url, Content: in.Content}); err != nil {
+			return nil, rpcerr.Wrap(err, "failed to index content")
+		}
+		err := s.db.QueryRowContext(ctx,
+			`UPDATE experts SET raw_content = NULL, language = NULLIF($2, ''), content_version = nextval('expert_content_versions') WHERE url = $1 RETURNING id`,
+			url, language).Scan(&expertID)
+		if err != nil {
+			return nil, rpcerr.Wrap(err, "failed to load indexed expert")
+		}
+
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported expert type %s", in.ExpertType)
+	}
+
+	// The new content version already keeps old answers from being served;
+	// dropping them frees their space, here and in the shared cache.
+	if s.cache != nil {
+		if err := s.cache.Invalidate(ctx, url); err != nil {
+			slog.WarnContext(ctx, "Failed to invalidate cached results", "url", url, "error", err)
+		}
+	}
+	return &pb.CreateOrUpdateExpertResponse{ExpertId: expertID}, nil
 }
 
 // QueryExpert implements expert.v1.ExpertServiceServer
 func (s *server) QueryExpert(ctx context.Context, in *pb.QueryExpertRequest) (*pb.QueryExpertResponse, error) {
-	log.Printf("Received QueryExpert for URL: %v", in.Url)
-	// TODO: Fetch expert metadata from DB to see if it's simple or RAG.
-	// TODO: If RAG, call RAG service's RetrieveContext method.
-	// TODO: If simple, get content from DB.
-	// TODO: Call LLM (mock for now) with context and query.
-	return &pb.QueryExpertResponse{Answer: "This is a mock answer from the expert."}, nil
+	slog.InfoContext(ctx, "Received QueryExpert", "url", in.Url)
+	if in.Url == "" || in.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "url and query are required")
+	}
+
+	// A bare domain may name an expert stored under https or http; the
+	// first candidate that exists wins.
+	candidates, err := urlnorm.Candidates(in.Url)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// An unknown page may get its expert on the spot, if the caller asks
+	// and it can be fetched and indexed within the budget.
+	leaf, err := s.findExpert(ctx, candidates)
+	if status.Code(err) == codes.NotFound && in.FetchIfMissing && s.onDemand != nil {
+		ready, cerr := s.createExpert(ctx, candidates)
+		if cerr != nil {
+			return nil, cerr
+		}
+		if !ready {
+			return &pb.QueryExpertResponse{
+				Status:            pb.ExpertStatus_EXPERT_STATUS_PENDING,
+				RetryAfterSeconds: int32(pendingRetryAfter / time.Second),
+			}, nil
+		}
+		leaf, err = s.findExpert(ctx, candidates)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	retrievalQueries := in.RetrievalQueries[:min(len(in.RetrievalQueries), maxRetrievalQueries)]
+
+	// Answers are cached per content version, so a changed expert is never
+	// answered from its old content. Cached answers cost no LLM tokens.
+	var key string
+	if s.cache != nil {
+		parts := []string{"expert", leaf.url, strconv.FormatInt(leaf.version, 10), cache.NormalizeQuery(in.Query)}
+		if leaf.isRAG {
+			for _, q := range retrievalQueries {
+				parts = append(parts, cache.NormalizeQuery(q))
+			}
+		}
+		key = cache.Key(parts...)
+		if b := cache.Lookup(ctx, s.cache, "expert", key); b != nil {
+			res := &pb.QueryExpertResponse{}
+			if err := proto.Unmarshal(b, res); err == nil {
+				return res, nil
+			}
+		}
+	}
+
+	// Simple experts answer from the whole page; the lines sharing the most
+	// keywords with the query are reported as the passages used.
+	content, passages := leaf.content, []string{leaf.content}
+	var used []*pb.Passage
+	if !leaf.isRAG {
+		for _, p := range passage.Rank(passage.Lines(leaf.content), in.Query, maxPassages) {
+			used = append(used, passageProto(p))
+		}
+	} else {
+		// Chunks retrieved for several forms of the query are merged in
+		// order, without repeats, each with its best score.
+		var chunks []string
+		byIndex := map[int32]*pb.Passage{}
+		for _, q := range append([]string{in.Query}, retrievalQueries...) {
+			res, err := s.ragSvcClient.RetrieveContext(ctx, &ragpb.RetrieveContextRequest{Url: leaf.url, Query: q})
+			if err != nil {
+				return nil, rpcerr.Wrap(err, "failed to retrieve context")
+			}
+			for _, c := range res.ContextChunks {
+				if !slices.Contains(chunks, c) {
+					chunks = append(chunks, c)
+				}
+			}
+			for _, c := range res.Chunks {
+				if p, ok := byIndex[c.Index]; ok {
+					p.Score = max(p.Score, c.Score)
+					continue
+				}
+				byIndex[c.Index] = &pb.Passage{
+					Text:        c.Text,
+					ChunkIndex:  c.Index,
+					StartOffset: c.StartOffset,
+					EndOffset:   c.EndOffset,
+					Heading:     c.Heading,
+					Score:       c.Score,
+				}
+				used = append(used, byIndex[c.Index])
+			}
+		}
+		sort.SliceStable(used, func(i, j int) bool { return used[i].Score > used[j].Score })
+		content, passages = strings.Join(chunks, "\n\n"), chunks
+	}
+
+	completion, err := s.llm.Generate(ctx, llm.ExpertPrompt(leaf.url, content, in.Query))
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to generate answer")
+	}
+	telemetry.RecordLLMUsage(s.llm.Model(), completion.PromptTokens, completion.CompletionTokens)
+	tokens := completion.PromptTokens + completion.CompletionTokens
+	res := &pb.QueryExpertResponse{Answer: completion.Text, Status: pb.ExpertStatus_EXPERT_STATUS_READY, Passages: used}
+
+	// The answer is checked against the passages it was generated from, so
+	// callers can flag or drop claims the content does not back.
+	if s.verifier != nil {
+		v := s.verifier.Verify(ctx, completion.Text, passages)
+		res.Verification = verificationProto(v)
+		if n := v.Unsupported(); n > 0 {
+			slog.InfoContext(ctx, "Answer has unsupported claims", "url", leaf.url, "unsupported", n, "claims", len(v.Claims))
+		}
+	}
+	if err := llm.SetUsageTrailer(ctx, tokens); err != nil {
+		slog.DebugContext(ctx, "Failed to report LLM usage", "error", err)
+	}
+	if s.cache != nil {
+		if b, err := proto.Marshal(res); err == nil {
+			cache.Store(ctx, s.cache, "expert", key, b, []string{leaf.url}, s.cacheTTL)
+		}
+	}
+	return res, nil
+}
+
+// passageProto converts a passage to its protobuf message.
+func passageProto(p passage.Passage) *pb.Passage {
+	return &pb.Passage{
+		Text:        p.Text,
+		ChunkIndex:  int32(p.Index),
+		StartOffset: int32(p.Start),
+		EndOffset:   int32(p.End),
+		Heading:     p.Heading,
+		Score:       float32(p.Score),
+	}
+}
+
+// verificationProto converts a verification to its protobuf message, and
+// counts its claims.
+func verificationProto(v *verify.Result) *pb.AnswerVerification {
+	out := &pb.AnswerVerification{UnsupportedClaims: int32(v.Unsupported()), Verifier: v.Verifier}
+	for _, c := range v.Claims {
+		out.Claims = append(out.Claims, &pb.Claim{
+			Text:      c.Text,
+			Support:   float32(c.Support),
+			Supported: c.Supported,
+			Evidence:  c.Evidence,
+		})
+		result := "supported"
+		if !c.Supported {
+			result = "unsupported"
+		}
+		telemetry.VerifiedClaims.WithLabelValues(result).Inc()
+	}
+	return out
+}
+
+// maxPassages bounds the lines of simple experts reported as the passages
+// an answer was generated from.
+const maxPassages = 5
+
+// maxRetrievalQueries bounds the other forms of a query RAG experts
+// retrieve context for.
+const maxRetrievalQueries = 4
+
+// leaf is the part of a leaf expert needed to answer queries.
+type leaf struct {
+	url     string
+	isRAG   bool
+	content string
+	version int64
+}
+
+// findExpert returns the first expert named by candidates.
+func (s *server) findExpert(ctx context.Context, candidates []string) (*leaf, error) {
+	var l leaf
+	err := s.db.QueryRowContext(ctx, `
+		SELECT url, is_rag_based, COALESCE(raw_content, ''), content_version FROM experts
+		WHERE url = ANY($1::text[])
+		ORDER BY array_position($1::text[], url)
+		LIMIT 1`, pq.Array(candidates)).Scan(&l.url, &l.isRAG, &l.content, &l.version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "no expert for %s", candidates[0])
+	}
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to load expert")
+	}
+	return &l, nil
+}
+
+// GetContentVersions implements expert.v1.ExpertServiceServer
+func (s *server) GetContentVersions(ctx context.Context, in *pb.GetContentVersionsRequest) (*pb.GetContentVersionsResponse, error) {
+	urls := make([]string, 0, len(in.Urls))
+	for _, u := range in.Urls {
+		c, err := urlnorm.Canonical(u)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		urls = append(urls, c)
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT url, content_version FROM experts WHERE url = ANY($1::text[])`, pq.Array(urls))
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to load content versions")
+	}
+	defer rows.Close()
+	res := &pb.GetContentVersionsResponse{Versions: map[string]int64{}}
+	for rows.Next() {
+		var url string
+		var version int64
+		if err := rows.Scan(&url, &version); err != nil {
+			return nil, rpcerr.Wrap(err, "failed to scan content version")
+		}
+		res.Versions[url] = version
+	}
+	if err := rows.Err(); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to load content versions")
+	}
+	return res, nil
+}
+
+const (
+	// pendingRetryAfter is how long clients are asked to wait before polling
+	// an expert that is being created.
+	pendingRetryAfter = 2 * time.Second
+	// creationTTL is how long the outcome of a creation is remembered, so
+	// polls see a failure instead of fetching the page again.
+	creationTTL = time.Minute
+	// maxCreations bounds the experts being created at once.
+	maxCreations = 64
+)
+
+// pageFetcher fetches the text of a page; *fetch.Fetcher implements it.
+type pageFetcher interface {
+	Fetch(ctx context.Context, url string) (*fetch.Page, error)
+}
+
+// onDemand creates experts for pages that have not been crawled, when a
+// query asks for it.
+type onDemand struct {
+	fetcher pageFetcher
+	// budget is how long a query waits for its expert before answering
+	// PENDING; timeout bounds the creation itself.
+	budget  time.Duration
+	timeout time.Duration
+	// ragThreshold is the content length above which experts are RAG
+	// based, as in the indexing job.
+	ragThreshold int
+
+	mu        sync.Mutex
+	creations map[string]*creation
+}
+
+// creation is an expert being created, shared by the queries waiting for
+// it.
+type creation struct {
+	done chan struct{}
+	err  error
+}
+
+// createExpert creates the expert for the page named by candidates, or
+// joins its creation if one is under way, and waits for it within the
+// on-demand budget. It reports false if the expert is not ready yet.
+func (s *server) createExpert(ctx context.Context, candidates []string) (bool, error) {
+	o := s.onDemand
+	key := candidates[0]
+	o.mu.Lock()
+	c, ok := o.creations[key]
+	if !ok {
+		if len(o.creations) >= maxCreations {
+			o.mu.Unlock()
+			return false, status.Error(codes.ResourceExhausted, "too many experts are being created, try again later")
+		}
+		c = &creation{done: make(chan struct{})}
+		if o.creations == nil {
+			o.creations = map[string]*creation{}
+		}
+		o.creations[key] = c
+		// The creation outlives the query that started it so later queries
+		// can poll for it, but keeps its request ID and trace.
+		go s.runCreation(context.WithoutCancel(ctx), key, candidates, c)
+	}
+	o.mu.Unlock()
+
+	timer := time.NewTimer(o.budget)
+	defer timer.Stop()
+	select {
+	case <-c.done:
+		return c.err == nil, c.err
+	case <-timer.C:
+		return false, nil
+	case <-ctx.Done():
+		return false, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (s *server) runCreation(ctx context.Context, key string, candidates []string, c *creation) {
+	o := s.onDemand
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	start := time.Now()
+	c.err = s.fetchAndIndex(ctx, candidates)
+	if c.err != nil {
+		slog.WarnContext(ctx, "Failed to create expert on demand", "url", key, "error", c.err)
+	} else {
+		slog.InfoContext(ctx, "Created expert on demand", "url", key, "duration", time.Since(start))
+	}
+	close(c.done)
+
+	time.AfterFunc(creationTTL, func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		delete(o.creations, key)
+	})
+}
+
+// fetchAndIndex fetches the first of candidates that can be fetched and
+// creates its expert, RAG based if the page is long.
+func (s *server) fetchAndIndex(ctx context.Context, candidates []string) error {
+	var url string
+	var page *fetch.Page
+	var err error
+	for _, url = range candidates {
+		if page, err = s.onDemand.fetcher.Fetch(ctx, url); err == nil {
+			break
+		}
+	}
+	switch {
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case errors.Is(err, fetch.ErrBlocked):
+		return status.Errorf(codes.InvalidArgument, "%s is not a public page", candidates[0])
+	case err != nil:
+		return status.Errorf(codes.NotFound, "no expert for %s and its page could not be fetched: %v", candidates[0], err)
+	case page.Text == "":
+		return status.Errorf(codes.NotFound, "no expert for %s and its page has no text", candidates[0])
+	}
+
+	expertType := pb.ExpertType_EXPERT_TYPE_SIMPLE
+	if len(page.Text) > s.onDemand.ragThreshold {
+		expertType = pb.ExpertType_EXPERT_TYPE_RAG
+	}
+	_, err = s.CreateOrUpdateExpert(ctx, &pb.CreateOrUpdateExpertRequest{
+		Url:        url,
+		Content:    page.Text,
+		ExpertType: expertType,
+		Language:   page.Language,
+	})
+	return err
+}
+
+const (
+	// defaultPageSize and maxPageSize bound the experts in a ListExperts page.
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+var (
+	uuidPattern   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	domainPattern = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)*$`)
+	// languagePattern matches lowercased language tags such as "en-us".
+	languagePattern = regexp.MustCompile(`^[a-z]{2,8}(-[a-z0-9]{1,8})*$`)
+)
+
+// normalizeLanguage returns the lowercased form of a language tag, or ""
+// if tag is not one. Pages may declare "en_US" for "en-US".
+func normalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if !languagePattern.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
+
+// domainsCond returns a condition matching experts whose URL host is one
+// of domains or a subdomain, with arg adding the query argument.
+func domainsCond(domains []string, arg func(any) string) (string, error) {
+	names := make([]string, len(domains))
+	for i, d := range domains {
+		names[i] = strings.TrimSuffix(strings.ToLower(d), ".")
+		if !domainPattern.MatchString(names[i]) {
+			return "", status.Errorf(codes.InvalidArgument, "invalid domain %q", d)
+		}
+	}
+	// The pattern excludes LIKE wildcards, so the domains can be
+	// concatenated into the LIKE pattern.
+	host := `substring(e.url from '^[a-z]+://([^/:]+)')`
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM unnest(%s::text[]) AS d(name) WHERE %s = d.name OR %s LIKE '%%.' || d.name)`,
+		arg(pq.Array(names)), host, host), nil
+}
+
+// expertLevels maps the expert_type column to the API enum.
+var expertLevels = map[string]pb.ExpertLevel{
+	"ROOT":      pb.ExpertLevel_EXPERT_LEVEL_ROOT,
+	"MIDDLEMAN": pb.ExpertLevel_EXPERT_LEVEL_MIDDLEMAN,
+	"LEAF":      pb.ExpertLevel_EXPERT_LEVEL_LEAF,
+}
+
+// expertColumns are the columns of experts e read by scanExpert.
+const expertColumns = `e.id, e.name, COALESCE(e.url, ''), e.type, e.is_rag_based, e.created_at, e.updated_at,
+	(SELECT COUNT(*) FROM document_chunks c WHERE c.expert_id = e.id), COALESCE(e.language, '')`
+
+// scanExpert reads a row of expertColumns.
+func scanExpert(row interface{ Scan(...any) error }) (*pb.Expert, error) {
+	var (
+		e                pb.Expert
+		level            string
+		isRAG            bool
+		created, updated time.Time
+	)
+	if err := row.Scan(&e.Id, &e.Name, &e.Url, &level, &isRAG, &created, &updated, &e.ChunkCount, &e.Language); err != nil {
+		return nil, err
+	}
+	e.Level = expertLevels[level]
+	if e.Level == pb.ExpertLevel_EXPERT_LEVEL_LEAF {
+		e.ExpertType = pb.ExpertType_EXPERT_TYPE_SIMPLE
+		if isRAG {
+			e.ExpertType = pb.ExpertType_EXPERT_TYPE_RAG
+		}
+	}
+	e.CreatedAt = timestamppb.New(created)
+	e.UpdatedAt = timestamppb.New(updated)
+	return &e, nil
+}
+
+// pageToken encodes the position after the last expert of a page.
+func pageToken(e *pb.Expert) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(e.CreatedAt.AsTime().Format(time.RFC3339Nano) + " " + e.Id))
+}
+
+func parsePageToken(token string) (time.Time, string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		created, id, ok := strings.Cut(string(b), " ")
+		if t, err := time.Parse(time.RFC3339Nano, created); ok && err == nil && uuidPattern.MatchString(id) {
+			return t, id, nil
+		}
+	}
+	return time.Time{}, "", errors.New("invalid page token")
+}
+
+func validateID(id string) error {
+	if !uuidPattern.MatchString(id) {
+		return status.Errorf(codes.InvalidArgument, "invalid expert id %q", id)
+	}
+	return nil
+}
+
+// ListExperts implements expert.v1.ExpertServiceServer
+func (s *server) ListExperts(ctx context.Context, in *pb.ListExpertsRequest) (*pb.ListExpertsResponse, error) {
+	pageSize := int(in.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	switch in.ExpertType {
+	case pb.ExpertType_EXPERT_TYPE_UNSPECIFIED:
+	case pb.ExpertType_EXPERT_TYPE_SIMPLE:
+		conds = append(conds, `e.type = 'LEAF' AND NOT e.is_rag_based`)
+	case pb.ExpertType_EXPERT_TYPE_RAG:
+		conds = append(conds, `e.is_rag_based`)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported expert type %s", in.ExpertType)
+	}
+	if len(in.Domain) > 0 {
+		cond, err := domainsCond(in.Domain, arg)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(in.ExcludeDomain) > 0 {
+		cond, err := domainsCond(in.ExcludeDomain, arg)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, "NOT "+cond)
+	}
+	if in.Language != "" {
+		lang := normalizeLanguage(in.Language)
+		if lang == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid language %q", in.Language)
+		}
+		l := arg(lang)
+		conds = append(conds, fmt.Sprintf(`(e.language = %s OR e.language LIKE %s || '-%%')`, l, l))
+	}
+	if in.Level != pb.ExpertLevel_EXPERT_LEVEL_UNSPECIFIED {
+		var level string
+		for name, l := range expertLevels {
+			if l == in.Level {
+				level = name
+			}
+		}
+		if level == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported level %s", in.Level)
+		}
+		conds = append(conds, "e.type = "+arg(level))
+	}
+	if in.UpdatedAfter != nil {
+		if err := in.UpdatedAfter.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid updated_after: %v", err)
+		}
+		conds = append(conds, "e.updated_at > "+arg(in.UpdatedAfter.AsTime()))
+	}
+	if in.UpdatedBefore != nil {
+		if err := in.UpdatedBefore.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid updated_before: %v", err)
+		}
+		conds = append(conds, "e.updated_at < "+arg(in.UpdatedBefore.AsTime()))
+	}
+	if in.PageToken != "" {
+		created, id, err := parsePageToken(in.PageToken)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		conds = append(conds, fmt.Sprintf("(e.created_at, e.id) < (%s, %s::uuid)", arg(created), arg(id)))
+	}
+
+	query := "SELECT " + expertColumns + " FROM experts e"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	// One extra row tells whether there is a next page.
+	query += " ORDER BY e.created_at DESC, e.id DESC LIMIT " + arg(pageSize+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to list experts")
+	}
+	defer rows.Close()
+	res := &pb.ListExpertsResponse{}
+	for rows.Next() {
+		e, err := scanExpert(rows)
+		if err != nil {
+			return nil, rpcerr.Wrap(err, "failed to scan expert")
+		}
+		res.Experts = append(res.Experts, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to list experts")
+	}
+	if len(res.Experts) > pageSize {
+		res.Experts = res.Experts[:pageSize]
+		res.NextPageToken = pageToken(res.Experts[pageSize-1])
+	}
+	return res, nil
+}
+
+// GetExpert implements expert.v1.ExpertServiceServer
+func (s *server) GetExpert(ctx context.Context, in *pb.GetExpertRequest) (*pb.GetExpertResponse, error) {
+	if err := validateID(in.Id); err != nil {
+		return nil, err
+	}
+	e, err := scanExpert(s.db.QueryRowContext(ctx, "SELECT "+expertColumns+" FROM experts e WHERE e.id = $1", in.Id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "no expert with id %s", in.Id)
+	}
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to load expert")
+	}
+	res := &pb.GetExpertResponse{Expert: e, EmbeddedChunkCounts: map[string]int32{}}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ce.model, COUNT(*) FROM chunk_embeddings ce
+		JOIN document_chunks c ON c.id = ce.chunk_id
+		WHERE c.expert_id = $1
+		GROUP BY ce.model`, in.Id)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to count embeddings")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var model string
+		var count int32
+		if err := rows.Scan(&model, &count); err != nil {
+			return nil, rpcerr.Wrap(err, "failed to scan embedding count")
+		}
+		res.EmbeddedChunkCounts[model] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to count embeddings")
+	}
+
+	if res.Parents, err = s.relatives(ctx, "parent_expert_id", "child_expert_id", in.Id); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to load parents")
+	}
+	if res.Children, err = s.relatives(ctx, "child_expert_id", "parent_expert_id", in.Id); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to load children")
+	}
+	return res, nil
+}
+
+// relatives returns the experts in column rel of the hierarchy rows whose
+// column self is id.
+func (s *server) relatives(ctx context.Context, rel, self, id string) ([]*pb.Expert, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM expert_hierarchy h
+		JOIN experts e ON e.id = h.%s
+		WHERE h.%s = $1
+		ORDER BY e.name`, expertColumns, rel, self), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var experts []*pb.Expert
+	for rows.Next() {
+		e, err := scanExpert(rows)
+		if err != nil {
+			return nil, err
+		}
+		experts = append(experts, e)
+	}
+	return experts, rows.Err()
+}
+
+// DeleteExpert implements expert.v1.ExpertServiceServer
+func (s *server) DeleteExpert(ctx context.Context, in *pb.DeleteExpertRequest) (*pb.DeleteExpertResponse, error) {
+	if err := validateID(in.Id); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var chunks int32
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM document_chunks WHERE expert_id = $1`, in.Id).Scan(&chunks); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to count chunks")
+	}
+	// Chunks, their embeddings and hierarchy links cascade.
+	result, err := tx.ExecContext(ctx, `DELETE FROM experts WHERE id = $1`, in.Id)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to delete expert")
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to delete expert")
+	} else if n == 0 {
+		return nil, status.Errorf(codes.NotFound, "no expert with id %s", in.Id)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to commit deletion")
+	}
+	slog.InfoContext(ctx, "Deleted expert", "id", in.Id, "chunks", chunks)
+	return &pb.DeleteExpertResponse{DeletedChunks: chunks}, nil
+}
+
+// ReindexExpert implements expert.v1.ExpertServiceServer
+func (s *server) ReindexExpert(ctx context.Context, in *pb.ReindexExpertRequest) (*pb.ReindexExpertResponse, error) {
+	if err := validateID(in.Id); err != nil {
+		return nil, err
+	}
+	if s.nc == nil {
+		return nil, status.Error(codes.FailedPrecondition, "reindexing is disabled, -nats-url is not set")
+	}
+	var url string
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(url, '') FROM experts WHERE id = $1`, in.Id).Scan(&url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "no expert with id %s", in.Id)
+	}
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to load expert")
+	}
+	if url == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "expert %s has no page to reindex", in.Id)
+	}
+
+	msg, err := crawl.NewRequestMsg(ctx, url)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to marshal crawl request")
+	}
+	if err := s.nc.PublishMsg(msg); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to publish crawl request: %v", err)
+	}
+	slog.InfoContext(ctx, "Requested reindex", "id", in.Id, "url", url)
+	return &pb.ReindexExpertResponse{Url: url}, nil
 }
 
 func main() {
 	var cfg config
-	flag.StringVar(&cfg.grpcPort, "grpc-port", "50052", "The gRPC port to listen on")
-	flag.StringVar(&cfg.dbConn, "db-conn", "host=postgres user=postgres password=postgres dbname=portal sslmode=disable", "PostgreSQL connection string")
-	flag.StringVar(&cfg.ragSvcAddr, "rag-svc-addr", "rag-service:50051", "The address of the RAG service")
-	flag.Parse()
+	loader := conf.New("expert-service")
+	loader.StringVar(&cfg.grpcPort, "grpc-port", "50052", "The gRPC port to listen on")
+	loader.StringVar(&cfg.dbConn, "db-conn", "host=postgres user=postgres password=postgres dbname=portal sslmode=disable", "PostgreSQL connection string")
+	loader.StringVar(&cfg.ragSvcAddr, "rag-svc-addr", "rag-service:50051", "The address of the RAG service")
+	loader.StringVar(&cfg.natsURL, "nats-url", "", "The URL of the NATS server reindex requests are published to (empty to disable reindexing)")
+	loader.BoolVar(&cfg.autoMigrate, "auto-migrate", false, "Apply pending database migrations before serving")
+	loader.StringVar(&cfg.logLevel, "log-level", "info", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "json", "The log output format (json or text)")
+	loader.StringVar(&cfg.traceExporter, "trace-exporter", "none", "The trace exporter to use (none, stdout or otlp)")
+	loader.StringVar(&cfg.otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP gRPC collector (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	loader.StringVar(&cfg.metricsAddr, "metrics-addr", ":9090", "The address to serve Prometheus metrics on (empty to disable)")
+	loader.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 25*time.Second, "How long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.GRPCServer)
+	loader.BoolVar(&cfg.onDemand, "on-demand", true, "Create experts for unknown pages when a query asks for it")
+	loader.DurationVar(&cfg.onDemandBudget, "on-demand-budget", 5*time.Second, "How long a query waits for its expert to be created before answering PENDING")
+	loader.DurationVar(&cfg.onDemandTimeout, "on-demand-timeout", time.Minute, "How long creating an expert on demand may take")
+	loader.IntVar(&cfg.fetchMaxBytes, "fetch-max-bytes", 5<<20, "The largest page fetched for an expert created on demand")
+	loader.IntVar(&cfg.ragThreshold, "rag-threshold", 4096, "Pages fetched on demand with more characters than this get a RAG expert")
+	loader.IntVar(&cfg.cacheSize, "cache-size", 10000, "The number of answers cached in memory (0 to disable caching)")
+	loader.DurationVar(&cfg.cacheTTL, "cache-ttl", time.Hour, "How long answers are cached")
+	loader.BoolVar(&cfg.sharedCache, "shared-cache", false, "Also cache answers in the database, shared with every replica")
+	loader.StringVar(&cfg.verifier, "verifier", "lexical", "How answers are verified against the content (lexical or none)")
+	loader.Float64Var(&cfg.verifyThreshold, "verify-threshold", verify.DefaultThreshold, "The support below which a claim of an answer is flagged as unsupported")
+	loader.IntVar(&cfg.maxAttempts, "rpc-max-attempts", 3, "Attempts of a RAG service call failing with UNAVAILABLE, up to 5 (1 to disable retries)")
+	loader.IntVar(&cfg.breakerFailures, "breaker-failures", 5, "Consecutive RAG service failures that open the circuit breaker (0 to disable it)")
+	loader.DurationVar(&cfg.breakerOpenFor, "breaker-open-for", 10*time.Second, "How long the circuit breaker fails calls before probing the RAG service again")
+	loader.Required("grpc-port", "db-conn", "rag-svc-addr")
+	loader.Secret("db-conn")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("expert-service", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	// --- Telemetry ---
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   "expert-service",
+		TraceExporter: cfg.traceExporter,
+		OTLPEndpoint:  cfg.otlpEndpoint,
+		MetricsAddr:   cfg.metricsAddr,
+		Certs:         certs,
+	})
+	if err != nil {
+		logger.Fatal("failed to set up telemetry", "error", err)
+	}
+	defer shutdown.Run("telemetry", shutdownTelemetry, cfg.shutdownTimeout)
 
 	// --- Database Connection ---
-	db, err := sql.Open("postgres", cfg.dbConn)
+	dsn, err := certs.PostgresDSN(cfg.dbConn)
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		logger.Fatal("invalid configuration", "error", err)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		logger.Fatal("failed to connect to database", "error", err)
 	}
 	defer db.Close()
-	log.Println("Successfully connected to the database")
+
+	if err := db.Ping(); err != nil {
+		logger.Fatal("failed to ping database", "error", err)
+	}
+	slog.Info("Successfully connected to the database")
+
+	// --- Database Migrations ---
+	// `expert-service [flags] migrate [up|down [N]|version]` manages the schema and exits.
+	if len(loader.Args()) > 0 && loader.Args()[0] == "migrate" {
+		if err := migrations.Command(context.Background(), db, loader.Args()[1:]); err != nil {
+			logger.Fatal("migration failed", "error", err)
+		}
+		return
+	}
+	if cfg.autoMigrate {
+		m, err := migrations.New(db)
+		if err != nil {
+			logger.Fatal("failed to load migrations", "error", err)
+		}
+		if _, err := m.Up(context.Background()); err != nil {
+			logger.Fatal("failed to migrate database", "error", err)
+		}
+	}
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(certs.ClientCredentials()),
+		grpc.WithChainUnaryInterceptor(logger.UnaryClientInterceptor(), auth.UnaryClientInterceptor()),
+	}, telemetry.DialOptions()...)
 
 	// --- gRPC Client for RAG Service ---
-	conn, err := grpc.NewClient(cfg.ragSvcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpcclient.New(cfg.ragSvcAddr, grpcclient.Config{
+		Name:        "rag-service",
+		MaxAttempts: cfg.maxAttempts,
+		Timeouts: map[string]time.Duration{
+			// Indexing embeds every chunk of a page.
+			ragpb.RAGService_IndexContent_FullMethodName:    time.Minute,
+			ragpb.RAGService_RetrieveContext_FullMethodName: 10 * time.Second,
+		},
+		BreakerFailures: cfg.breakerFailures,
+		BreakerOpenFor:  cfg.breakerOpenFor,
+	}, dialOpts...)
 	if err != nil {
-		log.Fatalf("did not connect to RAG service: %v", err)
+		logger.Fatal("did not connect to RAG service", "error", err)
 	}
 	defer conn.Close()
 	ragSvcClient := ragpb.NewRAGServiceClient(conn)
-	log.Println("Successfully connected to RAG service")
+	slog.Info("Successfully connected to RAG service")
+
+	// --- NATS Connection for Reindex Requests ---
+	var nc *nats.Conn
+	if cfg.natsURL != "" {
+		nc, err = nats.Connect(cfg.natsURL, certs.NATSOptions()...)
+		if err != nil {
+			logger.Fatal("failed to connect to NATS", "error", err)
+		}
+		defer nc.Close()
+		slog.Info("Successfully connected to NATS")
+	}
 
 	// --- gRPC Server Setup for this service ---
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.grpcPort))
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		logger.Fatal("failed to listen", "error", err)
+	}
+	serverOpts := append([]grpc.ServerOption{
+		grpc.Creds(certs.ServerCredentials()),
+		grpc.ChainUnaryInterceptor(logger.UnaryServerInterceptor(), auth.UnaryServerInterceptor()),
+	}, telemetry.ServerOptions()...)
+	s := grpc.NewServer(serverOpts...)
+	srv := &server{
+		db:           db,
+		ragSvcClient: ragSvcClient,
+		llm:          llm.NewStub("This is a mock answer from the expert."),
+		nc:           nc,
+	}
+	if cfg.cacheSize > 0 {
+		var shared cache.Cache
+		if cfg.sharedCache {
+			shared = cache.NewPostgres(db)
+		}
+		srv.cache, srv.cacheTTL = cache.New(cfg.cacheSize, shared), cfg.cacheTTL
 	}
-	s := grpc.NewServer()
-	pb.RegisterExpertServiceServer(s, &server{db: db, ragSvcClient: ragSvcClient})
-	log.Printf("server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	if cfg.onDemand {
+		srv.onDemand = &onDemand{
+			fetcher:      fetch.New(cfg.onDemandTimeout, int64(cfg.fetchMaxBytes)),
+			budget:       cfg.onDemandBudget,
+			timeout:      cfg.onDemandTimeout,
+			ragThreshold: cfg.ragThreshold,
+		}
 	}
+	if cfg.verifyThreshold <= 0 || cfg.verifyThreshold > 1 {
+		logger.Fatal("invalid configuration", "error", "-verify-threshold must be in (0, 1]")
+	}
+	switch cfg.verifier {
+	case "lexical":
+		srv.verifier = verify.Lexical{Threshold: cfg.verifyThreshold}
+	case "none":
+	default:
+		logger.Fatal("invalid configuration", "error", fmt.Sprintf("unknown -verifier %q", cfg.verifier))
+	}
+	pb.RegisterExpertServiceServer(s, srv)
+
+	checker := health.New()
+	checker.Add("database", health.DB(db))
+	checker.Add("rag-service", health.GRPC(conn, ragpb.RAGService_ServiceDesc.ServiceName))
+	if nc != nil {
+		checker.Add("nats", health.NATS(nc))
+	}
+	health.RegisterGRPC(s, checker, pb.ExpertService_ServiceDesc.ServiceName)
+	// --- Serve until SIGINT or SIGTERM ---
+	ctx, stop := shutdown.Signals()
+	defer stop()
+	go func() {
+		slog.Info("Server listening", "addr", lis.Addr().String())
+		if err := s.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			logger.Fatal("failed to serve", "error", err)
+		}
+	}()
+	<-ctx.Done()
+	stop()
+
+	// Fail health checks first so clients move away, then let active RPCs
+	// finish. Client connections, the database pool and telemetry are closed
+	// by the deferred calls above.
+	slog.Info("Shutting down", "timeout", cfg.shutdownTimeout)
+	checker.Shutdown()
+	shutdown.GRPC(s, cfg.shutdownTimeout)
+	slog.Info("Server stopped")
 }
@@ -2,19 +2,34 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"portal.com/portal/internal/cache"
+	"portal.com/portal/internal/fetch"
+	"portal.com/portal/internal/llm"
+	"portal.com/portal/internal/verify"
 	pb "portal.com/portal/pkg/expert/v1"
 	ragpb "portal.com/portal/pkg/rag/v1"
 )
 
 // mockRAGServiceClient is a mock implementation of RAGServiceClient.
 type mockRAGServiceClient struct {
-	ragpb.UnimplementedRAGServiceClient
-	// This mock can be extended to control responses for different test cases.
+	ragpb.RAGServiceClient
+	// queries are the queries context was retrieved for.
+	queries []string
 }
 
 // IndexContent is the mock implementation for the RAG service's IndexContent method.
@@ -25,11 +40,19 @@ func (m *mockRAGServiceClient) IndexContent(ctx context.Context, in *ragpb.Index
 
 // RetrieveContext is the mock implementation for the RAG service's RetrieveContext method.
 func (m *mockRAGServiceClient) RetrieveContext(ctx context.Context, in *ragpb.RetrieveContextRequest, opts ...grpc.CallOption) (*ragpb.RetrieveContextResponse, error) {
-	return &ragpb.RetrieveContextResponse{ContextChunks: []string{"mocked chunk"}}, nil
+	m.queries = append(m.queries, in.Query)
+	score := float32(len(m.queries)) / 10
+	return &ragpb.RetrieveContextResponse{
+		ContextChunks: []string{"mocked chunk", "chunk for " + in.Query},
+		Chunks: []*ragpb.Chunk{
+			{Text: "mocked chunk", Index: 0, StartOffset: 0, EndOffset: 12, Heading: "Mock", Score: score},
+			{Text: "chunk for " + in.Query, Index: int32(len(m.queries)), Score: 0.25},
+		},
+	}, nil
 }
 
 func TestCreateOrUpdateExpert(t *testing.T) {
-	db, _, err := sqlmock.New()
+	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
@@ -42,6 +65,15 @@ func TestCreateOrUpdateExpert(t *testing.T) {
 		ragSvcClient: mockRagClient,
 	}
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO experts (type, name, url, is_rag_based, raw_content, language)`)).
+		WithArgs("https://example.com/", "test content", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("expert-1"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM document_chunks WHERE expert_id = $1`)).
+		WithArgs("expert-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
 	req := &pb.CreateOrUpdateExpertRequest{
 		Url:        "https://example.com",
 		Content:    "test content",
@@ -50,16 +82,51 @@ func TestCreateOrUpdateExpert(t *testing.T) {
 
 	res, err := s.CreateOrUpdateExpert(context.Background(), req)
 	if err != nil {
-		t.Errorf("CreateOrUpdateExpert() error = %v, wantErr %v", err, false)
+		t.Fatalf("CreateOrUpdateExpert() error = %v, wantErr %v", err, false)
 	}
 
-	if res.ExpertId != "mock-expert-id" {
-		t.Errorf("expected mock expert id, got %s", res.ExpertId)
+	if res.ExpertId != "expert-1" {
+		t.Errorf("expected expert id expert-1, got %s", res.ExpertId)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateOrUpdateRAGExpert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	s := &server{
+		db:           db,
+		ragSvcClient: &mockRAGServiceClient{},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE experts SET raw_content = NULL, language = NULLIF($2, ''), content_version = nextval('expert_content_versions') WHERE url = $1 RETURNING id`)).
+		WithArgs("https://example.com/", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("expert-2"))
+
+	res, err := s.CreateOrUpdateExpert(context.Background(), &pb.CreateOrUpdateExpertRequest{
+		Url:        "https://example.com",
+		Content:    "long content",
+		ExpertType: pb.ExpertType_EXPERT_TYPE_RAG,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateExpert() error = %v", err)
+	}
+	if res.ExpertId != "expert-2" {
+		t.Errorf("expected expert id expert-2, got %s", res.ExpertId)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
 
 func TestQueryExpert(t *testing.T) {
-	db, _, err := sqlmock.New()
+	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
@@ -70,19 +137,532 @@ func TestQueryExpert(t *testing.T) {
 	s := &server{
 		db:           db,
 		ragSvcClient: mockRagClient,
+		llm:          llm.NewStub("This is a mock answer from the expert."),
 	}
 
+	// A bare domain may name an https or an http expert.
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based, COALESCE(raw_content, ''), content_version FROM experts`)).
+		WithArgs(pq.Array([]string{"https://example.com/", "http://example.com/"})).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "is_rag_based", "raw_content", "content_version"}).AddRow("http://example.com/", true, "", 1))
+
 	req := &pb.QueryExpertRequest{
-		Url:   "https://example.com",
+		Url:   "example.com",
 		Query: "what is this?",
 	}
 
 	res, err := s.QueryExpert(context.Background(), req)
 	if err != nil {
-		t.Errorf("QueryExpert() error = %v, wantErr %v", err, false)
+		t.Fatalf("QueryExpert() error = %v, wantErr %v", err, false)
 	}
 
 	if res.Answer != "This is a mock answer from the expert." {
 		t.Errorf("unexpected mock answer: %s", res.Answer)
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestQueryExpertStatusCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	s := &server{
+		db:           db,
+		ragSvcClient: &mockRAGServiceClient{},
+		llm:          llm.NewStub("answer"),
+	}
+
+	_, err = s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://example.com"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a missing query, got %v", err)
+	}
+	_, err = s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "ftp://example.com", Query: "q"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a non-http URL, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+		WithArgs(pq.Array([]string{"https://unknown.example/"})).
+		WillReturnError(sql.ErrNoRows)
+	_, err = s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://unknown.example", Query: "q"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown expert, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+		WithArgs(pq.Array([]string{"https://example.com/"})).
+		WillReturnError(errors.New("connection reset"))
+	_, err = s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://example.com", Query: "q"})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal for a database failure, got %v", err)
+	}
+}
+
+// countingLLM counts the completions it generates and keeps the last prompt.
+type countingLLM struct {
+	llm.Client
+	calls  int
+	prompt string
+}
+
+func (c *countingLLM) Generate(ctx context.Context, prompt string) (*llm.Completion, error) {
+	c.calls++
+	c.prompt = prompt
+	return c.Client.Generate(ctx, prompt)
+}
+
+func TestQueryExpertRetrievesForEachForm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rag := &mockRAGServiceClient{}
+	model := &countingLLM{Client: llm.NewStub("answer")}
+	s := &server{db: db, ragSvcClient: rag, llm: model}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "is_rag_based", "raw_content", "content_version"}).AddRow("https://example.com/", true, "", 1))
+
+	res, err := s.QueryExpert(context.Background(), &pb.QueryExpertRequest{
+		Url:              "https://example.com/",
+		Query:            "what is colly and how do I install it",
+		RetrievalQueries: []string{"what is colly", "how do I install it", "colly", "install", "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("QueryExpert() error = %v", err)
+	}
+	// The query and up to maxRetrievalQueries other forms are retrieved for.
+	want := []string{"what is colly and how do I install it", "what is colly", "how do I install it", "colly", "install"}
+	if !slices.Equal(rag.queries, want) {
+		t.Errorf("retrieved context for %q, want %q", rag.queries, want)
+	}
+	if n := strings.Count(model.prompt, "mocked chunk"); n != 1 || !strings.Contains(model.prompt, "chunk for install") {
+		t.Errorf("expected every chunk once in the prompt, got %q", model.prompt)
+	}
+	if !strings.Contains(model.prompt, "Question: what is colly and how do I install it") {
+		t.Errorf("expected the prompt to ask the query, got %q", model.prompt)
+	}
+	// Chunks are passages once, with their best score, most relevant first.
+	if n := len(res.Passages); n != 6 {
+		t.Fatalf("expected 6 passages, got %d: %v", n, res.Passages)
+	}
+	if p := res.Passages[0]; p.Text != "mocked chunk" || p.Score != 0.5 || p.EndOffset != 12 || p.Heading != "Mock" {
+		t.Errorf("first passage = %v, want the mocked chunk with its best score", p)
+	}
+	if p := res.Passages[1]; p.ChunkIndex != 1 || p.Text != "chunk for what is colly and how do I install it" {
+		t.Errorf("second passage = %v, want the first chunk retrieved for the query", p)
+	}
+}
+
+func TestQueryExpertPassagesOfSimpleExpert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	s := &server{db: db, llm: llm.NewStub("answer")}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "is_rag_based", "raw_content", "content_version"}).
+			AddRow("https://go-colly.org/", false, "Colly\nA scraping framework for Go.\nInstall\nRun go get to install Colly.", 1))
+
+	res, err := s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://go-colly.org/", Query: "how do I install colly?"})
+	if err != nil {
+		t.Fatalf("QueryExpert() error = %v", err)
+	}
+	if len(res.Passages) != 1 {
+		t.Fatalf("expected 1 passage, got %v", res.Passages)
+	}
+	p := res.Passages[0]
+	if p.Text != "Run go get to install Colly." || p.ChunkIndex != 3 || p.StartOffset != 43 || p.EndOffset != 71 || p.Heading != "Install" || p.Score != 1 {
+		t.Errorf("passage = %v, want the install line", p)
+	}
+}
+
+func TestQueryExpertVerifiesAnswer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	s := &server{
+		db:       db,
+		llm:      llm.NewStub("Colly is a scraping framework for Go. It was written by Google."),
+		verifier: verify.Lexical{Threshold: verify.DefaultThreshold},
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "is_rag_based", "raw_content", "content_version"}).
+			AddRow("https://go-colly.org/", false, "Colly is a fast scraping framework for Go. It is open source.", 1))
+
+	res, err := s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://go-colly.org/", Query: "what is colly?"})
+	if err != nil {
+		t.Fatalf("QueryExpert() error = %v", err)
+	}
+	v := res.Verification
+	if v == nil || len(v.Claims) != 2 || v.UnsupportedClaims != 1 || v.Verifier != "lexical" {
+		t.Fatalf("Verification = %v, want 2 claims with 1 unsupported", v)
+	}
+	if c := v.Claims[0]; !c.Supported || c.Evidence != "Colly is a fast scraping framework for Go." {
+		t.Errorf("first claim = %v, want it supported by the first sentence", c)
+	}
+	if c := v.Claims[1]; c.Supported || c.Text != "It was written by Google." {
+		t.Errorf("second claim = %v, want it unsupported", c)
+	}
+}
+
+func TestQueryExpertCachesAnswers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	model := &countingLLM{Client: llm.NewStub("answer")}
+	s := &server{
+		db:           db,
+		ragSvcClient: &mockRAGServiceClient{},
+		llm:          model,
+		cache:        cache.New(10, nil),
+		cacheTTL:     time.Minute,
+	}
+	expectExpert := func(version int64) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based, COALESCE(raw_content, ''), content_version FROM experts`)).
+			WithArgs(pq.Array([]string{"https://example.com/"})).
+			WillReturnRows(sqlmock.NewRows([]string{"url", "is_rag_based", "raw_content", "content_version"}).AddRow("https://example.com/", false, "content", version))
+	}
+
+	// Spellings of the same query share an answer.
+	expectExpert(3)
+	expectExpert(3)
+	for _, q := range []string{"What is Colly?", "  what is   colly "} {
+		res, err := s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://example.com/", Query: q})
+		if err != nil || res.Answer != "answer" {
+			t.Fatalf("unexpected response %v, %v", res, err)
+		}
+	}
+	if model.calls != 1 {
+		t.Errorf("expected a single LLM call, got %d", model.calls)
+	}
+
+	// A new content version is answered afresh.
+	expectExpert(4)
+	if _, err := s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://example.com/", Query: "what is colly"}); err != nil {
+		t.Fatal(err)
+	}
+	if model.calls != 2 {
+		t.Errorf("expected a second LLM call, got %d", model.calls)
+	}
+
+	// Updating the expert drops its cached answers.
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO experts`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("expert-1"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM document_chunks`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	_, err = s.CreateOrUpdateExpert(context.Background(), &pb.CreateOrUpdateExpertRequest{Url: "https://example.com/", Content: "new", ExpertType: pb.ExpertType_EXPERT_TYPE_SIMPLE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := s.cache.(*cache.LRU).Len(); n != 0 {
+		t.Errorf("expected the cache to be empty, %d entries left", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetContentVersions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := &server{db: db}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, content_version FROM experts WHERE url = ANY($1::text[])`)).
+		WithArgs(pq.Array([]string{"https://example.com/", "https://missing.example/"})).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "content_version"}).AddRow("https://example.com/", 5))
+	res, err := s.GetContentVersions(context.Background(), &pb.GetContentVersionsRequest{Urls: []string{"https://Example.com", "https://missing.example/"}})
+	if err != nil || len(res.Versions) != 1 || res.Versions["https://example.com/"] != 5 {
+		t.Errorf("unexpected versions %v, %v", res, err)
+	}
+}
+
+// fakeFetcher returns page for every page, once release is closed if set.
+type fakeFetcher struct {
+	page    *fetch.Page
+	err     error
+	release chan struct{}
+	fetched chan string
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context, url string) (*fetch.Page, error) {
+	if f.fetched != nil {
+		f.fetched <- url
+	}
+	if f.release != nil {
+		<-f.release
+	}
+	return f.page, f.err
+}
+
+func TestQueryExpertCreatesMissingExpert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	s := &server{
+		db:           db,
+		ragSvcClient: &mockRAGServiceClient{},
+		llm:          llm.NewStub("answer"),
+		onDemand: &onDemand{
+			fetcher:      &fakeFetcher{page: &fetch.Page{Text: "page text", Language: "en"}},
+			budget:       time.Second,
+			timeout:      time.Second,
+			ragThreshold: 4096,
+		},
+	}
+
+	columns := []string{"url", "is_rag_based", "raw_content", "content_version"}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+		WithArgs(pq.Array([]string{"https://new.example/"})).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO experts`)).
+		WithArgs("https://new.example/", "page text", "en").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("expert-1"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM document_chunks`)).
+		WithArgs("expert-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+		WithArgs(pq.Array([]string{"https://new.example/"})).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("https://new.example/", false, "page text", 7))
+
+	res, err := s.QueryExpert(context.Background(), &pb.QueryExpertRequest{Url: "https://new.example", Query: "q", FetchIfMissing: true})
+	if err != nil {
+		t.Fatalf("QueryExpert() error = %v", err)
+	}
+	if res.Status != pb.ExpertStatus_EXPERT_STATUS_READY || res.Answer != "answer" {
+		t.Errorf("unexpected response %v", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestQueryExpertPendingWhileCreating(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	fetcher := &fakeFetcher{err: errors.New("unexpected status 404 Not Found"), release: make(chan struct{}), fetched: make(chan string, 2)}
+	s := &server{
+		db:           db,
+		ragSvcClient: &mockRAGServiceClient{},
+		llm:          llm.NewStub("answer"),
+		onDemand:     &onDemand{fetcher: fetcher, budget: 10 * time.Millisecond, timeout: time.Second},
+	}
+	req := &pb.QueryExpertRequest{Url: "https://slow.example/", Query: "q", FetchIfMissing: true}
+	for range 3 {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).
+			WithArgs(pq.Array([]string{"https://slow.example/"})).
+			WillReturnError(sql.ErrNoRows)
+	}
+
+	// Queries during the creation are answered PENDING and share it.
+	for range 2 {
+		res, err := s.QueryExpert(context.Background(), req)
+		if err != nil || res.Status != pb.ExpertStatus_EXPERT_STATUS_PENDING || res.RetryAfterSeconds != 2 {
+			t.Fatalf("expected a PENDING response, got %v, %v", res, err)
+		}
+	}
+	if url := <-fetcher.fetched; url != "https://slow.example/" {
+		t.Errorf("unexpected fetch of %s", url)
+	}
+
+	// Once the creation fails, polls get its error without fetching again.
+	close(fetcher.release)
+	_, err = s.QueryExpert(context.Background(), req)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a missing page, got %v", err)
+	}
+	if len(fetcher.fetched) != 0 {
+		t.Error("expected a single fetch")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// Without fetch_if_missing the expert is simply not found.
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT url, is_rag_based`)).WillReturnError(sql.ErrNoRows)
+	req.FetchIfMissing = false
+	if _, err := s.QueryExpert(context.Background(), req); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+// expertRow returns a row of expertColumns.
+func expertRow(rows *sqlmock.Rows, id, url string, isRAG bool, created time.Time) *sqlmock.Rows {
+	return rows.AddRow(id, url, url, "LEAF", isRAG, created, created, 3, "en")
+}
+
+var expertRowColumns = []string{"id", "name", "url", "type", "is_rag_based", "created_at", "updated_at", "chunks", "language"}
+
+func TestListExperts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := &server{db: db}
+
+	created := time.Date(2024, 10, 26, 10, 0, 0, 0, time.UTC)
+	stale := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows(expertRowColumns)
+	expertRow(rows, "00000000-0000-0000-0000-000000000003", "https://docs.example.com/", true, created)
+	expertRow(rows, "00000000-0000-0000-0000-000000000002", "https://example.com/", true, created.Add(-time.Hour))
+	expertRow(rows, "00000000-0000-0000-0000-000000000001", "https://example.com/a", true, created.Add(-2*time.Hour))
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE e.is_rag_based AND EXISTS (SELECT 1 FROM unnest($1::text[]) AS d(name) WHERE substring(e.url from '^[a-z]+://([^/:]+)') = d.name OR substring(e.url from '^[a-z]+://([^/:]+)') LIKE '%.' || d.name) AND NOT EXISTS (SELECT 1 FROM unnest($2::text[]) AS d(name) WHERE`)+
+		`.*`+regexp.QuoteMeta(`AND (e.language = $3 OR e.language LIKE $3 || '-%') AND e.updated_at > $4 AND e.updated_at < $5 ORDER BY e.created_at DESC, e.id DESC LIMIT $6`)).
+		WithArgs(pq.Array([]string{"example.com"}), pq.Array([]string{"old.example.com"}), "en-gb", stale.Add(-24*time.Hour), stale, 3).
+		WillReturnRows(rows)
+
+	res, err := s.ListExperts(context.Background(), &pb.ListExpertsRequest{
+		PageSize:      2,
+		ExpertType:    pb.ExpertType_EXPERT_TYPE_RAG,
+		Domain:        []string{"Example.com"},
+		ExcludeDomain: []string{"old.example.com"},
+		Language:      "en_GB",
+		UpdatedAfter:  timestamppb.New(stale.Add(-24 * time.Hour)),
+		UpdatedBefore: timestamppb.New(stale),
+	})
+	if err != nil {
+		t.Fatalf("ListExperts() error = %v", err)
+	}
+	if len(res.Experts) != 2 || res.Experts[0].ExpertType != pb.ExpertType_EXPERT_TYPE_RAG || res.Experts[0].ChunkCount != 3 {
+		t.Fatalf("unexpected experts %v", res.Experts)
+	}
+	if res.NextPageToken == "" {
+		t.Fatal("expected a next page token")
+	}
+
+	// The next page starts after the last expert of the first one.
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE (e.created_at, e.id) < ($1, $2::uuid) ORDER BY`)).
+		WithArgs(created.Add(-time.Hour), "00000000-0000-0000-0000-000000000002", 3).
+		WillReturnRows(expertRow(sqlmock.NewRows(expertRowColumns), "00000000-0000-0000-0000-000000000001", "https://example.com/a", false, created.Add(-2*time.Hour)))
+	res, err = s.ListExperts(context.Background(), &pb.ListExpertsRequest{PageSize: 2, PageToken: res.NextPageToken})
+	if err != nil {
+		t.Fatalf("ListExperts() error = %v", err)
+	}
+	if len(res.Experts) != 1 || res.NextPageToken != "" || res.Experts[0].ExpertType != pb.ExpertType_EXPERT_TYPE_SIMPLE {
+		t.Errorf("unexpected last page %v", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	for _, req := range []*pb.ListExpertsRequest{{Domain: []string{"%"}}, {ExcludeDomain: []string{"a b"}}, {Language: "not a tag"}, {PageToken: "bad"}} {
+		if _, err := s.ListExperts(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+}
+
+func TestGetExpert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := &server{db: db}
+
+	id := "00000000-0000-0000-0000-000000000001"
+	created := time.Date(2024, 10, 26, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM experts e WHERE e.id = $1`)).
+		WithArgs(id).
+		WillReturnRows(expertRow(sqlmock.NewRows(expertRowColumns), id, "https://example.com/", true, created))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT ce.model, COUNT(*) FROM chunk_embeddings ce`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"model", "count"}).AddRow("hash-256", 3))
+	mock.ExpectQuery(regexp.QuoteMeta(`JOIN experts e ON e.id = h.parent_expert_id`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(expertRowColumns).AddRow("00000000-0000-0000-0000-00000000000a", "Go", "", "MIDDLEMAN", false, created, created, 0, ""))
+	mock.ExpectQuery(regexp.QuoteMeta(`JOIN experts e ON e.id = h.child_expert_id`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(expertRowColumns))
+
+	res, err := s.GetExpert(context.Background(), &pb.GetExpertRequest{Id: id})
+	if err != nil {
+		t.Fatalf("GetExpert() error = %v", err)
+	}
+	if res.Expert.Url != "https://example.com/" || res.EmbeddedChunkCounts["hash-256"] != 3 {
+		t.Errorf("unexpected expert %v", res)
+	}
+	if len(res.Parents) != 1 || res.Parents[0].Level != pb.ExpertLevel_EXPERT_LEVEL_MIDDLEMAN || res.Parents[0].ExpertType != pb.ExpertType_EXPERT_TYPE_UNSPECIFIED {
+		t.Errorf("unexpected parents %v", res.Parents)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	if _, err := s.GetExpert(context.Background(), &pb.GetExpertRequest{Id: "1"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a malformed id, got %v", err)
+	}
+}
+
+func TestDeleteExpert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := &server{db: db}
+
+	id := "00000000-0000-0000-0000-000000000001"
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM document_chunks WHERE expert_id = $1`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM experts WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	res, err := s.DeleteExpert(context.Background(), &pb.DeleteExpertRequest{Id: id})
+	if err != nil || res.DeletedChunks != 12 {
+		t.Fatalf("DeleteExpert() = %v, %v", res, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM document_chunks`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM experts`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if _, err := s.DeleteExpert(context.Background(), &pb.DeleteExpertRequest{Id: id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown expert, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReindexExpertRequiresNATS(t *testing.T) {
+	s := &server{}
+	_, err := s.ReindexExpert(context.Background(), &pb.ReindexExpertRequest{Id: "00000000-0000-0000-0000-000000000001"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition without NATS, got %v", err)
+	}
 }
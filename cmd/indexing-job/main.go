@@ -3,63 +3,141 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"flag"
 	"log"
-	"runtime"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	expertpb "portal.com/portal/pkg/expert/v1"
+
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/grpcclient"
+	"portal.com/portal/internal/health"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/shutdown"
+	"portal.com/portal/internal/telemetry"
+	"portal.com/portal/internal/tlsconfig"
 )
 
 // CrawledContentMessage is the structure of messages received from the crawler.
 type CrawledContentMessage struct {
 	URL       string    `json:"url"`
 	Content   string    `json:"content"`
+	Language  string    `json:"language,omitempty"`
 	CrawledAt time.Time `json:"crawled_at"`
 }
 
 // config holds all the configuration for the service.
 type config struct {
-	natsURL        string
-	expertSvcAddr  string
-	ragThreshold   int
+	natsURL         string
+	expertSvcAddr   string
+	ragThreshold    int
+	healthAddr      string
+	logLevel        string
+	logFormat       string
+	traceExporter   string
+	otlpEndpoint    string
+	metricsAddr     string
+	shutdownTimeout time.Duration
+	tls             tlsconfig.Config
+	maxAttempts     int
+	breakerFailures int
+	breakerOpenFor  time.Duration
 }
 
 func main() {
 	var cfg config
-	flag.StringVar(&cfg.natsURL, "nats-url", "nats://nats:4222", "The URL of the NATS server")
-	flag.StringVar(&cfg.expertSvcAddr, "expert-svc-addr", "expert-service:50052", "The address of the Expert service")
-	flag.IntVar(&cfg.ragThreshold, "rag-threshold", 4096, "The content length threshold to create a RAG expert")
-	flag.Parse()
+	loader := conf.New("indexing-job")
+	loader.StringVar(&cfg.natsURL, "nats-url", "nats://nats:4222", "The URL of the NATS server")
+	loader.StringVar(&cfg.expertSvcAddr, "expert-svc-addr", "expert-service:50052", "The address of the Expert service")
+	loader.IntVar(&cfg.ragThreshold, "rag-threshold", 4096, "The content length threshold to create a RAG expert")
+	loader.StringVar(&cfg.healthAddr, "health-addr", ":8081", "The address to serve the /healthz and /readyz endpoints on")
+	loader.StringVar(&cfg.logLevel, "log-level", "info", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "json", "The log output format (json or text)")
+	loader.StringVar(&cfg.traceExporter, "trace-exporter", "none", "The trace exporter to use (none, stdout or otlp)")
+	loader.StringVar(&cfg.otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP gRPC collector (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	loader.StringVar(&cfg.metricsAddr, "metrics-addr", ":9090", "The address to serve Prometheus metrics on (empty to disable)")
+	loader.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 25*time.Second, "How long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.Client)
+	loader.IntVar(&cfg.maxAttempts, "rpc-max-attempts", 3, "Attempts of an Expert service call failing with UNAVAILABLE, up to 5 (1 to disable retries)")
+	loader.IntVar(&cfg.breakerFailures, "breaker-failures", 5, "Consecutive Expert service failures that open the circuit breaker (0 to disable it)")
+	loader.DurationVar(&cfg.breakerOpenFor, "breaker-open-for", 10*time.Second, "How long the circuit breaker fails calls before probing the Expert service again")
+	loader.Required("nats-url", "expert-svc-addr")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("indexing-job", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	// --- Telemetry ---
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   "indexing-job",
+		TraceExporter: cfg.traceExporter,
+		OTLPEndpoint:  cfg.otlpEndpoint,
+		MetricsAddr:   cfg.metricsAddr,
+		Certs:         certs,
+	})
+	if err != nil {
+		logger.Fatal("failed to set up telemetry", "error", err)
+	}
+	defer shutdown.Run("telemetry", shutdownTelemetry, cfg.shutdownTimeout)
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(certs.ClientCredentials()),
+		grpc.WithChainUnaryInterceptor(logger.UnaryClientInterceptor()),
+	}, telemetry.DialOptions()...)
 
 	// --- gRPC Client for Expert Service ---
-	conn, err := grpc.NewClient(cfg.expertSvcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpcclient.New(cfg.expertSvcAddr, grpcclient.Config{
+		Name:        "expert-service",
+		MaxAttempts: cfg.maxAttempts,
+		Timeouts: map[string]time.Duration{
+			expertpb.ExpertService_CreateOrUpdateExpert_FullMethodName: 30 * time.Second,
+		},
+		BreakerFailures: cfg.breakerFailures,
+		BreakerOpenFor:  cfg.breakerOpenFor,
+	}, dialOpts...)
 	if err != nil {
-		log.Fatalf("did not connect to Expert service: %v", err)
+		logger.Fatal("did not connect to Expert service", "error", err)
 	}
 	defer conn.Close()
 	expertSvcClient := expertpb.NewExpertServiceClient(conn)
-	log.Println("Successfully connected to Expert service")
+	slog.Info("Successfully connected to Expert service")
 
 	// --- NATS Connection ---
-	nc, err := nats.Connect(cfg.natsURL)
+	nc, err := nats.Connect(cfg.natsURL, certs.NATSOptions()...)
 	if err != nil {
-		log.Fatalf("failed to connect to NATS: %v", err)
+		logger.Fatal("failed to connect to NATS", "error", err)
 	}
 	defer nc.Close()
-	log.Println("Successfully connected to NATS")
+	slog.Info("Successfully connected to NATS")
 
 	// --- NATS Subscription ---
 	subject := "crawled-content"
 	_, err = nc.Subscribe(subject, func(msg *nats.Msg) {
-		log.Printf("Received a message on subject %s", subject)
+		// The request ID and trace context set by the crawler follow the page
+		// into the Expert and RAG services.
+		msgCtx := logger.ExtractNATS(context.Background(), msg)
+		msgCtx = telemetry.ExtractNATS(msgCtx, msg)
+		msgCtx, span := telemetry.Tracer("indexing-job").Start(msgCtx, subject+" process",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+		)
+		defer span.End()
+		slog.InfoContext(msgCtx, "Received a message", "subject", subject)
 		var contentMsg CrawledContentMessage
 		if err := json.Unmarshal(msg.Data, &contentMsg); err != nil {
-			log.Printf("Error unmarshalling message: %v", err)
+			slog.ErrorContext(msgCtx, "Error unmarshalling message", "error", err)
 			return // Don't process malformed messages
 		}
 
@@ -74,26 +152,47 @@ func main() {
 			Url:        contentMsg.URL,
 			Content:    contentMsg.Content,
 			ExpertType: expertType,
+			Language:   contentMsg.Language,
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		_, err := expertSvcClient.CreateOrUpdateExpert(ctx, req)
+		_, err := expertSvcClient.CreateOrUpdateExpert(msgCtx, req)
 		if err != nil {
-			log.Printf("Failed to call CreateOrUpdateExpert for URL %s: %v", contentMsg.URL, err)
+			slog.ErrorContext(msgCtx, "Failed to call CreateOrUpdateExpert", "url", contentMsg.URL, "error", err)
+			span.SetStatus(codes.Error, err.Error())
 			// In a real app, you might want to implement a retry mechanism or a dead-letter queue.
 			return
 		}
 
-		log.Printf("Successfully processed and indexed URL: %s", contentMsg.URL)
+		slog.InfoContext(msgCtx, "Successfully processed and indexed URL", "url", contentMsg.URL)
 	})
 	if err != nil {
-		log.Fatalf("failed to subscribe to subject %s: %v", subject, err)
+		logger.Fatal("failed to subscribe", "subject", subject, "error", err)
 	}
 
-	log.Printf("Subscribed to subject '%s'", subject)
+	slog.Info("Subscribed to subject", "subject", subject)
 
-	// Keep the worker alive so the subscription can process messages.
-	runtime.Goexit()
+	// --- Health Endpoints ---
+	checker := health.New()
+	checker.Add("nats", health.NATS(nc))
+	checker.Add("expert-service", health.GRPC(conn, expertpb.ExpertService_ServiceDesc.ServiceName))
+	healthServer, err := health.Serve(cfg.healthAddr, checker)
+	if err != nil {
+		logger.Fatal("failed to serve health endpoints", "error", err)
+	}
+	defer shutdown.Run("health server", healthServer.Shutdown, cfg.shutdownTimeout)
+
+	// --- Run until SIGINT or SIGTERM ---
+	ctx, stop := shutdown.Signals()
+	defer stop()
+	<-ctx.Done()
+	stop()
+
+	// Draining stops the subscription and waits for the messages already
+	// received to be indexed before closing the connection.
+	slog.Info("Shutting down", "timeout", cfg.shutdownTimeout)
+	checker.Shutdown()
+	if err := shutdown.NATS(nc, cfg.shutdownTimeout); err != nil {
+		slog.Error("Failed to drain NATS connection", "error", err)
+	}
+	slog.Info("Indexing job stopped")
 }
@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/embedding"
+	"portal.com/portal/internal/eval"
+	"portal.com/portal/internal/grpcclient"
+	"portal.com/portal/internal/llm"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/passage"
+	"portal.com/portal/internal/tlsconfig"
+	"portal.com/portal/internal/urlnorm"
+	"portal.com/portal/internal/verify"
+	expertpb "portal.com/portal/pkg/expert/v1"
+	orchpb "portal.com/portal/pkg/orchestrator/v1"
+	ragpb "portal.com/portal/pkg/rag/v1"
+)
+
+// config holds all the configuration for the tool.
+type config struct {
+	dataset       string
+	target        string
+	k             int
+	orchSvcAddr   string
+	expertSvcAddr string
+	ragSvcAddr    string
+	corpus        string
+	embedModel    string
+	embedDim      int
+	timeout       time.Duration
+	out           string
+	baseline      string
+	logLevel      string
+	logFormat     string
+	tls           tlsconfig.Config
+}
+
+// target answers the queries of cases.
+type target struct {
+	name string
+	// answers reports whether the target answers queries, or only finds
+	// pages and passages.
+	answers bool
+	run     func(ctx context.Context, c *eval.Case) (*eval.Output, error)
+}
+
+// orchestratorTarget searches like the API Gateway does, so experts are
+// selected by the orchestrator. The sources' snippets are the passages.
+func orchestratorTarget(client orchpb.QueryOrchestratorServiceClient, k int) *target {
+	return &target{name: "orchestrator", answers: true, run: func(ctx context.Context, c *eval.Case) (*eval.Output, error) {
+		res, err := client.Search(ctx, &orchpb.SearchRequest{Query: c.Query, MaxSources: int32(min(k, maxSources))})
+		if err != nil {
+			return nil, err
+		}
+		out := &eval.Output{Answer: res.Summary}
+		for _, s := range res.Sources {
+			out.URLs = append(out.URLs, s.Url)
+			out.Passages = append(out.Passages, s.Snippet)
+		}
+		return out, nil
+	}}
+}
+
+// maxSources is the most sources the orchestrator consults.
+const maxSources = 10
+
+// scored is what an expert found for a case.
+type scored struct {
+	url      string
+	answer   string
+	passages []scoredPassage
+}
+
+type scoredPassage struct {
+	text  string
+	score float32
+}
+
+// rank turns what the experts of a case found into an output: pages by the
+// score of their best passage, and passages by score, ties keeping the order
+// of the experts. The answer is the best page's.
+func rank(found []scored) *eval.Output {
+	best := func(s scored) float32 {
+		if len(s.passages) == 0 {
+			return -1
+		}
+		return s.passages[0].score
+	}
+	for _, s := range found {
+		sort.SliceStable(s.passages, func(i, j int) bool { return s.passages[i].score > s.passages[j].score })
+	}
+	sort.SliceStable(found, func(i, j int) bool { return best(found[i]) > best(found[j]) })
+	out := &eval.Output{}
+	var all []scoredPassage
+	for _, s := range found {
+		out.URLs = append(out.URLs, s.url)
+		all = append(all, s.passages...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+	for _, p := range all {
+		out.Passages = append(out.Passages, p.text)
+	}
+	if len(found) > 0 {
+		out.Answer = found[0].answer
+	}
+	return out
+}
+
+// consult calls ask for every candidate expert of c. Experts that fail are
+// left out; the case fails only if every expert does.
+func consult(ctx context.Context, c *eval.Case, ask func(ctx context.Context, url string) (scored, error)) (*eval.Output, error) {
+	urls := c.Candidates()
+	if len(urls) == 0 {
+		return nil, errors.New("the case has no urls or expected_urls to consult")
+	}
+	var found []scored
+	var errs []error
+	for _, url := range urls {
+		s, err := ask(ctx, url)
+		if err != nil {
+			slog.WarnContext(ctx, "Expert failed", "case", c.ID, "url", url, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+		found = append(found, s)
+	}
+	if len(found) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rank(found), nil
+}
+
+// expertTarget asks the experts of each case directly, leaving expert
+// selection out.
+func expertTarget(client expertpb.ExpertServiceClient) *target {
+	return &target{name: "expert", answers: true, run: func(ctx context.Context, c *eval.Case) (*eval.Output, error) {
+		return consult(ctx, c, func(ctx context.Context, url string) (scored, error) {
+			res, err := client.QueryExpert(ctx, &expertpb.QueryExpertRequest{Url: url, Query: c.Query})
+			if err != nil {
+				return scored{}, err
+			}
+			if res.Status == expertpb.ExpertStatus_EXPERT_STATUS_PENDING {
+				return scored{}, errors.New("the expert is not ready")
+			}
+			s := scored{url: url, answer: res.Answer}
+			for _, p := range res.Passages {
+				s.passages = append(s.passages, scoredPassage{text: p.Text, score: p.Score})
+			}
+			return s, nil
+		})
+	}}
+}
+
+// ragTarget retrieves the chunks of the experts of each case, measuring
+// retrieval alone. It does not answer.
+func ragTarget(client ragpb.RAGServiceClient) *target {
+	return &target{name: "rag", run: func(ctx context.Context, c *eval.Case) (*eval.Output, error) {
+		return consult(ctx, c, func(ctx context.Context, url string) (scored, error) {
+			res, err := client.RetrieveContext(ctx, &ragpb.RetrieveContextRequest{Url: url, Query: c.Query})
+			if err != nil {
+				return scored{}, err
+			}
+			s := scored{url: url}
+			for _, ch := range res.Chunks {
+				s.passages = append(s.passages, scoredPassage{text: ch.Text, score: ch.Score})
+			}
+			return s, nil
+		})
+	}}
+}
+
+// page is a page of the offline corpus, in the format of the crawler's
+// crawled-content messages.
+type page struct {
+	URL     string `json:"url"`
+	Content string `json:"content"`
+}
+
+// loadCorpus reads pages from JSONL, one page per line.
+func loadCorpus(path string) ([]page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var pages []page
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for n := 1; scanner.Scan(); n++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var p page
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			return nil, fmt.Errorf("%s: line %d: %w", path, n, err)
+		}
+		if p.URL == "" {
+			return nil, fmt.Errorf("%s: line %d: url is required", path, n)
+		}
+		pages = append(pages, p)
+	}
+	return pages, scanner.Err()
+}
+
+// offlinePassages is the number of passages of a page answers are generated
+// from offline, as many as the RAG service retrieves.
+const offlinePassages = 5
+
+// offlineTarget answers in process from a corpus, without any service: the
+// lines of pages are embedded and ranked by their similarity to the query,
+// and the model is a stub answering with the content's sentence closest to
+// the question, so runs are deterministic and need no network. Cases
+// without urls consult the whole corpus.
+func offlineTarget(ctx context.Context, pages []page, embedder embedding.Embedder, model llm.Client) (*target, error) {
+	type line struct {
+		text   string
+		vector []float32
+	}
+	lines := map[string][]line{}
+	var all []string
+	for _, p := range pages {
+		var texts []string
+		for _, l := range passage.Lines(p.Content) {
+			texts = append(texts, l.Text)
+		}
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed %s: %w", p.URL, err)
+		}
+		url := canonical(p.URL)
+		for i, text := range texts {
+			lines[url] = append(lines[url], line{text: text, vector: vectors[i]})
+		}
+		all = append(all, url)
+	}
+	return &target{name: "offline", answers: true, run: func(ctx context.Context, c *eval.Case) (*eval.Output, error) {
+		vectors, err := embedder.Embed(ctx, []string{c.Query})
+		if err != nil {
+			return nil, err
+		}
+		q := vectors[0]
+		if len(c.URLs) == 0 {
+			c = &eval.Case{ID: c.ID, Query: c.Query, URLs: all}
+		}
+		return consult(ctx, c, func(ctx context.Context, url string) (scored, error) {
+			page, ok := lines[canonical(url)]
+			if !ok {
+				return scored{}, errors.New("not in the corpus")
+			}
+			s := scored{url: url}
+			for _, l := range page {
+				s.passages = append(s.passages, scoredPassage{text: l.text, score: dot(q, l.vector)})
+			}
+			sort.SliceStable(s.passages, func(i, j int) bool { return s.passages[i].score > s.passages[j].score })
+			s.passages = s.passages[:min(len(s.passages), offlinePassages)]
+			var content []string
+			for _, p := range s.passages {
+				content = append(content, p.text)
+			}
+			completion, err := model.Generate(ctx, llm.ExpertPrompt(url, strings.Join(content, "\n\n"), c.Query))
+			if err != nil {
+				return scored{}, err
+			}
+			s.answer = completion.Text
+			return s, nil
+		})
+	}}, nil
+}
+
+// canonical returns the canonical form of url, or url if it is invalid.
+func canonical(url string) string {
+	if c, err := urlnorm.Canonical(url); err == nil {
+		return c
+	}
+	return url
+}
+
+// dot returns the dot product of two vectors, their cosine similarity as
+// embeddings are normalized.
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// extractiveStub is a deterministic stand-in for a model. It answers with
+// the sentence of the prompt's content sharing the most keywords with the
+// question, so answer metrics move with retrieval.
+type extractiveStub struct{}
+
+// Model implements llm.Client.
+func (extractiveStub) Model() string { return "extractive-stub" }
+
+// Generate implements llm.Client.
+func (extractiveStub) Generate(ctx context.Context, prompt string) (*llm.Completion, error) {
+	content, question := llm.ParseExpertPrompt(prompt)
+	answer, best := "", 0.0
+	for _, s := range verify.Sentences(content) {
+		if score := passage.Overlap(s, question); score > best {
+			answer, best = s, score
+		}
+	}
+	return &llm.Completion{
+		Text:             answer,
+		PromptTokens:     llm.CountTokens(prompt),
+		CompletionTokens: llm.CountTokens(answer),
+	}, nil
+}
+
+// run answers every case in order and scores it.
+func run(ctx context.Context, t *target, cases []eval.Case, k int, timeout time.Duration) []eval.CaseResult {
+	results := make([]eval.CaseResult, len(cases))
+	for i := range cases {
+		c := &cases[i]
+		ctx, cancel := context.WithTimeout(logger.WithRequestID(ctx, logger.NewRequestID()), timeout)
+		start := time.Now()
+		out, err := t.run(ctx, c)
+		latency := time.Since(start)
+		cancel()
+
+		r := eval.CaseResult{ID: c.ID, LatencyMS: float64(latency.Microseconds()) / 1000}
+		if err != nil {
+			slog.WarnContext(ctx, "Case failed", "case", c.ID, "error", err)
+			r.Error = err.Error()
+			out = nil
+		} else {
+			r.URLs, r.Answer = out.URLs[:min(len(out.URLs), k)], out.Answer
+		}
+		r.Metrics = eval.Score(c, out, k, t.answers)
+		slog.DebugContext(ctx, "Scored case", "case", c.ID, "metrics", r.Metrics, "latency", latency)
+		results[i] = r
+	}
+	return results
+}
+
+// dial connects to a service.
+func dial(name, addr string, timeout time.Duration, certs *tlsconfig.Certs) *grpc.ClientConn {
+	conn, err := grpcclient.New(addr, grpcclient.Config{Name: name, MaxAttempts: 3, Timeout: timeout},
+		grpc.WithTransportCredentials(certs.ClientCredentials()),
+		grpc.WithChainUnaryInterceptor(logger.UnaryClientInterceptor()))
+	if err != nil {
+		logger.Fatal("did not connect to "+name, "error", err)
+	}
+	return conn
+}
+
+func main() {
+	var cfg config
+	loader := conf.New("portal-eval")
+	loader.StringVar(&cfg.dataset, "dataset", "", "JSONL file of the cases to evaluate")
+	loader.StringVar(&cfg.target, "target", "orchestrator", "What answers the queries (orchestrator, expert, rag or offline)")
+	loader.IntVar(&cfg.k, "k", 5, "The number of pages and passages the metrics consider")
+	loader.StringVar(&cfg.orchSvcAddr, "orch-svc-addr", "localhost:50053", "The address of the Query Orchestrator service")
+	loader.StringVar(&cfg.expertSvcAddr, "expert-svc-addr", "localhost:50052", "The address of the Expert service")
+	loader.StringVar(&cfg.ragSvcAddr, "rag-svc-addr", "localhost:50051", "The address of the RAG service")
+	loader.StringVar(&cfg.corpus, "corpus", "", "JSONL file of the pages the offline target answers from, as {\"url\", \"content\"} objects")
+	loader.StringVar(&cfg.embedModel, "embedding-model", "hash-v1", "The embedding model of the offline target")
+	loader.IntVar(&cfg.embedDim, "embedding-dim", 768, "The dimension of -embedding-model")
+	loader.DurationVar(&cfg.timeout, "timeout", 30*time.Second, "How long each case may take")
+	loader.StringVar(&cfg.out, "out", "", "File to write the JSON report to (empty to only print the metrics)")
+	loader.StringVar(&cfg.baseline, "baseline", "", "JSON report of an earlier run to compare the metrics with")
+	loader.StringVar(&cfg.logLevel, "log-level", "warn", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "text", "The log output format (json or text)")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.Client)
+	loader.Required("dataset")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("portal-eval", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	if cfg.k < 1 {
+		logger.Fatal("invalid configuration", "error", "-k must be at least 1")
+	}
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	data, err := os.ReadFile(cfg.dataset)
+	if err != nil {
+		logger.Fatal("failed to read dataset", "error", err)
+	}
+	cases, err := eval.LoadDataset(bytes.NewReader(data))
+	if err != nil {
+		logger.Fatal("invalid dataset", "dataset", cfg.dataset, "error", err)
+	}
+	var base *eval.Report
+	if cfg.baseline != "" {
+		if base, err = eval.ReadReport(cfg.baseline); err != nil {
+			logger.Fatal("failed to read baseline", "error", err)
+		}
+	}
+
+	ctx := context.Background()
+	var t *target
+	switch cfg.target {
+	case "orchestrator":
+		conn := dial("query-orchestrator", cfg.orchSvcAddr, cfg.timeout, certs)
+		defer conn.Close()
+		t = orchestratorTarget(orchpb.NewQueryOrchestratorServiceClient(conn), cfg.k)
+	case "expert":
+		conn := dial("expert-service", cfg.expertSvcAddr, cfg.timeout, certs)
+		defer conn.Close()
+		t = expertTarget(expertpb.NewExpertServiceClient(conn))
+	case "rag":
+		conn := dial("rag-service", cfg.ragSvcAddr, cfg.timeout, certs)
+		defer conn.Close()
+		t = ragTarget(ragpb.NewRAGServiceClient(conn))
+	case "offline":
+		if cfg.corpus == "" {
+			logger.Fatal("invalid configuration", "error", "-target=offline requires -corpus")
+		}
+		pages, err := loadCorpus(cfg.corpus)
+		if err != nil {
+			logger.Fatal("failed to read corpus", "error", err)
+		}
+		embedder, err := embedding.New(cfg.embedModel, cfg.embedDim)
+		if err != nil {
+			logger.Fatal("failed to create embedder", "error", err)
+		}
+		if t, err = offlineTarget(ctx, pages, embedder, extractiveStub{}); err != nil {
+			logger.Fatal("failed to index corpus", "error", err)
+		}
+	default:
+		logger.Fatal("invalid configuration", "error", fmt.Sprintf("unknown -target %q", cfg.target))
+	}
+
+	report := &eval.Report{
+		Dataset:       cfg.dataset,
+		DatasetSHA256: eval.HashDataset(data),
+		Target:        t.name,
+		K:             cfg.k,
+		StartedAt:     time.Now().UTC(),
+	}
+	if base != nil {
+		if err := report.Comparable(base); err != nil {
+			logger.Fatal("cannot compare with the baseline", "error", err)
+		}
+	}
+	report.Cases = run(ctx, t, cases, cfg.k, cfg.timeout)
+	report.Summarize()
+
+	if err := report.WriteText(os.Stdout, base); err != nil {
+		logger.Fatal("failed to print report", "error", err)
+	}
+	if cfg.out != "" {
+		f, err := os.Create(cfg.out)
+		if err != nil {
+			logger.Fatal("failed to write report", "error", err)
+		}
+		if err := report.WriteJSON(f); err != nil {
+			logger.Fatal("failed to write report", "error", err)
+		}
+		if err := f.Close(); err != nil {
+			logger.Fatal("failed to write report", "error", err)
+		}
+	}
+}
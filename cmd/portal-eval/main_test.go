@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"portal.com/portal/internal/embedding"
+	"portal.com/portal/internal/eval"
+	"portal.com/portal/internal/llm"
+)
+
+// corpus is the offline corpus of the tests, as JSONL.
+const corpus = `{"url": "https://go-colly.org/", "content": "Colly is a scraping framework for Go.\nInstall Colly by running go get github.com/gocolly/colly/v2 in your module.\nA collector runs the callbacks attached to it."}
+
+{"url": "https://nats.io/", "content": "NATS is a messaging system.\nJetStream adds persistence to NATS streams.\nSubjects route messages between publishers and subscribers."}
+`
+
+func loadTestCorpus(t *testing.T) []page {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	if err := os.WriteFile(path, []byte(corpus), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pages, err := loadCorpus(path)
+	if err != nil {
+		t.Fatalf("loadCorpus() error = %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("loadCorpus() = %d pages, want 2", len(pages))
+	}
+	return pages
+}
+
+func TestRank(t *testing.T) {
+	out := rank([]scored{
+		{url: "a", answer: "from a", passages: []scoredPassage{{"a1", 0.2}, {"a2", 0.5}}},
+		{url: "b", answer: "from b", passages: []scoredPassage{{"b1", 0.9}}},
+		{url: "c", answer: "from c"},
+		{url: "d", answer: "from d", passages: []scoredPassage{{"d1", 0.5}}},
+	})
+	want := &eval.Output{
+		URLs:     []string{"b", "a", "d", "c"},
+		Passages: []string{"b1", "a2", "d1", "a1"},
+		Answer:   "from b",
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("rank() = %+v, want %+v", out, want)
+	}
+}
+
+func TestConsult(t *testing.T) {
+	c := &eval.Case{ID: "q", Query: "q", ExpectedURLs: []string{"good", "bad"}}
+	ask := func(ctx context.Context, url string) (scored, error) {
+		if url == "bad" {
+			return scored{}, errors.New("unavailable")
+		}
+		return scored{url: url, answer: "answer"}, nil
+	}
+	out, err := consult(context.Background(), c, ask)
+	if err != nil {
+		t.Fatalf("consult() error = %v", err)
+	}
+	if !reflect.DeepEqual(out.URLs, []string{"good"}) || out.Answer != "answer" {
+		t.Errorf("consult() = %+v, want the good expert only", out)
+	}
+
+	// The case fails only if every expert does.
+	c.ExpectedURLs = []string{"bad"}
+	if _, err := consult(context.Background(), c, ask); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("consult() error = %v, want the expert's error", err)
+	}
+	if _, err := consult(context.Background(), &eval.Case{ID: "q", Query: "q"}, ask); err == nil {
+		t.Error("consult() succeeded without experts to consult")
+	}
+}
+
+func TestExtractiveStub(t *testing.T) {
+	content := "NATS is a messaging system. JetStream adds persistence to NATS streams."
+	completion, err := extractiveStub{}.Generate(context.Background(), llm.ExpertPrompt("https://nats.io/", content, "What adds persistence?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completion.Text != "JetStream adds persistence to NATS streams." {
+		t.Errorf("Generate() = %q, want the sentence closest to the question", completion.Text)
+	}
+	if completion.PromptTokens == 0 || completion.CompletionTokens != 6 {
+		t.Errorf("Generate() used %d prompt and %d completion tokens", completion.PromptTokens, completion.CompletionTokens)
+	}
+}
+
+func TestOfflineTarget(t *testing.T) {
+	pages := loadTestCorpus(t)
+	embedder, err := embedding.New("hash-v1", 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := offlineTarget(context.Background(), pages, embedder, extractiveStub{})
+	if err != nil {
+		t.Fatalf("offlineTarget() error = %v", err)
+	}
+	cases := []eval.Case{{
+		// Without urls, the whole corpus is consulted.
+		ID:               "install",
+		Query:            "how do I install colly with go get?",
+		ExpectedURLs:     []string{"https://go-colly.org/"},
+		ExpectedPassages: []string{"go get github.com/gocolly/colly/v2"},
+		ReferenceAnswer:  "Install Colly by running go get github.com/gocolly/colly/v2 in your module.",
+	}, {
+		// Experts missing from the corpus are left out.
+		ID:              "jetstream",
+		Query:           "what adds persistence to nats streams?",
+		ExpectedURLs:    []string{"https://nats.io/"},
+		ReferenceAnswer: "JetStream adds persistence to NATS streams.",
+		URLs:            []string{"https://example.com/", "https://nats.io"},
+	}, {
+		ID:           "missing",
+		Query:        "what is example.com?",
+		ExpectedURLs: []string{"https://example.com/"},
+		URLs:         []string{"https://example.com/"},
+	}}
+	results := run(context.Background(), target, cases, 5, time.Minute)
+
+	for i, want := range []map[string]float64{
+		{eval.RecallAtK: 1, eval.MRR: 1, eval.NDCGAtK: 1, eval.PassageRecallAtK: 1, eval.PassageMRR: 1, eval.PassageNDCGAtK: 1, eval.AnswerF1: 1, eval.AnswerRougeL: 1},
+		{eval.RecallAtK: 1, eval.MRR: 1, eval.NDCGAtK: 1, eval.AnswerF1: 1, eval.AnswerRougeL: 1},
+		{eval.RecallAtK: 0, eval.MRR: 0, eval.NDCGAtK: 0},
+	} {
+		r := results[i]
+		if !reflect.DeepEqual(r.Metrics, want) {
+			t.Errorf("case %s scored %v, want %v", r.ID, r.Metrics, want)
+		}
+	}
+	if got := results[0].URLs; len(got) != 2 || got[0] != "https://go-colly.org/" {
+		t.Errorf("case install found %q, want the Colly page first of the corpus", got)
+	}
+	if results[1].Error != "" || results[2].Error == "" {
+		t.Errorf("errors = %q, %q, want only the case without experts in the corpus to fail", results[1].Error, results[2].Error)
+	}
+
+	report := &eval.Report{Cases: results}
+	report.Summarize()
+	if report.Failed != 1 || report.Metrics[eval.RecallAtK] != 2.0/3 || report.Metrics[eval.AnswerF1] != 1 {
+		t.Errorf("Summarize() = %d failed, metrics %v", report.Failed, report.Metrics)
+	}
+}
@@ -2,92 +2,747 @@ package main
 
 import (
 	"context"
-	"flag"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"maps"
 	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	expertpb "portal.com/portal/pkg/expert/v1"
 	pb "portal.com/portal/pkg/orchestrator/v1"
+
+	"portal.com/portal/internal/auth"
+	"portal.com/portal/internal/cache"
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/embedding"
+	"portal.com/portal/internal/grpcclient"
+	"portal.com/portal/internal/health"
+	"portal.com/portal/internal/hedge"
+	"portal.com/portal/internal/llm"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/passage"
+	"portal.com/portal/internal/query"
+	"portal.com/portal/internal/rpcerr"
+	"portal.com/portal/internal/shutdown"
+	"portal.com/portal/internal/telemetry"
+	"portal.com/portal/internal/tlsconfig"
 )
 
 // config holds all the configuration for the service.
 type config struct {
-	grpcPort      string
-	expertSvcAddr string
+	grpcPort        string
+	expertSvcAddr   string
+	logLevel        string
+	logFormat       string
+	traceExporter   string
+	otlpEndpoint    string
+	metricsAddr     string
+	shutdownTimeout time.Duration
+	tls             tlsconfig.Config
+	dbConn          string
+	cacheSize       int
+	cacheTTL        time.Duration
+	semanticSize    int
+	semanticMin     float64
+	embeddingModel  string
+	embeddingDim    int
+	searchBudget    time.Duration
+	hedgeQuantile   float64
+	maxAttempts     int
+	breakerFailures int
+	breakerOpenFor  time.Duration
 }
 
 // server implements the QueryOrchestratorService.
 type server struct {
 	pb.UnimplementedQueryOrchestratorServiceServer
 	expertSvcClient expertpb.ExpertServiceClient
+	analyzer        query.Analyzer
+	// budget bounds the latency of a search, 0 for no bound.
+	budget time.Duration
+	// latencies may be nil, which disables hedging. Otherwise an expert is
+	// sent a second request once the first has taken longer than the
+	// hedgeQuantile of its latencies.
+	latencies     *hedge.Latencies
+	hedgeQuantile float64
+	// cache may be nil, which disables caching search results.
+	cache    cache.Cache
+	cacheTTL time.Duration
+	// semantic may be nil, which disables serving the results of similar
+	// queries. Queries are embedded with embedder.
+	semantic          *cache.Semantic
+	semanticThreshold float64
+	embedder          embedding.Embedder
 }
 
+const (
+	// defaultMaxSources and maxSources bound the experts a search consults.
+	defaultMaxSources = 3
+	maxSources        = 10
+
+	// expertBudgetShare is the share of a search's remaining budget experts
+	// have to answer.
+	expertBudgetShare = 0.8
+
+	// The hedging delay of an expert is a quantile of its last
+	// latencyWindow latencies, once there are minLatencySamples of them,
+	// and otherwise of all experts' together. Latencies are kept for up to
+	// maxLatencyKeys experts.
+	latencyWindow     = 100
+	minLatencySamples = 20
+	maxLatencyKeys    = 10000
+)
+
 // Search implements orchestrator.v1.QueryOrchestratorServiceServer
 func (s *server) Search(ctx context.Context, in *pb.SearchRequest) (*pb.SearchResponse, error) {
-	log.Printf("Received Search request with query: %s", in.Query)
+	subject := "anonymous"
+	if id := auth.FromContext(ctx); id != nil {
+		subject = id.Subject
+	}
+	slog.InfoContext(ctx, "Received Search request", "query", in.Query, "subject", subject)
+	if strings.TrimSpace(in.Query) == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	if in.MaxSources < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "max_sources must not be negative, got %d", in.MaxSources)
+	}
+	// Every step of the search shares its latency budget.
+	if s.budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.budget)
+		defer cancel()
+	}
+
+	// The query is understood first: its intent decides which experts are
+	// consulted and how their answers are combined.
+	analysis := s.analyzer.Analyze(ctx, in.Query)
+	slog.DebugContext(ctx, "Analyzed query", "intent", analysis.Intent, "sub_queries", analysis.SubQueries, "analyzer", analysis.Analyzer)
+	// Report the LLM tokens spent by the experts, whether or not the search
+	// succeeds, so the gateway can charge them to the caller.
+	tokens := 0
+	defer func() {
+		if err := llm.SetUsageTrailer(ctx, tokens); err != nil {
+			slog.DebugContext(ctx, "Failed to report LLM usage", "error", err)
+		}
+	}()
+
+	// A navigational query is answered by the site it names alone, unless
+	// the caller asked for more sources.
+	limit := int32(defaultMaxSources)
+	switch {
+	case in.MaxSources > 0:
+		limit = min(in.MaxSources, maxSources)
+	case analysis.Intent == query.Navigational && analysis.Target != "":
+		limit = 1
+	}
 
-	// TODO: This is simplified routing. A real implementation would have a
-	// dynamic way to select which experts to query based on the user's query.
-	// For now, we'll hardcode a single expert to consult.
-	expertURL := "http://gocolly.dev/" // The same URL our crawler starts with
+	experts, nextPageToken, err := s.selectExperts(ctx, in, analysis, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Results are cached for the content versions of the experts consulted,
+	// so they are computed again once any of them changes.
+	versions := s.contentVersions(ctx, experts)
+	cq := s.cacheQuery(ctx, in, experts, versions)
+	if res := s.cachedSearch(ctx, cq, versions); res != nil {
+		res.Metadata.Analysis = analysisProto(analysis)
+		return res, nil
+	}
+
+	answers, skipped, used, err := s.queryExperts(ctx, in.Query, analysis.RetrievalQueries(in.Query), experts)
+	tokens += used
+	if err != nil {
+		return nil, err
+	}
 
-	log.Printf("Querying expert for URL: %s", expertURL)
+	// Snippets quote the page where it backs the expert's answer, falling
+	// back to the answer for experts that report no passages.
+	var sources []*pb.Source
+	for _, a := range answers {
+		snippet := passage.Snippet(a.passages, a.answer, maxSnippetLength)
+		if snippet == "" {
+			snippet = a.answer
+		}
+		sources = append(sources, &pb.Source{
+			Url:     a.url,
+			Title:   "Mock Title", // TODO: Get title from expert/metadata
+			Snippet: snippet,
+		})
+	}
 
-	expertReq := &expertpb.QueryExpertRequest{
-		Url:   expertURL,
-		Query: in.Query,
+	res := &pb.SearchResponse{
+		Summary: synthesize(analysis, answers),
+		Sources: sources,
+		Metadata: &pb.SearchMetadata{
+			CacheStatus:    pb.CacheStatus_CACHE_STATUS_MISS,
+			GeneratedAt:    timestamppb.Now(),
+			Analysis:       analysisProto(analysis),
+			SkippedExperts: skipped,
+		},
+		NextPageToken: nextPageToken,
+	}
+	// A result missing the answers of skipped experts is not cached, so the
+	// next search asks them again.
+	if len(skipped) == 0 {
+		s.storeSearch(ctx, cq, res, versions)
 	}
+	return res, nil
+}
 
-	expertRes, err := s.expertSvcClient.QueryExpert(ctx, expertReq)
+// selectExperts returns the URLs of up to limit leaf experts matching the
+// search's filters, newest first, and the token of the next page. A
+// navigational query consults the experts of the site it names, unless that
+// site has none.
+func (s *server) selectExperts(ctx context.Context, in *pb.SearchRequest, analysis *query.Analysis, limit int32) ([]string, string, error) {
+	// TODO: Experts are not yet ranked by their relevance to the query, only
+	// filtered.
+	req := &expertpb.ListExpertsRequest{
+		PageSize:      limit,
+		PageToken:     in.PageToken,
+		Level:         expertpb.ExpertLevel_EXPERT_LEVEL_LEAF,
+		ExpertType:    in.ExpertType,
+		Domain:        in.IncludeDomains,
+		ExcludeDomain: in.ExcludeDomains,
+		Language:      in.Language,
+		UpdatedAfter:  in.CrawledAfter,
+	}
+	var res *expertpb.ListExpertsResponse
+	var err error
+	if analysis.Intent == query.Navigational && analysis.Target != "" && len(in.IncludeDomains) == 0 {
+		targeted := proto.CloneOf(req)
+		targeted.Domain = []string{analysis.Target}
+		res, err = s.expertSvcClient.ListExperts(ctx, targeted)
+	}
+	if res == nil || len(res.Experts) == 0 {
+		res, err = s.expertSvcClient.ListExperts(ctx, req)
+	}
 	if err != nil {
-		log.Printf("Failed to query expert service for URL %s: %v", expertURL, err)
-		return nil, fmt.Errorf("expert query failed: %w", err)
+		return nil, "", rpcerr.Wrap(err, "expert selection failed")
+	}
+	if len(res.Experts) == 0 {
+		return nil, "", status.Error(codes.NotFound, "no experts match the search")
+	}
+	experts := make([]string, len(res.Experts))
+	for i, e := range res.Experts {
+		experts[i] = e.Url
+	}
+	return experts, res.NextPageToken, nil
+}
+
+// maxSnippetLength bounds the snippets of sources, in bytes.
+const maxSnippetLength = 300
+
+// expertAnswer is an expert's answer to a search's query.
+type expertAnswer struct {
+	url    string
+	answer string
+	// passages are the texts of the parts of the page the answer was
+	// generated from.
+	passages []string
+}
+
+// queryExperts asks every expert the query concurrently and returns the
+// answers in the order of experts, the experts skipped because they failed
+// or ran out of budget, and the LLM tokens the experts spent. It fails only
+// if every expert was skipped. RAG experts also retrieve context for
+// retrievalQueries.
+func (s *server) queryExperts(ctx context.Context, query string, retrievalQueries, experts []string) (answers []expertAnswer, skipped []*pb.SkippedExpert, tokens int, err error) {
+	// Experts get a share of what remains of the search's budget, leaving
+	// the rest to combine their answers.
+	expertCtx, cancel := ctx, context.CancelFunc(func() {})
+	if deadline, ok := ctx.Deadline(); ok {
+		share := time.Duration(float64(time.Until(deadline)) * expertBudgetShare)
+		expertCtx, cancel = context.WithTimeout(ctx, share)
+	}
+	defer cancel()
+
+	responses := make([]*expertpb.QueryExpertResponse, len(experts))
+	errs := make([]error, len(experts))
+	used := make([]int, len(experts))
+	var wg sync.WaitGroup
+	for i, url := range experts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slog.InfoContext(ctx, "Querying expert", "url", url)
+			req := &expertpb.QueryExpertRequest{
+				Url:              url,
+				Query:            query,
+				RetrievalQueries: retrievalQueries,
+			}
+			// An expert slower than usual gets a second request, and the
+			// first answer wins. The losing request is cancelled before it
+			// reports its usage, yet the expert has spent tokens on it too,
+			// so it is charged as many as the winning one.
+			var mu sync.Mutex
+			spent, finished, won := 0, 0, 0
+			call := func(ctx context.Context) (*expertpb.QueryExpertResponse, error) {
+				start := time.Now()
+				var trailer metadata.MD
+				res, err := s.expertSvcClient.QueryExpert(ctx, req, grpc.Trailer(&trailer))
+				n := llm.UsageFromTrailer(trailer)
+				mu.Lock()
+				spent += n
+				finished++
+				if err == nil && won == 0 {
+					won = n
+				}
+				mu.Unlock()
+				if err == nil && s.latencies != nil {
+					s.latencies.Observe(url, time.Since(start))
+				}
+				return res, err
+			}
+			var delay time.Duration
+			if s.latencies != nil {
+				delay, _ = s.latencies.Quantile(url, s.hedgeQuantile)
+			}
+			var out hedge.Outcome
+			responses[i], out, errs[i] = hedge.Call(expertCtx, delay, call)
+			attempts := 1
+			if out.Hedged {
+				attempts = 2
+			}
+			mu.Lock()
+			used[i] = spent + (attempts-finished)*won
+			mu.Unlock()
+			if out.Hedged {
+				winner := "primary"
+				switch {
+				case errs[i] != nil:
+					winner = "none"
+				case out.HedgeWon:
+					winner = "hedge"
+				}
+				slog.DebugContext(ctx, "Hedged expert query", "url", url, "delay", delay, "winner", winner)
+				telemetry.HedgedRequests.WithLabelValues(winner).Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Experts that failed after spending tokens are charged too.
+	for i, url := range experts {
+		tokens += used[i]
+		if errs[i] == nil {
+			a := expertAnswer{url: url, answer: responses[i].Answer}
+			for _, p := range responses[i].Passages {
+				a.passages = append(a.passages, p.Text)
+			}
+			answers = append(answers, a)
+			continue
+		}
+		reason, label := pb.SkipReason_SKIP_REASON_FAILED, "failed"
+		if status.Code(errs[i]) == codes.DeadlineExceeded {
+			reason, label = pb.SkipReason_SKIP_REASON_BUDGET_EXCEEDED, "budget_exceeded"
+		}
+		slog.WarnContext(ctx, "Skipping expert", "url", url, "reason", label, "error", errs[i])
+		telemetry.SkippedExperts.WithLabelValues(label).Inc()
+		skipped = append(skipped, &pb.SkippedExpert{Url: url, Reason: reason})
+	}
+	if len(answers) == 0 {
+		return nil, skipped, tokens, rpcerr.Wrap(errs[0], "expert query failed")
+	}
+	return answers, skipped, tokens, nil
+}
+
+// synthesize combines the experts' answers into the summary, as the query's
+// intent calls for.
+// TODO: This is simplified synthesis. A real implementation would use an LLM
+// to synthesize answers from multiple experts into a coherent summary.
+func synthesize(analysis *query.Analysis, answers []expertAnswer) string {
+	switch analysis.Intent {
+	case query.Comparative, query.Exploratory:
+		// Comparisons and overviews draw on every expert, so each distinct
+		// answer is kept, in the order of the experts.
+		var parts []string
+		for _, a := range answers {
+			if a.answer != "" && !slices.Contains(parts, a.answer) {
+				parts = append(parts, a.answer)
+			}
+		}
+		return strings.Join(parts, "\n\n")
 	}
+	// A fact or a site is answered by the first expert, which is the named
+	// site's for navigational queries.
+	return answers[0].answer
+}
 
-	// TODO: This is simplified synthesis. A real implementation would use an LLM
-	// to synthesize answers from multiple experts into a coherent summary.
-	summary := expertRes.Answer
+// queryIntents maps query intents to the API enum.
+var queryIntents = map[query.Intent]pb.QueryIntent{
+	query.Navigational: pb.QueryIntent_QUERY_INTENT_NAVIGATIONAL,
+	query.Factual:      pb.QueryIntent_QUERY_INTENT_FACTUAL,
+	query.Comparative:  pb.QueryIntent_QUERY_INTENT_COMPARATIVE,
+	query.Exploratory:  pb.QueryIntent_QUERY_INTENT_EXPLORATORY,
+}
 
-	source := &pb.Source{
-		Url:     expertURL,
-		Title:   "Mock Title", // TODO: Get title from expert/metadata
-		Snippet: expertRes.Answer,
+func analysisProto(a *query.Analysis) *pb.QueryAnalysis {
+	return &pb.QueryAnalysis{
+		Intent:     queryIntents[a.Intent],
+		SubQueries: a.SubQueries,
+		Rewrites:   a.Rewrites,
+		Target:     a.Target,
+		Analyzer:   a.Analyzer,
 	}
+}
 
-	return &pb.SearchResponse{
-		Summary: summary,
-		Sources: []*pb.Source{source},
-	}, nil
+// contentVersions returns the content versions of experts, 0 for those that
+// do not exist yet. It returns nil if caching is disabled or the versions
+// are unavailable, in which case the search runs uncached.
+func (s *server) contentVersions(ctx context.Context, experts []string) map[string]int64 {
+	if s.cache == nil && s.semantic == nil {
+		return nil
+	}
+	res, err := s.expertSvcClient.GetContentVersions(ctx, &expertpb.GetContentVersionsRequest{Urls: experts})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get content versions, not caching", "error", err)
+		return nil
+	}
+	versions := make(map[string]int64, len(experts))
+	for _, url := range experts {
+		versions[url] = res.Versions[url]
+	}
+	return versions
+}
+
+// cacheQuery is how a search is looked up in the caches.
+type cacheQuery struct {
+	// query is the normalized query.
+	query string
+	// scope identifies the search's filters and page; results are only
+	// shared between searches of the same scope.
+	scope string
+	// key is the exact cache key, or empty.
+	key string
+	// vector is the query embedding for the semantic cache, or nil.
+	vector []float32
+}
+
+// cacheQuery returns how to look up a search consulting experts at the
+// given content versions, or nil if it is not to be cached.
+func (s *server) cacheQuery(ctx context.Context, in *pb.SearchRequest, experts []string, versions map[string]int64) *cacheQuery {
+	if versions == nil {
+		return nil
+	}
+	cq := &cacheQuery{query: cache.NormalizeQuery(in.Query), scope: searchScope(in)}
+	if s.cache != nil {
+		parts := []string{"search", cq.scope, cq.query}
+		for _, url := range experts {
+			parts = append(parts, url, strconv.FormatInt(versions[url], 10))
+		}
+		cq.key = cache.Key(parts...)
+	}
+	if s.semantic != nil {
+		vectors, err := s.embedder.Embed(ctx, []string{cq.query})
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to embed query", "error", err)
+		} else {
+			cq.vector = vectors[0]
+		}
+	}
+	return cq
+}
+
+// searchScope returns the cache scope of a search: a key of its request
+// without the query.
+func searchScope(in *pb.SearchRequest) string {
+	scope := proto.CloneOf(in)
+	scope.Query = ""
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(scope)
+	return cache.Key("scope", string(b))
+}
+
+// cachedSearch returns the cached result of the same normalized query, or
+// else of the most similar query whose cited experts are unchanged, with
+// its metadata telling which.
+func (s *server) cachedSearch(ctx context.Context, cq *cacheQuery, versions map[string]int64) *pb.SearchResponse {
+	if cq == nil {
+		return nil
+	}
+	if cq.key != "" {
+		if res := cachedResponse(cache.Lookup(ctx, s.cache, "search", cq.key)); res != nil {
+			res.Metadata.CacheStatus = pb.CacheStatus_CACHE_STATUS_EXACT_HIT
+			return res
+		}
+	}
+	if cq.vector == nil {
+		return nil
+	}
+
+	// Cited experts outside this search's selection are checked with one
+	// more GetContentVersions call.
+	unchanged := func(e *cache.SemanticEntry) bool {
+		current := versions
+		var others []string
+		for url := range e.Versions {
+			if _, ok := versions[url]; !ok {
+				others = append(others, url)
+			}
+		}
+		if len(others) > 0 {
+			if current = s.contentVersions(ctx, others); current == nil {
+				return false
+			}
+		}
+		for url, v := range e.Versions {
+			if current[url] != v {
+				return false
+			}
+		}
+		return true
+	}
+	e, similarity := s.semantic.Match(cq.scope, cq.vector, s.semanticThreshold, unchanged)
+	var res *pb.SearchResponse
+	if e != nil {
+		res = cachedResponse(e.Value)
+	}
+	if res == nil {
+		telemetry.CacheRequests.WithLabelValues("semantic", "miss").Inc()
+		return nil
+	}
+	telemetry.CacheRequests.WithLabelValues("semantic", "hit").Inc()
+	slog.InfoContext(ctx, "Serving a similar query's result", "cached_query", e.Query, "similarity", similarity)
+	res.Metadata.CacheStatus = pb.CacheStatus_CACHE_STATUS_SEMANTIC_HIT
+	res.Metadata.CachedQuery = e.Query
+	res.Metadata.Similarity = float32(similarity)
+	return res
+}
+
+// cachedResponse decodes a cached SearchResponse, or returns nil.
+func cachedResponse(b []byte) *pb.SearchResponse {
+	if b == nil {
+		return nil
+	}
+	res := &pb.SearchResponse{}
+	if err := proto.Unmarshal(b, res); err != nil {
+		return nil
+	}
+	if res.Metadata == nil {
+		res.Metadata = &pb.SearchMetadata{}
+	}
+	return res
+}
+
+// storeSearch caches the result of a search, tagged with the experts it
+// cites.
+func (s *server) storeSearch(ctx context.Context, cq *cacheQuery, res *pb.SearchResponse, versions map[string]int64) {
+	if cq == nil {
+		return
+	}
+	b, err := proto.Marshal(res)
+	if err != nil {
+		return
+	}
+	cited := map[string]int64{}
+	for _, src := range res.Sources {
+		cited[src.Url] = versions[src.Url]
+	}
+	if cq.key != "" {
+		cache.Store(ctx, s.cache, "search", cq.key, b, slices.Collect(maps.Keys(cited)), s.cacheTTL)
+	}
+	if cq.vector != nil {
+		s.semantic.Add(&cache.SemanticEntry{
+			Query:    cq.query,
+			Scope:    cq.scope,
+			Vector:   cq.vector,
+			Versions: cited,
+			Value:    b,
+			Expires:  time.Now().Add(s.cacheTTL),
+		})
+	}
 }
 
 func main() {
 	var cfg config
-	flag.StringVar(&cfg.grpcPort, "grpc-port", "50053", "The gRPC port to listen on")
-	flag.StringVar(&cfg.expertSvcAddr, "expert-svc-addr", "expert-service:50052", "The address of the Expert service")
-	flag.Parse()
+	loader := conf.New("query-orchestrator")
+	loader.StringVar(&cfg.grpcPort, "grpc-port", "50053", "The gRPC port to listen on")
+	loader.StringVar(&cfg.expertSvcAddr, "expert-svc-addr", "expert-service:50052", "The address of the Expert service")
+	loader.StringVar(&cfg.logLevel, "log-level", "info", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "json", "The log output format (json or text)")
+	loader.StringVar(&cfg.traceExporter, "trace-exporter", "none", "The trace exporter to use (none, stdout or otlp)")
+	loader.StringVar(&cfg.otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP gRPC collector (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	loader.StringVar(&cfg.metricsAddr, "metrics-addr", ":9090", "The address to serve Prometheus metrics on (empty to disable)")
+	loader.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 25*time.Second, "How long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.GRPCServer)
+	loader.StringVar(&cfg.dbConn, "db-conn", "", "PostgreSQL connection string of the shared result cache (empty to cache in memory only)")
+	loader.IntVar(&cfg.cacheSize, "cache-size", 1000, "The number of search results cached in memory (0 to disable caching)")
+	loader.DurationVar(&cfg.cacheTTL, "cache-ttl", 10*time.Minute, "How long search results are cached")
+	loader.IntVar(&cfg.semanticSize, "semantic-cache-size", 0, "The number of queries kept for matching similar queries (0 to disable); needs an embedding model that captures meaning")
+	loader.Float64Var(&cfg.semanticMin, "semantic-cache-threshold", 0.9, "The cosine similarity above which a similar query's result is served")
+	loader.StringVar(&cfg.embeddingModel, "embedding-model", "hash-v1", "The embedding model queries are compared with")
+	loader.IntVar(&cfg.embeddingDim, "embedding-dim", 768, "The dimension of the embedding model")
+	loader.DurationVar(&cfg.searchBudget, "search-budget", 5*time.Second, "The latency budget of a search (0 for none); experts not answering in time are skipped")
+	loader.Float64Var(&cfg.hedgeQuantile, "hedge-quantile", 0.95, "The quantile of an expert's latency after which a hedged request is sent (0 to disable hedging)")
+	loader.IntVar(&cfg.maxAttempts, "rpc-max-attempts", 3, "Attempts of an Expert service call failing with UNAVAILABLE, up to 5 (1 to disable retries)")
+	loader.IntVar(&cfg.breakerFailures, "breaker-failures", 5, "Consecutive Expert service failures that open the circuit breaker (0 to disable it)")
+	loader.DurationVar(&cfg.breakerOpenFor, "breaker-open-for", 10*time.Second, "How long the circuit breaker fails calls before probing the Expert service again")
+	loader.Required("grpc-port", "expert-svc-addr")
+	loader.Secret("db-conn")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("query-orchestrator", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	// --- Telemetry ---
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   "query-orchestrator",
+		TraceExporter: cfg.traceExporter,
+		OTLPEndpoint:  cfg.otlpEndpoint,
+		MetricsAddr:   cfg.metricsAddr,
+		Certs:         certs,
+	})
+	if err != nil {
+		logger.Fatal("failed to set up telemetry", "error", err)
+	}
+	defer shutdown.Run("telemetry", shutdownTelemetry, cfg.shutdownTimeout)
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(certs.ClientCredentials()),
+		grpc.WithChainUnaryInterceptor(logger.UnaryClientInterceptor(), auth.UnaryClientInterceptor()),
+	}, telemetry.DialOptions()...)
 
 	// --- gRPC Client for Expert Service ---
-	conn, err := grpc.NewClient(cfg.expertSvcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpcclient.New(cfg.expertSvcAddr, grpcclient.Config{
+		Name:        "expert-service",
+		MaxAttempts: cfg.maxAttempts,
+		Timeout:     30 * time.Second,
+		Timeouts: map[string]time.Duration{
+			expertpb.ExpertService_ListExperts_FullMethodName:        5 * time.Second,
+			expertpb.ExpertService_GetContentVersions_FullMethodName: 2 * time.Second,
+		},
+		BreakerFailures: cfg.breakerFailures,
+		BreakerOpenFor:  cfg.breakerOpenFor,
+	}, dialOpts...)
 	if err != nil {
-		log.Fatalf("did not connect to Expert service: %v", err)
+		logger.Fatal("did not connect to Expert service", "error", err)
 	}
 	defer conn.Close()
 	expertSvcClient := expertpb.NewExpertServiceClient(conn)
-	log.Println("Successfully connected to Expert service")
+	slog.Info("Successfully connected to Expert service")
+
+	// --- Result Cache ---
+	var db *sql.DB
+	var resultCache cache.Cache
+	if cfg.cacheSize > 0 {
+		var shared cache.Cache
+		if cfg.dbConn != "" {
+			dsn, err := certs.PostgresDSN(cfg.dbConn)
+			if err != nil {
+				logger.Fatal("invalid configuration", "error", err)
+			}
+			db, err = sql.Open("postgres", dsn)
+			if err != nil {
+				logger.Fatal("failed to connect to database", "error", err)
+			}
+			defer db.Close()
+			if err := db.Ping(); err != nil {
+				logger.Fatal("failed to ping database", "error", err)
+			}
+			slog.Info("Successfully connected to the database")
+			shared = cache.NewPostgres(db)
+		}
+		resultCache = cache.New(cfg.cacheSize, shared)
+	}
+	var semantic *cache.Semantic
+	var embedder embedding.Embedder
+	if cfg.semanticSize > 0 {
+		if cfg.semanticMin <= 0 || cfg.semanticMin > 1 {
+			logger.Fatal("invalid configuration", "error", "-semantic-cache-threshold must be in (0, 1]")
+		}
+		if embedder, err = embedding.New(cfg.embeddingModel, cfg.embeddingDim); err != nil {
+			logger.Fatal("invalid configuration", "error", err)
+		}
+		// Hash embeddings compare words, not meaning: a query and its
+		// negation share almost every word, so one would get the other's
+		// result.
+		if strings.HasPrefix(cfg.embeddingModel, "hash-") {
+			slog.Warn("The semantic cache matches queries by their words with a hash- embedding model; negated or reworded queries may be served the wrong result", "model", cfg.embeddingModel)
+		}
+		semantic = cache.NewSemantic(cfg.semanticSize)
+	}
+
+	// --- Query Understanding ---
+	var analyzer query.Analyzer = query.Rules{}
+
+	// --- Fan-out ---
+	var latencies *hedge.Latencies
+	switch {
+	case cfg.hedgeQuantile < 0 || cfg.hedgeQuantile >= 1:
+		logger.Fatal("invalid configuration", "error", "-hedge-quantile must be in [0, 1)")
+	case cfg.hedgeQuantile > 0:
+		latencies = hedge.NewLatencies(latencyWindow, minLatencySamples, maxLatencyKeys)
+	}
 
 	// --- gRPC Server Setup for this service ---
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.grpcPort))
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		logger.Fatal("failed to listen", "error", err)
 	}
-	s := grpc.NewServer()
-	pb.RegisterQueryOrchestratorServiceServer(s, &server{expertSvcClient: expertSvcClient})
-	log.Printf("server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	serverOpts := append([]grpc.ServerOption{
+		grpc.Creds(certs.ServerCredentials()),
+		grpc.ChainUnaryInterceptor(logger.UnaryServerInterceptor(), auth.UnaryServerInterceptor()),
+	}, telemetry.ServerOptions()...)
+	s := grpc.NewServer(serverOpts...)
+	pb.RegisterQueryOrchestratorServiceServer(s, &server{
+		expertSvcClient:   expertSvcClient,
+		analyzer:          analyzer,
+		budget:            cfg.searchBudget,
+		latencies:         latencies,
+		hedgeQuantile:     cfg.hedgeQuantile,
+		cache:             resultCache,
+		cacheTTL:          cfg.cacheTTL,
+		semantic:          semantic,
+		semanticThreshold: cfg.semanticMin,
+		embedder:          embedder,
+	})
+
+	checker := health.New()
+	checker.Add("expert-service", health.GRPC(conn, expertpb.ExpertService_ServiceDesc.ServiceName))
+	if db != nil {
+		checker.Add("database", health.DB(db))
 	}
+	health.RegisterGRPC(s, checker, pb.QueryOrchestratorService_ServiceDesc.ServiceName)
+	// --- Serve until SIGINT or SIGTERM ---
+	ctx, stop := shutdown.Signals()
+	defer stop()
+	go func() {
+		slog.Info("Server listening", "addr", lis.Addr().String())
+		if err := s.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			logger.Fatal("failed to serve", "error", err)
+		}
+	}()
+	<-ctx.Done()
+	stop()
+
+	// Fail health checks first so clients move away, then let active RPCs
+	// finish. Client connections, the database pool and telemetry are closed
+	// by the deferred calls above.
+	slog.Info("Shutting down", "timeout", cfg.shutdownTimeout)
+	checker.Shutdown()
+	shutdown.GRPC(s, cfg.shutdownTimeout)
+	slog.Info("Server stopped")
 }
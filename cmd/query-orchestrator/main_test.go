@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"portal.com/portal/internal/cache"
+	"portal.com/portal/internal/embedding"
+	"portal.com/portal/internal/hedge"
+	"portal.com/portal/internal/llm"
+	"portal.com/portal/internal/query"
+	expertpb "portal.com/portal/pkg/expert/v1"
+	pb "portal.com/portal/pkg/orchestrator/v1"
+)
+
+// fakeExperts is an Expert service with a fixed set of leaf experts.
+type fakeExperts struct {
+	expertpb.ExpertServiceClient
+
+	mu sync.Mutex
+	// experts are the URLs ListExperts returns, by the first domain
+	// requested ("" for none).
+	experts       map[string][]string
+	nextPageToken string
+	// versions are the content versions of the experts.
+	versions map[string]int64
+	// answer answers the attempt-th query of an expert, counted from 1.
+	// By default experts answer with their URL.
+	answer func(ctx context.Context, url string, attempt int) (*expertpb.QueryExpertResponse, error)
+	// tokens are the LLM tokens answered queries report.
+	tokens int
+
+	lists   []*expertpb.ListExpertsRequest
+	queries map[string]int
+}
+
+func (f *fakeExperts) ListExperts(ctx context.Context, in *expertpb.ListExpertsRequest, opts ...grpc.CallOption) (*expertpb.ListExpertsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists = append(f.lists, in)
+	domain := ""
+	if len(in.Domain) > 0 {
+		domain = in.Domain[0]
+	}
+	res := &expertpb.ListExpertsResponse{NextPageToken: f.nextPageToken}
+	for _, url := range f.experts[domain] {
+		res.Experts = append(res.Experts, &expertpb.Expert{Url: url})
+	}
+	return res, nil
+}
+
+func (f *fakeExperts) GetContentVersions(ctx context.Context, in *expertpb.GetContentVersionsRequest, opts ...grpc.CallOption) (*expertpb.GetContentVersionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := &expertpb.GetContentVersionsResponse{Versions: map[string]int64{}}
+	for _, url := range in.Urls {
+		res.Versions[url] = f.versions[url]
+	}
+	return res, nil
+}
+
+func (f *fakeExperts) QueryExpert(ctx context.Context, in *expertpb.QueryExpertRequest, opts ...grpc.CallOption) (*expertpb.QueryExpertResponse, error) {
+	f.mu.Lock()
+	if f.queries == nil {
+		f.queries = map[string]int{}
+	}
+	f.queries[in.Url]++
+	attempt, answer := f.queries[in.Url], f.answer
+	f.mu.Unlock()
+	res := &expertpb.QueryExpertResponse{Answer: "answer of " + in.Url}
+	if answer != nil {
+		var err error
+		if res, err = answer(ctx, in.Url, attempt); err != nil {
+			return nil, err
+		}
+	}
+	for _, opt := range opts {
+		if t, ok := opt.(grpc.TrailerCallOption); ok && f.tokens > 0 {
+			*t.TrailerAddr = metadata.Pairs(llm.UsageTrailerKey, strconv.Itoa(f.tokens))
+		}
+	}
+	return res, nil
+}
+
+// queried returns how many times url was queried.
+func (f *fakeExperts) queried(url string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries[url]
+}
+
+func newTestServer(t *testing.T, experts *fakeExperts) *server {
+	t.Helper()
+	return &server{expertSvcClient: experts, analyzer: query.Rules{}}
+}
+
+// withCaches enables the exact and semantic result caches of s.
+func withCaches(t *testing.T, s *server) *server {
+	t.Helper()
+	e, err := embedding.New("hash-v1", 256)
+	if err != nil {
+		t.Fatalf("failed to create embedder: %v", err)
+	}
+	s.cache = cache.New(100, nil)
+	s.cacheTTL = time.Minute
+	s.semantic = cache.NewSemantic(100)
+	s.semanticThreshold = 0.9
+	s.embedder = e
+	return s
+}
+
+func search(t *testing.T, s *server, in *pb.SearchRequest) *pb.SearchResponse {
+	t.Helper()
+	res, err := s.Search(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Search(%q) error = %v", in.Query, err)
+	}
+	return res
+}
+
+func TestSearchCache(t *testing.T) {
+	experts := &fakeExperts{
+		experts:  map[string][]string{"": {"https://go-colly.org/"}},
+		versions: map[string]int64{"https://go-colly.org/": 1},
+	}
+	s := withCaches(t, newTestServer(t, experts))
+
+	res := search(t, s, &pb.SearchRequest{Query: "How do I install Colly?"})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_MISS {
+		t.Errorf("first search: cache status = %v, want a miss", res.Metadata.CacheStatus)
+	}
+
+	// The same normalized query is an exact hit.
+	res = search(t, s, &pb.SearchRequest{Query: "how do i install colly"})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_EXACT_HIT {
+		t.Errorf("same query: cache status = %v, want an exact hit", res.Metadata.CacheStatus)
+	}
+	if len(res.Sources) != 1 || res.Summary != "answer of https://go-colly.org/" {
+		t.Errorf("same query: unexpected result %v", res)
+	}
+
+	// A paraphrase with the same words is a semantic hit.
+	res = search(t, s, &pb.SearchRequest{Query: "install colly, how do i?"})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_SEMANTIC_HIT {
+		t.Fatalf("paraphrase: cache status = %v, want a semantic hit", res.Metadata.CacheStatus)
+	}
+	if res.Metadata.CachedQuery != "how do i install colly" || res.Metadata.Similarity < 0.99 {
+		t.Errorf("paraphrase: cached query %q with similarity %v", res.Metadata.CachedQuery, res.Metadata.Similarity)
+	}
+	if n := experts.queried("https://go-colly.org/"); n != 1 {
+		t.Errorf("expert queried %d times, want 1", n)
+	}
+
+	// Once the cited expert changes, neither cache serves its result.
+	experts.versions["https://go-colly.org/"] = 2
+	res = search(t, s, &pb.SearchRequest{Query: "install colly, how do i?"})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_MISS {
+		t.Errorf("paraphrase after a content change: cache status = %v, want a miss", res.Metadata.CacheStatus)
+	}
+	// Forget the paraphrase's fresh result, which the query would match.
+	s.semantic = cache.NewSemantic(100)
+	res = search(t, s, &pb.SearchRequest{Query: "how do i install colly"})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_MISS {
+		t.Errorf("same query after a content change: cache status = %v, want a miss", res.Metadata.CacheStatus)
+	}
+	if n := experts.queried("https://go-colly.org/"); n != 3 {
+		t.Errorf("expert queried %d times, want 3", n)
+	}
+}
+
+func TestSearchFailsWithoutExperts(t *testing.T) {
+	s := newTestServer(t, &fakeExperts{})
+	_, err := s.Search(context.Background(), &pb.SearchRequest{Query: "colly"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Search() error = %v, want NotFound", err)
+	}
+}
+
+func TestSelectExpertsFilters(t *testing.T) {
+	experts := &fakeExperts{
+		experts:       map[string][]string{"go.dev": {"https://go.dev/doc/"}},
+		nextPageToken: "page-3",
+	}
+	s := newTestServer(t, experts)
+	crawledAfter := timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	in := &pb.SearchRequest{
+		Query:          "what is new in go?",
+		IncludeDomains: []string{"go.dev", "golang.org"},
+		ExcludeDomains: []string{"blog.go.dev"},
+		Language:       "en",
+		CrawledAfter:   crawledAfter,
+		ExpertType:     expertpb.ExpertType_EXPERT_TYPE_RAG,
+		MaxSources:     50,
+		PageToken:      "page-2",
+	}
+	res := search(t, s, in)
+
+	want := &expertpb.ListExpertsRequest{
+		PageSize:      maxSources,
+		PageToken:     "page-2",
+		Level:         expertpb.ExpertLevel_EXPERT_LEVEL_LEAF,
+		ExpertType:    expertpb.ExpertType_EXPERT_TYPE_RAG,
+		Domain:        []string{"go.dev", "golang.org"},
+		ExcludeDomain: []string{"blog.go.dev"},
+		Language:      "en",
+		UpdatedAfter:  crawledAfter,
+	}
+	if len(experts.lists) != 1 || !proto.Equal(experts.lists[0], want) {
+		t.Errorf("ListExperts requests = %v, want %v", experts.lists, want)
+	}
+	if res.NextPageToken != "page-3" {
+		t.Errorf("next page token = %q, want the Expert service's", res.NextPageToken)
+	}
+}
+
+func TestSelectExpertsNavigational(t *testing.T) {
+	experts := &fakeExperts{experts: map[string][]string{
+		"":            {"https://go.dev/", "https://nats.io/"},
+		"gocolly.dev": {"https://gocolly.dev/"},
+	}}
+	s := newTestServer(t, experts)
+
+	// A navigational query consults the site it names, alone.
+	res := search(t, s, &pb.SearchRequest{Query: "gocolly.dev"})
+	if len(res.Sources) != 1 || res.Sources[0].Url != "https://gocolly.dev/" {
+		t.Errorf("sources = %v, want the named site", res.Sources)
+	}
+	if got := experts.lists[0]; got.PageSize != 1 || len(got.Domain) != 1 || got.Domain[0] != "gocolly.dev" {
+		t.Errorf("ListExperts request = %v, want one expert of the named site", got)
+	}
+
+	// A site without experts falls back to the unfiltered selection.
+	experts.lists = nil
+	res = search(t, s, &pb.SearchRequest{Query: "example.com", MaxSources: 2})
+	if len(experts.lists) != 2 || len(experts.lists[1].Domain) != 0 || experts.lists[1].PageSize != 2 {
+		t.Errorf("ListExperts requests = %v, want a fallback without domains", experts.lists)
+	}
+	if len(res.Sources) != 2 {
+		t.Errorf("sources = %v, want the fallback's", res.Sources)
+	}
+}
+
+func TestSearchScope(t *testing.T) {
+	base := &pb.SearchRequest{Query: "colly", IncludeDomains: []string{"go-colly.org"}}
+	if searchScope(base) != searchScope(&pb.SearchRequest{Query: "other query", IncludeDomains: []string{"go-colly.org"}}) {
+		t.Error("searches differing only by query have different scopes")
+	}
+	for _, other := range []*pb.SearchRequest{
+		{Query: "colly"},
+		{Query: "colly", IncludeDomains: []string{"go.dev"}},
+		{Query: "colly", IncludeDomains: []string{"go-colly.org"}, Language: "en"},
+		{Query: "colly", IncludeDomains: []string{"go-colly.org"}, PageToken: "page-2"},
+		{Query: "colly", IncludeDomains: []string{"go-colly.org"}, MaxSources: 5},
+	} {
+		if searchScope(base) == searchScope(other) {
+			t.Errorf("%v has the scope of %v", other, base)
+		}
+	}
+
+	// Searches with different filters do not share cached results, even
+	// when they consult the same experts.
+	experts := &fakeExperts{experts: map[string][]string{
+		"":             {"https://go-colly.org/"},
+		"go-colly.org": {"https://go-colly.org/"},
+	}}
+	s := withCaches(t, newTestServer(t, experts))
+	search(t, s, &pb.SearchRequest{Query: "how do i install colly"})
+	res := search(t, s, &pb.SearchRequest{Query: "how do i install colly", IncludeDomains: []string{"go-colly.org"}})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_MISS {
+		t.Errorf("cache status = %v, want a miss for other filters", res.Metadata.CacheStatus)
+	}
+	res = search(t, s, &pb.SearchRequest{Query: "how do i install colly", IncludeDomains: []string{"go-colly.org"}})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_EXACT_HIT {
+		t.Errorf("cache status = %v, want a hit for the same filters", res.Metadata.CacheStatus)
+	}
+}
+
+func TestQueryExpertsSkipsSlowAndFailedExperts(t *testing.T) {
+	experts := &fakeExperts{
+		experts: map[string][]string{"": {"https://fast.example/", "https://slow.example/", "https://broken.example/"}},
+	}
+	experts.answer = func(ctx context.Context, url string, attempt int) (*expertpb.QueryExpertResponse, error) {
+		switch url {
+		case "https://slow.example/":
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case "https://broken.example/":
+			return nil, status.Error(codes.Internal, "model failed")
+		}
+		return &expertpb.QueryExpertResponse{Answer: "fast answer"}, nil
+	}
+	s := withCaches(t, newTestServer(t, experts))
+	s.budget = 100 * time.Millisecond
+
+	res := search(t, s, &pb.SearchRequest{Query: "what is colly?"})
+	if len(res.Sources) != 1 || res.Sources[0].Url != "https://fast.example/" || res.Summary != "fast answer" {
+		t.Errorf("unexpected result %v", res)
+	}
+	skipped := map[string]pb.SkipReason{}
+	for _, e := range res.Metadata.SkippedExperts {
+		skipped[e.Url] = e.Reason
+	}
+	want := map[string]pb.SkipReason{
+		"https://slow.example/":   pb.SkipReason_SKIP_REASON_BUDGET_EXCEEDED,
+		"https://broken.example/": pb.SkipReason_SKIP_REASON_FAILED,
+	}
+	if !maps.Equal(skipped, want) {
+		t.Errorf("skipped experts = %v, want %v", skipped, want)
+	}
+
+	// The incomplete result is not cached: the experts are asked again.
+	res = search(t, s, &pb.SearchRequest{Query: "what is colly?"})
+	if res.Metadata.CacheStatus != pb.CacheStatus_CACHE_STATUS_MISS {
+		t.Errorf("cache status = %v, want a miss", res.Metadata.CacheStatus)
+	}
+	for _, url := range experts.experts[""] {
+		if n := experts.queried(url); n != 2 {
+			t.Errorf("%s queried %d times, want 2", url, n)
+		}
+	}
+}
+
+func TestQueryExpertsAllFail(t *testing.T) {
+	experts := &fakeExperts{experts: map[string][]string{"": {"https://a.example/", "https://b.example/"}}}
+	experts.answer = func(ctx context.Context, url string, attempt int) (*expertpb.QueryExpertResponse, error) {
+		return nil, status.Error(codes.Unavailable, "expert down")
+	}
+	s := newTestServer(t, experts)
+
+	_, skipped, _, err := s.queryExperts(context.Background(), "q", nil, experts.experts[""])
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("queryExperts() error = %v, want Unavailable", err)
+	}
+	if len(skipped) != 2 || skipped[0].Reason != pb.SkipReason_SKIP_REASON_FAILED {
+		t.Errorf("skipped experts = %v, want both failed", skipped)
+	}
+	if _, err := s.Search(context.Background(), &pb.SearchRequest{Query: "q"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Search() error = %v, want Unavailable", err)
+	}
+}
+
+func TestQueryExpertsChargesCancelledHedge(t *testing.T) {
+	const url = "https://example.com/"
+	// The first request hangs until it is cancelled, without reporting its
+	// usage; the hedged one answers.
+	experts := &fakeExperts{tokens: 100}
+	experts.answer = func(ctx context.Context, url string, attempt int) (*expertpb.QueryExpertResponse, error) {
+		if attempt == 1 {
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return &expertpb.QueryExpertResponse{Answer: "answer"}, nil
+	}
+	s := newTestServer(t, experts)
+	s.latencies = hedge.NewLatencies(10, 1, 10)
+	s.latencies.Observe(url, time.Millisecond)
+	s.hedgeQuantile = 0.5
+
+	answers, skipped, tokens, err := s.queryExperts(context.Background(), "q", nil, []string{url})
+	if err != nil || len(answers) != 1 || len(skipped) != 0 {
+		t.Fatalf("queryExperts() = %v, %v, %v", answers, skipped, err)
+	}
+	if tokens != 200 {
+		t.Errorf("tokens = %d, want 200 for both requests", tokens)
+	}
+}
@@ -3,78 +3,440 @@ package main
 import (
 	"context"
 	"database/sql"
-	"flag"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	_ "github.com/lib/pq" // Postgres driver
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"portal.com/portal/internal/auth"
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/embedding"
+	"portal.com/portal/internal/health"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/migrations"
+	"portal.com/portal/internal/passage"
+	"portal.com/portal/internal/rpcerr"
+	"portal.com/portal/internal/shutdown"
+	"portal.com/portal/internal/telemetry"
+	"portal.com/portal/internal/tlsconfig"
 	pb "portal.com/portal/pkg/rag/v1" // The generated protobuf code
 )
 
+const (
+	// chunkSize is the number of words in each chunk.
+	chunkSize = 200
+	// chunkOverlap is the number of words shared by consecutive chunks.
+	chunkOverlap = 40
+	// retrieveLimit is the maximum number of chunks returned by RetrieveContext.
+	retrieveLimit = 5
+)
+
 // config holds all the configuration for the service.
 type config struct {
-	grpcPort string
-	dbConn   string
+	grpcPort        string
+	dbConn          string
+	embeddingModel  string
+	embeddingDim    int
+	autoMigrate     bool
+	logLevel        string
+	logFormat       string
+	traceExporter   string
+	otlpEndpoint    string
+	metricsAddr     string
+	shutdownTimeout time.Duration
+	tls             tlsconfig.Config
 }
 
 // server is used to implement rag.v1.RAGServiceServer.
 type server struct {
 	pb.UnimplementedRAGServiceServer
 	db *sql.DB
+	// defaultEmbedder is used when no model is marked active in the
+	// embedding_models table.
+	defaultEmbedder embedding.Embedder
+}
+
+// embeddingSpaces returns the embedder to use for queries and the embedders
+// new content must be indexed with. Content is written to every active or
+// backfilling space so a running re-embedding job never misses new chunks.
+func (s *server) embeddingSpaces(ctx context.Context) (query embedding.Embedder, index []embedding.Embedder, err error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT model, dimension, status FROM embedding_models WHERE status IN ('active', 'backfilling') ORDER BY model`)
+	if err != nil {
+		return nil, nil, rpcerr.Wrap(err, "failed to load embedding models")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var model, status string
+		var dim int
+		if err := rows.Scan(&model, &dim, &status); err != nil {
+			return nil, nil, rpcerr.Wrap(err, "failed to scan embedding model")
+		}
+		e, err := embedding.New(model, dim)
+		if err != nil {
+			return nil, nil, rpcerr.Wrap(err, "failed to create %s embedder", model)
+		}
+		index = append(index, e)
+		if status == "active" {
+			query = e
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, rpcerr.Wrap(err, "failed to load embedding models")
+	}
+
+	if query == nil {
+		query = s.defaultEmbedder
+		index = append(index, s.defaultEmbedder)
+	}
+	return query, index, nil
 }
 
 // IndexContent implements rag.v1.RAGServiceServer
 func (s *server) IndexContent(ctx context.Context, in *pb.IndexContentRequest) (*pb.IndexContentResponse, error) {
-	log.Printf("Received IndexContent for URL: %v", in.Url)
-	// TODO: Implement text chunking.
-	// TODO: Implement vectorization (mock).
-	// TODO: Store chunks and vectors in the database.
-	log.Printf("Content length: %d", len(in.Content))
+	slog.InfoContext(ctx, "Received IndexContent", "url", in.Url)
+	if in.Url == "" {
+		return nil, status.Error(codes.InvalidArgument, "url is required")
+	}
+
+	chunks := chunkText(in.Content, chunkSize, chunkOverlap)
+	slog.DebugContext(ctx, "Chunked content", "url", in.Url, "length", len(in.Content), "chunks", len(chunks))
+
+	_, embedders, err := s.embeddingSpaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.text
+	}
+	vectors := make([][][]float32, len(embedders))
+	for i, e := range embedders {
+		if vectors[i], err = e.Embed(ctx, texts); err != nil {
+			return nil, rpcerr.Wrap(err, "failed to embed content with %s", e.Model())
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var expertID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO experts (type, name, url, is_rag_based)
+		VALUES ('LEAF', $1, $1, TRUE)
+		ON CONFLICT (url) DO UPDATE SET is_rag_based = TRUE, updated_at = NOW()
+		RETURNING id`, in.Url).Scan(&expertID)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to upsert expert")
+	}
+
+	// Re-indexing replaces all chunks; their embeddings cascade.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_chunks WHERE expert_id = $1`, expertID); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to delete old chunks")
+	}
+
+	for i, chunk := range chunks {
+		var chunkID string
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO document_chunks (expert_id, chunk_text, chunk_index, start_offset, end_offset, heading)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')) RETURNING id`,
+			expertID, chunk.text, i, chunk.start, chunk.end, chunk.heading).Scan(&chunkID)
+		if err != nil {
+			return nil, rpcerr.Wrap(err, "failed to insert chunk %d", i)
+		}
+		for j, e := range embedders {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO chunk_embeddings (chunk_id, model, dimension, embedding) VALUES ($1, $2, $3, $4::vector)`,
+				chunkID, e.Model(), e.Dimension(), embedding.VectorLiteral(vectors[j][i]))
+			if err != nil {
+				return nil, rpcerr.Wrap(err, "failed to insert %s embedding for chunk %d", e.Model(), i)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to commit chunks")
+	}
+	for _, e := range embedders {
+		telemetry.ChunksIndexed.WithLabelValues(e.Model()).Add(float64(len(chunks)))
+	}
 	return &pb.IndexContentResponse{}, nil
 }
 
 // RetrieveContext implements rag.v1.RAGServiceServer
 func (s *server) RetrieveContext(ctx context.Context, in *pb.RetrieveContextRequest) (*pb.RetrieveContextResponse, error) {
-	log.Printf("Received RetrieveContext for URL: %v with query: %s", in.Url, in.Query)
-	// TODO: Implement similarity search in the database.
-	// For now, return mock data.
-	mockChunks := []string{
-		"This is a mock chunk for the query.",
-		"This is another relevant mock chunk.",
-	}
-	return &pb.RetrieveContextResponse{ContextChunks: mockChunks}, nil
+	slog.InfoContext(ctx, "Received RetrieveContext", "url", in.Url, "query", in.Query)
+	if in.Url == "" || in.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "url and query are required")
+	}
+
+	embedder, _, err := s.embeddingSpaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := embedder.Embed(ctx, []string{in.Query})
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to embed query with %s", embedder.Model())
+	}
+
+	// Only vectors from the query's embedding space are comparable, so the
+	// search is restricted to the same model and dimension. The cast to a
+	// fixed dimension lets Postgres use the model's partial vector index,
+	// created by migration 0010 for hash-v1 and by the re-embedding job for
+	// the models it backfills.
+	query := fmt.Sprintf(`
+		SELECT c.chunk_text, c.chunk_index, COALESCE(c.start_offset, 0), COALESCE(c.end_offset, 0),
+			COALESCE(c.heading, ''), 1 - (e.embedding::vector(%[1]d) <=> $4::vector(%[1]d))
+		FROM document_chunks c
+		JOIN experts x ON x.id = c.expert_id
+		JOIN chunk_embeddings e ON e.chunk_id = c.id
+		WHERE x.url = $1 AND e.model = $2 AND e.dimension = $3
+		ORDER BY e.embedding::vector(%[1]d) <=> $4::vector(%[1]d)
+		LIMIT $5`, embedder.Dimension())
+	rows, err := s.db.QueryContext(ctx, query,
+		in.Url, embedder.Model(), embedder.Dimension(), embedding.VectorLiteral(vectors[0]), retrieveLimit)
+	if err != nil {
+		return nil, rpcerr.Wrap(err, "failed to search chunks")
+	}
+	defer rows.Close()
+
+	res := &pb.RetrieveContextResponse{}
+	for rows.Next() {
+		c := &pb.Chunk{}
+		if err := rows.Scan(&c.Text, &c.Index, &c.StartOffset, &c.EndOffset, &c.Heading, &c.Score); err != nil {
+			return nil, rpcerr.Wrap(err, "failed to scan chunk")
+		}
+		res.ContextChunks = append(res.ContextChunks, c.Text)
+		res.Chunks = append(res.Chunks, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, rpcerr.Wrap(err, "failed to search chunks")
+	}
+
+	result := "hit"
+	if len(res.Chunks) == 0 {
+		result = "miss"
+	}
+	telemetry.RetrievalRequests.WithLabelValues(result).Inc()
+	telemetry.RetrievedChunks.Observe(float64(len(res.Chunks)))
+	return res, nil
+}
+
+// chunk is a part of a page's content.
+type chunk struct {
+	text string
+	// start and end are the offsets of the chunk in the content, in runes.
+	start, end int
+	// heading is the heading of the section the chunk starts in, if any.
+	heading string
+}
+
+// word is a word of a page's content, with its offsets in runes and the
+// heading of its section.
+type word struct {
+	text       string
+	start, end int
+	heading    string
+}
+
+// words returns the words of content, under the heading of their section.
+func words(content string) []word {
+	lines := strings.Split(content, "\n")
+	var ws []word
+	heading := ""
+	offset := 0
+	for i, line := range lines {
+		if h, ok := passage.Heading(line, lines[i+1:]); ok {
+			heading = h
+		}
+		pos, start := offset, -1
+		var b strings.Builder
+		for _, r := range line + " " {
+			if unicode.IsSpace(r) {
+				if start >= 0 {
+					ws = append(ws, word{text: b.String(), start: start, end: pos, heading: heading})
+					b.Reset()
+					start = -1
+				}
+			} else {
+				if start < 0 {
+					start = pos
+				}
+				b.WriteRune(r)
+			}
+			pos++
+		}
+		offset += utf8.RuneCountInString(line) + 1
+	}
+	return ws
+}
+
+// chunkText splits content into chunks of at most size words, with overlap
+// words repeated between consecutive chunks to preserve context at the edges.
+func chunkText(content string, size, overlap int) []chunk {
+	ws := words(content)
+	if len(ws) == 0 {
+		return nil
+	}
+	var chunks []chunk
+	step := size - overlap
+	for start := 0; ; start += step {
+		end := min(start+size, len(ws))
+		texts := make([]string, 0, end-start)
+		for _, w := range ws[start:end] {
+			texts = append(texts, w.text)
+		}
+		chunks = append(chunks, chunk{
+			text:    strings.Join(texts, " "),
+			start:   ws[start].start,
+			end:     ws[end-1].end,
+			heading: ws[start].heading,
+		})
+		if end == len(ws) {
+			return chunks
+		}
+	}
 }
 
 func main() {
 	var cfg config
-	flag.StringVar(&cfg.grpcPort, "grpc-port", "50051", "The gRPC port to listen on")
-	flag.StringVar(&cfg.dbConn, "db-conn", "host=postgres user=postgres password=postgres dbname=portal sslmode=disable", "PostgreSQL connection string")
-	flag.Parse()
+	loader := conf.New("rag-service")
+	loader.StringVar(&cfg.grpcPort, "grpc-port", "50051", "The gRPC port to listen on")
+	loader.StringVar(&cfg.dbConn, "db-conn", "host=postgres user=postgres password=postgres dbname=portal sslmode=disable", "PostgreSQL connection string")
+	loader.StringVar(&cfg.embeddingModel, "embedding-model", "hash-v1", "The embedding model to use when none is marked active in the database")
+	loader.IntVar(&cfg.embeddingDim, "embedding-dim", 768, "The dimension of the default embedding model")
+	loader.BoolVar(&cfg.autoMigrate, "auto-migrate", false, "Apply pending database migrations before serving")
+	loader.StringVar(&cfg.logLevel, "log-level", "info", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "json", "The log output format (json or text)")
+	loader.StringVar(&cfg.traceExporter, "trace-exporter", "none", "The trace exporter to use (none, stdout or otlp)")
+	loader.StringVar(&cfg.otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP gRPC collector (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	loader.StringVar(&cfg.metricsAddr, "metrics-addr", ":9090", "The address to serve Prometheus metrics on (empty to disable)")
+	loader.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 25*time.Second, "How long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.GRPCServer)
+	loader.Required("grpc-port", "db-conn")
+	loader.Secret("db-conn")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("rag-service", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	// --- Telemetry ---
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   "rag-service",
+		TraceExporter: cfg.traceExporter,
+		OTLPEndpoint:  cfg.otlpEndpoint,
+		MetricsAddr:   cfg.metricsAddr,
+		Certs:         certs,
+	})
+	if err != nil {
+		logger.Fatal("failed to set up telemetry", "error", err)
+	}
+	defer shutdown.Run("telemetry", shutdownTelemetry, cfg.shutdownTimeout)
+
+	defaultEmbedder, err := embedding.New(cfg.embeddingModel, cfg.embeddingDim)
+	if err != nil {
+		logger.Fatal("failed to create embedder", "error", err)
+	}
 
 	// --- Database Connection ---
-	db, err := sql.Open("postgres", cfg.dbConn)
+	dsn, err := certs.PostgresDSN(cfg.dbConn)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		logger.Fatal("failed to connect to database", "error", err)
 	}
 	defer db.Close()
 
 	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping database: %v", err)
+		logger.Fatal("failed to ping database", "error", err)
+	}
+	slog.Info("Successfully connected to the database")
+
+	// --- Database Migrations ---
+	// `rag-service [flags] migrate [up|down [N]|version]` manages the schema and exits.
+	if len(loader.Args()) > 0 && loader.Args()[0] == "migrate" {
+		if err := migrations.Command(context.Background(), db, loader.Args()[1:]); err != nil {
+			logger.Fatal("migration failed", "error", err)
+		}
+		return
+	}
+	if cfg.autoMigrate {
+		m, err := migrations.New(db)
+		if err != nil {
+			logger.Fatal("failed to load migrations", "error", err)
+		}
+		if _, err := m.Up(context.Background()); err != nil {
+			logger.Fatal("failed to migrate database", "error", err)
+		}
+	}
+
+	// Register the default model as active on a fresh database. Once a model
+	// is active, switching is left to the re-embedding job.
+	_, err = db.Exec(`
+		INSERT INTO embedding_models (model, dimension, status)
+		SELECT $1, $2, 'active'
+		WHERE NOT EXISTS (SELECT 1 FROM embedding_models WHERE status = 'active')
+		ON CONFLICT (model) DO NOTHING`, cfg.embeddingModel, cfg.embeddingDim)
+	if err != nil {
+		logger.Fatal("failed to register embedding model", "error", err)
 	}
-	log.Println("Successfully connected to the database")
 
 	// --- gRPC Server Setup ---
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.grpcPort))
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
-	}
-	s := grpc.NewServer()
-	pb.RegisterRAGServiceServer(s, &server{db: db})
-	log.Printf("server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+		logger.Fatal("failed to listen", "error", err)
 	}
+	serverOpts := append([]grpc.ServerOption{
+		grpc.Creds(certs.ServerCredentials()),
+		grpc.ChainUnaryInterceptor(logger.UnaryServerInterceptor(), auth.UnaryServerInterceptor()),
+	}, telemetry.ServerOptions()...)
+	s := grpc.NewServer(serverOpts...)
+	pb.RegisterRAGServiceServer(s, &server{db: db, defaultEmbedder: defaultEmbedder})
+
+	checker := health.New()
+	checker.Add("database", health.DB(db))
+	health.RegisterGRPC(s, checker, pb.RAGService_ServiceDesc.ServiceName)
+	// --- Serve until SIGINT or SIGTERM ---
+	ctx, stop := shutdown.Signals()
+	defer stop()
+	go func() {
+		slog.Info("Server listening", "addr", lis.Addr().String())
+		if err := s.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			logger.Fatal("failed to serve", "error", err)
+		}
+	}()
+	<-ctx.Done()
+	stop()
+
+	// Fail health checks first so clients move away, then let active RPCs
+	// finish. Client connections, the database pool and telemetry are closed
+	// by the deferred calls above.
+	slog.Info("Shutting down", "timeout", cfg.shutdownTimeout)
+	checker.Shutdown()
+	shutdown.GRPC(s, cfg.shutdownTimeout)
+	slog.Info("Server stopped")
 }
@@ -2,43 +2,86 @@ package main
 
 import (
 	"context"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"portal.com/portal/internal/embedding"
 	pb "portal.com/portal/pkg/rag/v1"
 )
 
-func TestIndexContent(t *testing.T) {
-	db, _, err := sqlmock.New()
+func newTestServer(t *testing.T) (*server, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
-	defer db.Close()
+	t.Cleanup(func() { db.Close() })
+
+	e, err := embedding.New("hash-v1", 8)
+	if err != nil {
+		t.Fatalf("failed to create embedder: %v", err)
+	}
+	return &server{db: db, defaultEmbedder: e}, mock
+}
+
+func TestIndexContent(t *testing.T) {
+	s, mock := newTestServer(t)
 
-	s := &server{db: db}
+	// hash-v1 is active and hash-v2 is being backfilled, so every chunk must be
+	// embedded in both spaces.
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT model, dimension, status FROM embedding_models`)).
+		WillReturnRows(sqlmock.NewRows([]string{"model", "dimension", "status"}).
+			AddRow("hash-v1", 8, "active").
+			AddRow("hash-v2", 4, "backfilling"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO experts`)).
+		WithArgs("https://example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("expert-1"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM document_chunks WHERE expert_id = $1`)).
+		WithArgs("expert-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO document_chunks`)).
+		WithArgs("expert-1", "This is some test content.", 0, 0, 26, "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("chunk-1"))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO chunk_embeddings`)).
+		WithArgs("chunk-1", "hash-v1", 8, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO chunk_embeddings`)).
+		WithArgs("chunk-1", "hash-v2", 4, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	req := &pb.IndexContentRequest{
 		Url:     "https://example.com",
 		Content: "This is some test content.",
 	}
 
-	_, err = s.IndexContent(context.Background(), req)
+	_, err := s.IndexContent(context.Background(), req)
 	if err != nil {
 		t.Errorf("IndexContent() error = %v, wantErr %v", err, false)
 	}
-
-	// This test is basic for now. When database logic is added to the IndexContent
-	// method, we will add mock.ExpectExec(...) here to verify the SQL queries.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
 }
 
 func TestRetrieveContext(t *testing.T) {
-	db, _, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	s, mock := newTestServer(t)
 
-	s := &server{db: db}
+	// With no active model registered, the default embedder is used and the
+	// search must be restricted to its space.
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT model, dimension, status FROM embedding_models`)).
+		WillReturnRows(sqlmock.NewRows([]string{"model", "dimension", "status"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT c.chunk_text`)).
+		WithArgs("https://example.com", "hash-v1", 8, sqlmock.AnyArg(), retrieveLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"chunk_text", "chunk_index", "start_offset", "end_offset", "heading", "score"}).
+			AddRow("This is a relevant chunk.", 3, 120, 145, "Usage", 0.82).
+			AddRow("This is another relevant chunk.", 0, 0, 0, "", 0.61))
 
 	req := &pb.RetrieveContextRequest{
 		Url:   "https://example.com",
@@ -47,13 +90,73 @@ func TestRetrieveContext(t *testing.T) {
 
 	res, err := s.RetrieveContext(context.Background(), req)
 	if err != nil {
-		t.Errorf("RetrieveContext() error = %v, wantErr %v", err, false)
+		t.Fatalf("RetrieveContext() error = %v, wantErr %v", err, false)
+	}
+
+	if len(res.ContextChunks) != 2 || len(res.Chunks) != 2 {
+		t.Errorf("expected 2 chunks, got %d and %d", len(res.ContextChunks), len(res.Chunks))
+	}
+	if c := res.GetChunks()[0]; c.Index != 3 || c.StartOffset != 120 || c.EndOffset != 145 || c.Heading != "Usage" || c.Score != 0.82 {
+		t.Errorf("first chunk = %v", c)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
+}
+
+func TestRetrieveContextStatusCodes(t *testing.T) {
+	s, mock := newTestServer(t)
+
+	_, err := s.RetrieveContext(context.Background(), &pb.RetrieveContextRequest{Url: "https://example.com"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a missing query, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT model, dimension, status FROM embedding_models`)).
+		WillReturnError(context.DeadlineExceeded)
+	_, err = s.RetrieveContext(context.Background(), &pb.RetrieveContextRequest{Url: "https://example.com", Query: "q"})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	content := strings.Repeat("word ", 10)
 
-	if len(res.ContextChunks) != 2 {
-		t.Errorf("expected 2 mock chunks, got %d", len(res.ContextChunks))
+	chunks := chunkText(content, 4, 1)
+	// Windows start at 0, 3, 6 and the last one reaches the end at 10.
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %v", len(chunks), chunks)
 	}
+	if got := len(strings.Fields(chunks[2].text)); got != 4 {
+		t.Errorf("expected last chunk to have 4 words, got %d", got)
+	}
+	if c := chunks[1]; c.start != 15 || c.end != 34 {
+		t.Errorf("expected second chunk at [15, 34), got [%d, %d)", c.start, c.end)
+	}
+	if chunkText("   ", 4, 1) != nil {
+		t.Error("expected no chunks for blank content")
+	}
+}
 
-	// This test checks the current mock implementation. When database logic is added,
-	// we will use mock.ExpectQuery(...) and mock.NewRows(...) to test the real logic.
+func TestChunkTextHeadings(t *testing.T) {
+	content := "Colly\nélégant scraping for Go.\n\n## Install it\nRun go get to add the module.\nExtensions\nThey add features. Short line\nThe end."
+
+	chunks := chunkText(content, 5, 0)
+	want := []chunk{
+		{text: "Colly élégant scraping for Go.", start: 0, end: 30, heading: "Colly"},
+		{text: "## Install it Run go", start: 32, end: 52, heading: "Install it"},
+		{text: "get to add the module.", start: 53, end: 75, heading: "Install it"},
+		{text: "Extensions They add features. Short", start: 76, end: 111, heading: "Extensions"},
+		{text: "line The end.", start: 112, end: 125, heading: "Extensions"},
+	}
+	if !slices.Equal(chunks, want) {
+		t.Errorf("chunkText() =\n%v\nwant\n%v", chunks, want)
+	}
+	runes := []rune(content)
+	for _, c := range chunks {
+		if got := strings.Join(strings.Fields(string(runes[c.start:c.end])), " "); got != c.text {
+			t.Errorf("content[%d:%d] = %q, want %q", c.start, c.end, got, c.text)
+		}
+	}
 }
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	conf "portal.com/portal/internal/config"
+	"portal.com/portal/internal/embedding"
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/shutdown"
+	"portal.com/portal/internal/telemetry"
+	"portal.com/portal/internal/tlsconfig"
+)
+
+// config holds all the configuration for the job.
+type config struct {
+	dbConn          string
+	model           string
+	dim             int
+	batchSize       int
+	pause           time.Duration
+	activate        bool
+	logLevel        string
+	logFormat       string
+	traceExporter   string
+	otlpEndpoint    string
+	metricsAddr     string
+	shutdownTimeout time.Duration
+	tls             tlsconfig.Config
+}
+
+// chunk is a document chunk that still needs an embedding in the target space.
+type chunk struct {
+	id   string
+	text string
+}
+
+// registerModel records the target model as "backfilling" (a retired model is
+// brought back, an active one is left alone). From then on the RAG service
+// writes new chunks in both the active and the target space, so the backfill
+// only has to catch up with existing chunks.
+func registerModel(ctx context.Context, db *sql.DB, e embedding.Embedder) error {
+	var status string
+	var dim int
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO embedding_models (model, dimension, status)
+		VALUES ($1, $2, 'backfilling')
+		ON CONFLICT (model) DO UPDATE SET status =
+			CASE WHEN embedding_models.status = 'active' THEN 'active' ELSE 'backfilling' END
+		RETURNING dimension, status`, e.Model(), e.Dimension()).Scan(&dim, &status)
+	if err != nil {
+		return fmt.Errorf("failed to insert model: %w", err)
+	}
+	if dim != e.Dimension() {
+		return fmt.Errorf("model %s is registered with dimension %d, not %d", e.Model(), dim, e.Dimension())
+	}
+	slog.InfoContext(ctx, "Registered embedding model", "model", e.Model(), "dimension", dim, "status", status)
+	return nil
+}
+
+// nextBatch returns up to limit chunks that have no embedding for the model.
+func nextBatch(ctx context.Context, db *sql.DB, model string, limit int) ([]chunk, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.id, c.chunk_text
+		FROM document_chunks c
+		WHERE NOT EXISTS (
+			SELECT 1 FROM chunk_embeddings e WHERE e.chunk_id = c.id AND e.model = $1
+		)
+		ORDER BY c.id
+		LIMIT $2`, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []chunk
+	for rows.Next() {
+		var c chunk
+		if err := rows.Scan(&c.id, &c.text); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		batch = append(batch, c)
+	}
+	return batch, rows.Err()
+}
+
+// embedBatch embeds a batch of chunks and stores the vectors in one
+// transaction. Chunks deleted or embedded concurrently are skipped.
+func embedBatch(ctx context.Context, db *sql.DB, e embedding.Embedder, batch []chunk) error {
+	texts := make([]string, len(batch))
+	for i, c := range batch {
+		texts[i] = c.text
+	}
+	vectors, err := e.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed batch: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, c := range batch {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO chunk_embeddings (chunk_id, model, dimension, embedding)
+			SELECT id, $2, $3, $4::vector FROM document_chunks WHERE id = $1
+			ON CONFLICT (chunk_id, model) DO NOTHING`,
+			c.id, e.Model(), e.Dimension(), embedding.VectorLiteral(vectors[i]))
+		if err != nil {
+			return fmt.Errorf("failed to insert embedding for chunk %s: %w", c.id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// maxIndexedDimension is the most dimensions pgvector's HNSW indexes accept.
+const maxIndexedDimension = 2000
+
+// vectorIndex returns the name of the model's partial vector index.
+func vectorIndex(model string) string {
+	return "idx_chunk_embeddings_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, model)
+}
+
+// createIndex builds the partial HNSW index of the model's embedding space,
+// which the RAG service's queries use once the model is active. It is built
+// concurrently, so indexing is not blocked meanwhile, and left alone if it
+// already exists.
+func createIndex(ctx context.Context, db *sql.DB, e embedding.Embedder) error {
+	if e.Dimension() > maxIndexedDimension {
+		slog.WarnContext(ctx, "The embedding space is too large for a vector index, queries will scan it", "model", e.Model(), "dimension", e.Dimension())
+		return nil
+	}
+	name := vectorIndex(e.Model())
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON chunk_embeddings
+			USING hnsw ((embedding::vector(%d)) vector_cosine_ops)
+			WHERE model = %s AND dimension = %d`,
+		pq.QuoteIdentifier(name), e.Dimension(), pq.QuoteLiteral(e.Model()), e.Dimension()))
+	if err != nil {
+		return fmt.Errorf("failed to create index %s: %w", name, err)
+	}
+	slog.InfoContext(ctx, "Created vector index", "model", e.Model(), "index", name)
+	return nil
+}
+
+// activate makes the model the one used for queries and retires the
+// previously active model. Retired vectors are kept so a rollback is a
+// metadata-only change. It fails if a chunk has no embedding for the model,
+// so queries never switch to a space missing part of the corpus.
+func activate(ctx context.Context, db *sql.DB, model string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var missing int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM document_chunks c
+		WHERE NOT EXISTS (
+			SELECT 1 FROM chunk_embeddings e WHERE e.chunk_id = c.id AND e.model = $1
+		)`, model).Scan(&missing)
+	if err != nil {
+		return fmt.Errorf("failed to count chunks to embed: %w", err)
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d chunks have no embedding for model %s", missing, model)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE embedding_models SET status = 'retired' WHERE status = 'active' AND model <> $1`, model); err != nil {
+		return fmt.Errorf("failed to retire active model: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE embedding_models SET status = 'active' WHERE model = $1`, model); err != nil {
+		return fmt.Errorf("failed to activate model: %w", err)
+	}
+	return tx.Commit()
+}
+
+// backfill embeds the chunks missing from the model's space in batches of
+// batchSize, pausing between batches. It returns the number of chunks
+// embedded, and whether none is left: a cancelled stop ends the backfill
+// between batches, and since every batch is committed on its own, running
+// the job again resumes where it stopped.
+func backfill(ctx, stop context.Context, db *sql.DB, e embedding.Embedder, batchSize int, pause time.Duration) (int, bool, error) {
+	total := 0
+	for {
+		batch, err := nextBatch(ctx, db, e.Model(), batchSize)
+		if err != nil {
+			return total, false, fmt.Errorf("failed to load batch: %w", err)
+		}
+		if len(batch) == 0 {
+			return total, true, nil
+		}
+		if err := embedBatch(ctx, db, e, batch); err != nil {
+			return total, false, err
+		}
+		total += len(batch)
+		telemetry.ChunksIndexed.WithLabelValues(e.Model()).Add(float64(len(batch)))
+		slog.Info("Embedded batch", "model", e.Model(), "chunks", len(batch), "total", total)
+
+		if stop.Err() != nil {
+			return total, false, nil
+		}
+		select {
+		case <-stop.Done():
+			return total, false, nil
+		case <-time.After(pause):
+		}
+	}
+}
+
+func main() {
+	var cfg config
+	loader := conf.New("reembedding-job")
+	loader.StringVar(&cfg.dbConn, "db-conn", "host=postgres user=postgres password=postgres dbname=portal sslmode=disable", "PostgreSQL connection string")
+	loader.StringVar(&cfg.model, "model", "", "The embedding model to migrate chunks to")
+	loader.IntVar(&cfg.dim, "dim", 768, "The dimension of the target embedding model")
+	loader.IntVar(&cfg.batchSize, "batch-size", 256, "The number of chunks to embed per batch")
+	loader.DurationVar(&cfg.pause, "pause", 100*time.Millisecond, "The time to wait between batches to limit database load")
+	loader.BoolVar(&cfg.activate, "activate", false, "Make the target model active for queries once every chunk is embedded")
+	loader.StringVar(&cfg.logLevel, "log-level", "info", "The minimum log level (debug, info, warn or error)")
+	loader.StringVar(&cfg.logFormat, "log-format", "json", "The log output format (json or text)")
+	loader.StringVar(&cfg.traceExporter, "trace-exporter", "none", "The trace exporter to use (none, stdout or otlp)")
+	loader.StringVar(&cfg.otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP gRPC collector (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	loader.StringVar(&cfg.metricsAddr, "metrics-addr", ":9090", "The address to serve Prometheus metrics on (empty to disable)")
+	loader.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 25*time.Second, "How long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	tlsconfig.RegisterFlags(loader, &cfg.tls, tlsconfig.Client)
+	loader.Required("db-conn", "model")
+	loader.Secret("db-conn")
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if _, err := logger.New("reembedding-job", cfg.logLevel, cfg.logFormat); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	slog.Info("Loaded configuration", "config", loader)
+	certs, err := tlsconfig.Load(cfg.tls)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+
+	// --- Telemetry ---
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   "reembedding-job",
+		TraceExporter: cfg.traceExporter,
+		OTLPEndpoint:  cfg.otlpEndpoint,
+		MetricsAddr:   cfg.metricsAddr,
+		Certs:         certs,
+	})
+	if err != nil {
+		logger.Fatal("failed to set up telemetry", "error", err)
+	}
+	defer shutdown.Run("telemetry", shutdownTelemetry, cfg.shutdownTimeout)
+
+	embedder, err := embedding.New(cfg.model, cfg.dim)
+	if err != nil {
+		logger.Fatal("failed to create embedder", "error", err)
+	}
+
+	// --- Database Connection ---
+	dsn, err := certs.PostgresDSN(cfg.dbConn)
+	if err != nil {
+		logger.Fatal("invalid configuration", "error", err)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		logger.Fatal("failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		logger.Fatal("failed to ping database", "error", err)
+	}
+	slog.Info("Successfully connected to the database")
+
+	sigCtx, stop := shutdown.Signals()
+	defer stop()
+
+	ctx := context.Background()
+	if err := registerModel(ctx, db, embedder); err != nil {
+		logger.Fatal("failed to register model", "error", err)
+	}
+
+	// --- Backfill Loop ---
+	total, complete, err := backfill(ctx, sigCtx, db, embedder, cfg.batchSize, cfg.pause)
+	if err != nil {
+		logger.Fatal("failed to backfill embeddings", "error", err)
+	}
+	if !complete {
+		slog.Info("Backfill interrupted, run the job again to resume", "model", cfg.model, "total", total)
+		return
+	}
+	slog.Info("Backfill complete", "model", cfg.model, "total", total)
+	if err := createIndex(ctx, db, embedder); err != nil {
+		logger.Fatal("failed to index embeddings", "error", err)
+	}
+
+	if cfg.activate {
+		if err := activate(ctx, db, cfg.model); err != nil {
+			logger.Fatal("failed to activate model", "error", err)
+		}
+		slog.Info("Model is now active for queries", "model", cfg.model)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"portal.com/portal/internal/embedding"
+)
+
+func newTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, embedding.Embedder) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	e, err := embedding.New("hash-v2", 4)
+	if err != nil {
+		t.Fatalf("failed to create embedder: %v", err)
+	}
+	return db, mock, e
+}
+
+// expectBatch expects a batch of chunks to be loaded and embedded.
+func expectBatch(mock sqlmock.Sqlmock, limit int, ids ...string) {
+	rows := sqlmock.NewRows([]string{"id", "chunk_text"})
+	for _, id := range ids {
+		rows.AddRow(id, "text of "+id)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT c.id, c.chunk_text`)).
+		WithArgs("hash-v2", limit).
+		WillReturnRows(rows)
+	if len(ids) == 0 {
+		return
+	}
+	mock.ExpectBegin()
+	for _, id := range ids {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO chunk_embeddings`)).
+			WithArgs(id, "hash-v2", 4, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func TestRegisterModel(t *testing.T) {
+	db, mock, e := newTestDB(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO embedding_models`)).
+		WithArgs("hash-v2", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"dimension", "status"}).AddRow(4, "backfilling"))
+	if err := registerModel(context.Background(), db, e); err != nil {
+		t.Errorf("registerModel() error = %v", err)
+	}
+
+	// A model registered with another dimension cannot be backfilled.
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO embedding_models`)).
+		WithArgs("hash-v2", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"dimension", "status"}).AddRow(8, "retired"))
+	if err := registerModel(context.Background(), db, e); err == nil {
+		t.Error("registerModel() succeeded for a dimension mismatch")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBackfillBatches(t *testing.T) {
+	db, mock, e := newTestDB(t)
+
+	expectBatch(mock, 2, "chunk-1", "chunk-2")
+	expectBatch(mock, 2, "chunk-3")
+	expectBatch(mock, 2)
+
+	ctx := context.Background()
+	total, complete, err := backfill(ctx, ctx, db, e, 2, 0)
+	if err != nil {
+		t.Fatalf("backfill() error = %v", err)
+	}
+	if total != 3 || !complete {
+		t.Errorf("backfill() = %d, %t, want 3, true", total, complete)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBackfillResumes(t *testing.T) {
+	db, mock, e := newTestDB(t)
+
+	// A stop ends the backfill after the batch in progress is committed.
+	stopped, stop := context.WithCancel(context.Background())
+	stop()
+	expectBatch(mock, 2, "chunk-1", "chunk-2")
+	total, complete, err := backfill(context.Background(), stopped, db, e, 2, 0)
+	if err != nil {
+		t.Fatalf("backfill() error = %v", err)
+	}
+	if total != 2 || complete {
+		t.Errorf("backfill() = %d, %t, want 2, false", total, complete)
+	}
+
+	// The next run only loads the chunks still missing an embedding.
+	expectBatch(mock, 2, "chunk-3")
+	expectBatch(mock, 2)
+	ctx := context.Background()
+	total, complete, err = backfill(ctx, ctx, db, e, 2, 0)
+	if err != nil {
+		t.Fatalf("backfill() error = %v", err)
+	}
+	if total != 1 || !complete {
+		t.Errorf("backfill() = %d, %t, want 1, true", total, complete)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestActivate(t *testing.T) {
+	db, mock, _ := newTestDB(t)
+
+	// Chunks indexed since the backfill without a vector in the new space keep
+	// the old model active.
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*)`)).
+		WithArgs("hash-v2").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+	err := activate(context.Background(), db, "hash-v2")
+	if err == nil || !strings.Contains(err.Error(), "1 chunks") {
+		t.Errorf("activate() error = %v, want chunks missing an embedding", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*)`)).
+		WithArgs("hash-v2").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE embedding_models SET status = 'retired'`)).
+		WithArgs("hash-v2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE embedding_models SET status = 'active'`)).
+		WithArgs("hash-v2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := activate(context.Background(), db, "hash-v2"); err != nil {
+		t.Errorf("activate() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	db, mock, e := newTestDB(t)
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_chunk_embeddings_hash_v2" ON chunk_embeddings
+			USING hnsw ((embedding::vector(4)) vector_cosine_ops)
+			WHERE model = 'hash-v2' AND dimension = 4`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := createIndex(context.Background(), db, e); err != nil {
+		t.Errorf("createIndex() error = %v", err)
+	}
+
+	// pgvector cannot index the space of a larger model.
+	large, err := embedding.New("hash-v2", maxIndexedDimension+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := createIndex(context.Background(), db, large); err != nil {
+		t.Errorf("createIndex() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
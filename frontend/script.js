@@ -11,7 +11,7 @@ document.addEventListener('DOMContentLoaded', () => {
         resultsContainer.innerHTML = '<p>Searching...</p>';
 
         try {
-            const response = await fetch('/search', {
+            const response = await fetch('/api/v1/search', {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// keyPrefix makes Portal API keys recognisable, e.g. by secret scanners.
+const keyPrefix = "portal_"
+
+// HashKey returns the hex-encoded SHA-256 of an API key. Keys are 256 random
+// bits, so a fast unsalted hash is enough to make a leaked table useless.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewKey returns a new random API key.
+func NewKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+// DBKeys stores API keys in the api_keys table.
+type DBKeys struct {
+	db *sql.DB
+}
+
+// NewDBKeys returns a KeyStore backed by db.
+func NewDBKeys(db *sql.DB) *DBKeys {
+	return &DBKeys{db: db}
+}
+
+// LookupKey implements KeyStore.
+func (k *DBKeys) LookupKey(ctx context.Context, hash string) (*Identity, error) {
+	var subject string
+	err := k.db.QueryRowContext(ctx,
+		`SELECT subject FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`, hash).Scan(&subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to look up api key: %w", err)
+	}
+	return &Identity{Subject: subject, Method: MethodAPIKey}, nil
+}
+
+// Create stores a new key for subject and returns its ID and the key. The key
+// cannot be recovered later.
+func (k *DBKeys) Create(ctx context.Context, subject, name string) (id, key string, err error) {
+	if key, err = NewKey(); err != nil {
+		return "", "", fmt.Errorf("auth: failed to generate api key: %w", err)
+	}
+	err = k.db.QueryRowContext(ctx,
+		`INSERT INTO api_keys (subject, name, key_hash) VALUES ($1, $2, $3) RETURNING id`,
+		subject, name, HashKey(key)).Scan(&id)
+	if err != nil {
+		return "", "", fmt.Errorf("auth: failed to store api key: %w", err)
+	}
+	return id, key, nil
+}
+
+// Revoke revokes the key with the given ID.
+func (k *DBKeys) Revoke(ctx context.Context, id string) error {
+	res, err := k.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("auth: failed to revoke api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("auth: no active api key with id %s", id)
+	}
+	return nil
+}
+
+// Command runs the "apikey" subcommand of the API Gateway. args are the
+// arguments after "apikey": "create SUBJECT [NAME]", "revoke ID" or "list".
+func Command(ctx context.Context, db *sql.DB, args []string, out io.Writer) error {
+	keys := NewDBKeys(db)
+	if len(args) == 0 {
+		return errors.New("auth: missing command (want create SUBJECT [NAME], revoke ID or list)")
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) < 2 || len(args) > 3 {
+			return errors.New("auth: usage: apikey create SUBJECT [NAME]")
+		}
+		name := ""
+		if len(args) == 3 {
+			name = args[2]
+		}
+		id, key, err := keys.Create(ctx, args[1], name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "id:  %s\nkey: %s\n\nStore the key now; it cannot be shown again.\n", id, key)
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New("auth: usage: apikey revoke ID")
+		}
+		if err := keys.Revoke(ctx, args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "revoked %s\n", args[1])
+	case "list":
+		rows, err := db.QueryContext(ctx,
+			`SELECT id, subject, name, created_at, revoked_at FROM api_keys ORDER BY created_at`)
+		if err != nil {
+			return fmt.Errorf("auth: failed to list api keys: %w", err)
+		}
+		defer rows.Close()
+
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSUBJECT\tNAME\tCREATED\tREVOKED")
+		for rows.Next() {
+			var id, subject, name string
+			var created time.Time
+			var revoked sql.NullTime
+			if err := rows.Scan(&id, &subject, &name, &created, &revoked); err != nil {
+				return fmt.Errorf("auth: failed to list api keys: %w", err)
+			}
+			revokedAt := "-"
+			if revoked.Valid {
+				revokedAt = revoked.Time.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", id, subject, name, created.Format(time.RFC3339), revokedAt)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("auth: failed to list api keys: %w", err)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("auth: unknown command %q (want create, revoke or list)", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package auth authenticates callers of the API Gateway and carries their
+// identity to the backend services.
+//
+// Callers authenticate with a static API key in the X-Api-Key header, or with
+// a JWT bearer token in the Authorization header. API keys are stored hashed
+// in Postgres; tokens are verified against the keys of a JWKS document read
+// from a file or URL. The resulting Identity travels in the request context
+// and, through the gRPC interceptors, in the metadata of backend calls.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APIKeyHeader is the HTTP header carrying an API key.
+const APIKeyHeader = "X-Api-Key"
+
+// Authentication methods reported in Identity.Method.
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned for unknown or revoked API keys and
+	// for tokens that fail verification.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrNotConfigured is returned when a request uses a method the gateway
+	// was not configured for.
+	ErrNotConfigured = errors.New("authentication method not configured")
+)
+
+// Identity is an authenticated caller.
+type Identity struct {
+	// Subject identifies the caller: the subject of an API key, or the sub
+	// claim of a token.
+	Subject string
+	// Method is MethodAPIKey or MethodJWT.
+	Method string
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity stored in ctx, or nil for anonymous
+// requests.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
+
+// KeyStore looks up API keys by their hash.
+type KeyStore interface {
+	// LookupKey returns the identity of the key with the given hash, or
+	// ErrInvalidCredentials if there is no such active key.
+	LookupKey(ctx context.Context, hash string) (*Identity, error)
+}
+
+// Authenticator verifies the credentials of HTTP requests. Either
+// verification method may be left unconfigured.
+type Authenticator struct {
+	// Keys verifies API keys. If nil, API keys are rejected.
+	Keys KeyStore
+	// JWKS provides the keys tokens are verified with. If nil, bearer tokens
+	// are rejected.
+	JWKS *JWKS
+	// Issuer and Audience, if set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+}
+
+// Authenticate returns the identity of the caller of r. It returns
+// ErrNoCredentials if r carries neither an API key nor a bearer token.
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		if a.Keys == nil {
+			return nil, fmt.Errorf("api key: %w", ErrNotConfigured)
+		}
+		return a.Keys.LookupKey(r.Context(), HashKey(key))
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrNoCredentials
+	}
+	if a.JWKS == nil {
+		return nil, fmt.Errorf("bearer token: %w", ErrNotConfigured)
+	}
+	return a.verifyToken(r.Context(), token)
+}
+
+// verifyToken checks the signature and standard claims of a JWT.
+func (a *Authenticator) verifyToken(ctx context.Context, token string) (*Identity, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+	}
+	if a.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.Issuer))
+	}
+	if a.Audience != "" {
+		opts = append(opts, jwt.WithAudience(a.Audience))
+	}
+
+	var claims jwt.RegisteredClaims
+	if _, err := jwt.ParseWithClaims(token, &claims, a.JWKS.keyfunc(ctx), opts...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	return &Identity{Subject: claims.Subject, Method: MethodJWT}, nil
+}
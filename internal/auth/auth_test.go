@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// writeJWKS writes a key set holding the public half of key to a file.
+func writeJWKS(t *testing.T, kid string, key *rsa.PrivateKey) string {
+	t.Helper()
+	set := map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func signToken(t *testing.T, kid string, key *rsa.PrivateKey, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := NewJWKS(context.Background(), writeJWKS(t, "k1", key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Authenticator{JWKS: jwks, Issuer: "https://issuer.example"}
+
+	valid := jwt.RegisteredClaims{
+		Subject:   "user-1",
+		Issuer:    "https://issuer.example",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	req := httptest.NewRequest(http.MethodPost, "/search", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "k1", key, valid))
+	id, err := a.Authenticate(req)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if id.Subject != "user-1" || id.Method != MethodJWT {
+		t.Errorf("unexpected identity %+v", id)
+	}
+
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "https://other.example"
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	for name, token := range map[string]string{
+		"expired":      signToken(t, "k1", key, expired),
+		"wrong issuer": signToken(t, "k1", key, wrongIssuer),
+		"wrong key":    signToken(t, "k1", other, valid),
+	} {
+		req.Header.Set("Authorization", "Bearer "+token)
+		if _, err := a.Authenticate(req); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: expected ErrInvalidCredentials, got %v", name, err)
+		}
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	a := &Authenticator{Keys: NewDBKeys(db)}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT subject FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`)).
+		WithArgs(HashKey("portal_good")).
+		WillReturnRows(sqlmock.NewRows([]string{"subject"}).AddRow("team-a"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT subject FROM api_keys`)).
+		WithArgs(HashKey("portal_revoked")).
+		WillReturnRows(sqlmock.NewRows([]string{"subject"}))
+
+	req := httptest.NewRequest(http.MethodPost, "/search", nil)
+	req.Header.Set(APIKeyHeader, "portal_good")
+	id, err := a.Authenticate(req)
+	if err != nil || id.Subject != "team-a" || id.Method != MethodAPIKey {
+		t.Errorf("expected team-a, got %+v, %v", id, err)
+	}
+
+	req.Header.Set(APIKeyHeader, "portal_revoked")
+	if _, err := a.Authenticate(req); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	if _, err := a.Authenticate(httptest.NewRequest(http.MethodPost, "/search", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/search", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	if _, err := a.Authenticate(req); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("expected ErrNotConfigured without a JWKS, got %v", err)
+	}
+}
+
+func TestIdentityPropagation(t *testing.T) {
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	ctx := WithIdentity(context.Background(), &Identity{Subject: "user-1", Method: MethodJWT})
+	if err := UnaryClientInterceptor()(ctx, "/svc/M", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen *Identity
+	handler := func(ctx context.Context, req any) (any, error) {
+		seen = FromContext(ctx)
+		return nil, nil
+	}
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	if _, err := UnaryServerInterceptor()(incoming, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/M"}, handler); err != nil {
+		t.Fatal(err)
+	}
+	if seen == nil || seen.Subject != "user-1" || seen.Method != MethodJWT {
+		t.Errorf("expected the identity to reach the handler, got %+v", seen)
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys carrying the caller identity to the backend services. The
+// backends trust them as set by the gateway, so they must only be reachable
+// from inside the deployment.
+const (
+	SubjectMetadataKey = "x-portal-subject"
+	MethodMetadataKey  = "x-portal-auth-method"
+)
+
+// UnaryClientInterceptor forwards the identity in ctx to the called service.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := FromContext(ctx); id != nil {
+			ctx = metadata.AppendToOutgoingContext(ctx, SubjectMetadataKey, id.Subject, MethodMetadataKey, id.Method)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerInterceptor restores the identity forwarded by the caller in
+// the handler's context and passes it on to further backend calls.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if subject := md.Get(SubjectMetadataKey); len(subject) > 0 && subject[0] != "" {
+				id := &Identity{Subject: subject[0]}
+				if method := md.Get(MethodMetadataKey); len(method) > 0 {
+					id.Method = method[0]
+				}
+				ctx = WithIdentity(ctx, id)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
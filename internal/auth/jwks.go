@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksMaxAge is how long fetched keys are used before they are reloaded.
+	jwksMaxAge = time.Hour
+	// jwksMinRefresh limits reloads triggered by tokens with an unknown key
+	// ID, so forged tokens cannot make the gateway hammer the JWKS source.
+	jwksMinRefresh = time.Minute
+)
+
+// JWKS holds the public keys of a JSON Web Key Set, read from a file or an
+// http(s) URL. Keys are reloaded when they are older than an hour, or when a
+// token names a key ID that is not in the set, which picks up key rotation.
+type JWKS struct {
+	source string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]any
+	fetched time.Time
+}
+
+// NewJWKS loads the key set from source, a file path or an http(s) URL.
+func NewJWKS(ctx context.Context, source string) (*JWKS, error) {
+	j := &JWKS{source: source, client: &http.Client{Timeout: 10 * time.Second}}
+	if err := j.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// key returns the public key with the given ID. An empty ID is accepted if
+// the set holds a single key.
+func (j *JWKS) key(ctx context.Context, kid string) (any, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, ok := j.lookup(kid)
+	stale := time.Since(j.fetched) > jwksMaxAge
+	if stale || (!ok && time.Since(j.fetched) > jwksMinRefresh) {
+		if err := j.refreshLocked(ctx); err != nil {
+			// Keep serving the keys we have if the source is briefly down.
+			slog.WarnContext(ctx, "Failed to refresh JWKS", "source", j.source, "error", err)
+		} else {
+			key, ok = j.lookup(kid)
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (j *JWKS) lookup(kid string) (any, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, k := range j.keys {
+			return k, true
+		}
+	}
+	k, ok := j.keys[kid]
+	return k, ok
+}
+
+// keyfunc adapts the key set to jwt.Keyfunc.
+func (j *JWKS) keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return j.key(ctx, kid)
+	}
+}
+
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.refreshLocked(ctx)
+}
+
+func (j *JWKS) refreshLocked(ctx context.Context) error {
+	data, err := j.read(ctx)
+	if err != nil {
+		return fmt.Errorf("auth: failed to read JWKS from %s: %w", j.source, err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("auth: invalid JWKS from %s: %w", j.source, err)
+	}
+	j.keys = keys
+	j.fetched = time.Now()
+	return nil
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk is a single JSON Web Key. Only the fields of RSA and EC public keys
+// are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the signing keys of a key set by key ID. Keys of other
+// types or uses are skipped.
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]any{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key any
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsaKey()
+		case "EC":
+			key, err = k.ecKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signing keys")
+	}
+	return keys, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := decodeInt(k.E)
+	if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := decodeInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := decodeInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
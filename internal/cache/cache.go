@@ -0,0 +1,125 @@
+// Package cache stores computed results, such as expert answers and search
+// summaries, so repeated queries skip the fan-out and the LLM calls.
+//
+// Entries are tagged with the URLs of the experts they were computed from,
+// and keys include the content version of those experts, so an entry is
+// never served once an expert changes. Invalidate removes the entries of a
+// changed expert early. A cache is an in-memory LRU, optionally in front of
+// a Postgres table shared by every replica. Semantic finds entries by the
+// similarity of query embeddings instead, so paraphrases share a result.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"portal.com/portal/internal/telemetry"
+)
+
+// Entry is a cached value.
+type Entry struct {
+	Value []byte
+	// Tags are the URLs of the experts the value was computed from.
+	Tags    []string
+	Expires time.Time
+}
+
+// Cache stores entries by key.
+type Cache interface {
+	// Get returns the entry for key, or nil if there is none or it has
+	// expired.
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set stores e under key.
+	Set(ctx context.Context, key string, e *Entry) error
+	// Invalidate removes the entries tagged with tag.
+	Invalidate(ctx context.Context, tag string) error
+}
+
+// Key returns the cache key for parts, e.g. the kind of result, the expert
+// URL and content version, and the normalized query.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NormalizeQuery folds the spellings of a query that deserve the same
+// answer: case, runs of spaces and trailing punctuation are ignored.
+func NormalizeQuery(query string) string {
+	q := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	return strings.TrimRightFunc(q, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+}
+
+// Layered is an in-memory cache in front of a shared one. Entries found in
+// the shared cache are copied to the local one.
+type Layered struct {
+	Local  Cache
+	Shared Cache
+}
+
+// New returns a cache keeping up to size entries in memory, in front of
+// shared if it is not nil.
+func New(size int, shared Cache) Cache {
+	local := NewLRU(size)
+	if shared == nil {
+		return local
+	}
+	return &Layered{Local: local, Shared: shared}
+}
+
+// Get implements Cache.
+func (l *Layered) Get(ctx context.Context, key string) (*Entry, error) {
+	if e, err := l.Local.Get(ctx, key); e != nil || err != nil {
+		return e, err
+	}
+	e, err := l.Shared.Get(ctx, key)
+	if e != nil {
+		l.Local.Set(ctx, key, e)
+	}
+	return e, err
+}
+
+// Set implements Cache.
+func (l *Layered) Set(ctx context.Context, key string, e *Entry) error {
+	l.Local.Set(ctx, key, e)
+	return l.Shared.Set(ctx, key, e)
+}
+
+// Invalidate implements Cache.
+func (l *Layered) Invalidate(ctx context.Context, tag string) error {
+	l.Local.Invalidate(ctx, tag)
+	return l.Shared.Invalidate(ctx, tag)
+}
+
+// Lookup returns the value cached under key, or nil. A failing cache is
+// logged and treated as a miss, so it never fails a request. The outcome is
+// counted in telemetry.CacheRequests under name.
+func Lookup(ctx context.Context, c Cache, name, key string) []byte {
+	e, err := c.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to read cache", "cache", name, "error", err)
+	}
+	if e == nil {
+		telemetry.CacheRequests.WithLabelValues(name, "miss").Inc()
+		return nil
+	}
+	telemetry.CacheRequests.WithLabelValues(name, "hit").Inc()
+	return e.Value
+}
+
+// Store caches value under key for ttl, tagged with the URLs of the experts
+// it was computed from. Failures are logged.
+func Store(ctx context.Context, c Cache, name, key string, value []byte, tags []string, ttl time.Duration) {
+	e := &Entry{Value: value, Tags: tags, Expires: time.Now().Add(ttl)}
+	if err := c.Set(ctx, key, e); err != nil {
+		slog.WarnContext(ctx, "Failed to write cache", "cache", name, "error", err)
+	}
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func entry(value string, tags ...string) *Entry {
+	return &Entry{Value: []byte(value), Tags: tags, Expires: time.Now().Add(time.Hour)}
+}
+
+func get(t *testing.T, c Cache, key string) string {
+	t.Helper()
+	e, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", key, err)
+	}
+	if e == nil {
+		return ""
+	}
+	return string(e.Value)
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	c.Set(ctx, "a", entry("1", "https://a.example/"))
+	c.Set(ctx, "b", entry("2", "https://b.example/"))
+	get(t, c, "a") // b is now the least recently used
+	c.Set(ctx, "c", entry("3", "https://a.example/", "https://b.example/"))
+	if get(t, c, "a") != "1" || get(t, c, "b") != "" || get(t, c, "c") != "3" {
+		t.Errorf("expected b to be evicted")
+	}
+
+	c.Invalidate(ctx, "https://a.example/")
+	if c.Len() != 0 {
+		t.Errorf("expected the entries tagged with a.example to be removed, %d left", c.Len())
+	}
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.Set(ctx, "d", &Entry{Value: []byte("4"), Expires: now.Add(time.Minute)})
+	c.now = func() time.Time { return now.Add(time.Minute) }
+	if get(t, c, "d") != "" {
+		t.Error("expected d to have expired")
+	}
+
+	empty := NewLRU(0)
+	empty.Set(ctx, "a", entry("1"))
+	if get(t, empty, "a") != "" {
+		t.Error("expected a zero-sized cache to store nothing")
+	}
+}
+
+func TestLayered(t *testing.T) {
+	ctx := context.Background()
+	shared := NewLRU(10)
+	c := New(10, shared)
+	shared.Set(ctx, "a", entry("1", "https://a.example/"))
+
+	// Shared entries are copied to the local cache.
+	local := c.(*Layered).Local.(*LRU)
+	if get(t, c, "a") != "1" || local.Len() != 1 {
+		t.Errorf("expected a hit from the shared cache")
+	}
+	c.Set(ctx, "b", entry("2"))
+	if get(t, shared, "b") != "2" {
+		t.Error("expected Set to write through")
+	}
+	c.Invalidate(ctx, "https://a.example/")
+	if local.Len() != 1 || shared.Len() != 1 {
+		t.Errorf("expected Invalidate to reach both caches")
+	}
+}
+
+func TestPostgres(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	c := NewPostgres(db)
+	ctx := context.Background()
+
+	expires := time.Now().Add(time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT value, tags, expires_at FROM result_cache WHERE key = $1 AND expires_at > NOW()`)).
+		WithArgs("k").
+		WillReturnRows(sqlmock.NewRows([]string{"value", "tags", "expires_at"}).AddRow([]byte("v"), "{https://a.example/}", expires))
+	e, err := c.Get(ctx, "k")
+	if err != nil || string(e.Value) != "v" || len(e.Tags) != 1 || e.Tags[0] != "https://a.example/" {
+		t.Errorf("unexpected entry %+v, %v", e, err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM result_cache WHERE expires_at <= NOW()`)).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO result_cache (key, value, tags, expires_at)`)).
+		WithArgs("k", []byte("v"), pq.Array([]string{"https://a.example/"}), expires).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := c.Set(ctx, "k", &Entry{Value: []byte("v"), Tags: []string{"https://a.example/"}, Expires: expires}); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM result_cache WHERE tags @> ARRAY[$1::text]`)).
+		WithArgs("https://a.example/").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := c.Invalidate(ctx, "https://a.example/"); err != nil {
+		t.Errorf("Invalidate() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	if got := NormalizeQuery("  What IS   Colly?? "); got != "what is colly" {
+		t.Errorf("unexpected normalized query %q", got)
+	}
+	if Key("a", "bc") == Key("ab", "c") {
+		t.Error("expected keys to separate their parts")
+	}
+}
+
+func TestSemantic(t *testing.T) {
+	s := NewSemantic(2)
+	expires := time.Now().Add(time.Hour)
+	s.Add(&SemanticEntry{Query: "a", Vector: []float32{1, 0, 0}, Value: []byte("a"), Expires: expires})
+	s.Add(&SemanticEntry{Query: "b", Vector: []float32{0, 1, 0}, Value: []byte("b"), Expires: expires})
+	valid := func(*SemanticEntry) bool { return true }
+
+	e, sim := s.Match("", []float32{0.9, 0.1, 0}, 0.9, valid)
+	if e == nil || e.Query != "a" || sim < 0.9 {
+		t.Fatalf("expected a match for a, got %v %v", e, sim)
+	}
+	if e, _ := s.Match("", []float32{0, 0, 1}, 0.9, valid); e != nil {
+		t.Errorf("expected no match for an unrelated query, got %s", e.Query)
+	}
+	if e, _ := s.Match("other", []float32{1, 0, 0}, 0.9, valid); e != nil || s.Len() != 2 {
+		t.Errorf("expected no match in another scope, got %v with %d entries", e, s.Len())
+	}
+
+	// Rejected entries are removed and the next best one is tried.
+	e, _ = s.Match("", []float32{0.8, 0.7, 0}, 0.5, func(e *SemanticEntry) bool { return e.Query != "a" })
+	if e == nil || e.Query != "b" || s.Len() != 1 {
+		t.Errorf("expected b after rejecting a, got %v with %d entries", e, s.Len())
+	}
+
+	// The least recently used entry is evicted when full.
+	s.Add(&SemanticEntry{Query: "c", Vector: []float32{0, 0, 1}, Expires: expires})
+	s.Match("", []float32{0, 1, 0}, 0.9, valid)
+	s.Add(&SemanticEntry{Query: "d", Vector: []float32{1, 1, 0}, Expires: expires})
+	if e, _ := s.Match("", []float32{0, 0, 1}, 0.9, valid); e != nil {
+		t.Errorf("expected c to be evicted, got %s", e.Query)
+	}
+
+	s.now = func() time.Time { return expires }
+	if e, _ := s.Match("", []float32{0, 1, 0}, 0.9, valid); e != nil || s.Len() != 0 {
+		t.Errorf("expected every entry to have expired")
+	}
+}
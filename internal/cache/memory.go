@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU keeps up to a fixed number of entries in the process, evicting the
+// least recently used one when full.
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *lruItem, most recently used first
+	items map[string]*list.Element
+	tags  map[string]map[string]bool // tag -> keys
+	now   func() time.Time
+}
+
+type lruItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewLRU returns an empty LRU holding up to size entries.
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		order: list.New(),
+		items: map[string]*list.Element{},
+		tags:  map[string]map[string]bool{},
+		now:   time.Now,
+	}
+}
+
+// Get implements Cache.
+func (c *LRU) Get(ctx context.Context, key string) (*Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := el.Value.(*lruItem)
+	if !c.now().Before(item.entry.Expires) {
+		c.remove(el)
+		return nil, nil
+	}
+	c.order.MoveToFront(el)
+	return item.entry, nil
+}
+
+// Set implements Cache.
+func (c *LRU) Set(ctx context.Context, key string, e *Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return nil
+	}
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, entry: e})
+	for _, tag := range e.Tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]bool{}
+		}
+		c.tags[tag][key] = true
+	}
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Invalidate implements Cache.
+func (c *LRU) Invalidate(ctx context.Context, tag string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.tags[tag] {
+		c.remove(c.items[key])
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet
+// evicted.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	item := c.order.Remove(el).(*lruItem)
+	delete(c.items, item.key)
+	for _, tag := range item.entry.Tags {
+		delete(c.tags[tag], item.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
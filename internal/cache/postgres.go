@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// pruneInterval is how often Postgres deletes expired entries.
+const pruneInterval = time.Minute
+
+// Postgres keeps entries in the result_cache table, shared by every
+// replica of every service using it. Expired rows are deleted from time to
+// time as entries are written.
+type Postgres struct {
+	db *sql.DB
+
+	mu     sync.Mutex
+	pruned time.Time
+}
+
+// NewPostgres returns a Cache backed by db.
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Get implements Cache.
+func (p *Postgres) Get(ctx context.Context, key string) (*Entry, error) {
+	var e Entry
+	err := p.db.QueryRowContext(ctx,
+		`SELECT value, tags, expires_at FROM result_cache WHERE key = $1 AND expires_at > NOW()`,
+		key).Scan(&e.Value, pq.Array(&e.Tags), &e.Expires)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Set implements Cache.
+func (p *Postgres) Set(ctx context.Context, key string, e *Entry) error {
+	if err := p.prune(ctx); err != nil {
+		return err
+	}
+	_, err := p.db.ExecContext(ctx, `
+		INSERT INTO result_cache (key, value, tags, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, tags = EXCLUDED.tags, expires_at = EXCLUDED.expires_at`,
+		key, e.Value, pq.Array(e.Tags), e.Expires)
+	return err
+}
+
+// Invalidate implements Cache.
+func (p *Postgres) Invalidate(ctx context.Context, tag string) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM result_cache WHERE tags @> ARRAY[$1::text]`, tag)
+	return err
+}
+
+func (p *Postgres) prune(ctx context.Context) error {
+	p.mu.Lock()
+	if time.Since(p.pruned) < pruneInterval {
+		p.mu.Unlock()
+		return nil
+	}
+	p.pruned = time.Now()
+	p.mu.Unlock()
+	_, err := p.db.ExecContext(ctx, `DELETE FROM result_cache WHERE expires_at <= NOW()`)
+	return err
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// SemanticEntry is a cached value found by the similarity of the query it
+// answers rather than by key.
+type SemanticEntry struct {
+	Query string
+	// Scope restricts the entry to queries with the same scope, such as the
+	// same search filters.
+	Scope  string
+	Vector []float32
+	// Versions are the content versions of the experts the value was
+	// computed from, by URL.
+	Versions map[string]int64
+	Value    []byte
+	Expires  time.Time
+}
+
+// Semantic keeps up to a fixed number of entries in the process and finds
+// the one whose query embedding is closest to a new query's. Entries are
+// scanned linearly, which is fast enough for the few thousand entries a
+// replica holds; the least recently used entry is evicted when full.
+type Semantic struct {
+	mu      sync.Mutex
+	size    int
+	entries []*semanticItem
+	clock   int64
+	now     func() time.Time
+}
+
+type semanticItem struct {
+	entry    *SemanticEntry
+	norm     float64
+	lastUsed int64
+}
+
+// NewSemantic returns an empty Semantic holding up to size entries.
+func NewSemantic(size int) *Semantic {
+	return &Semantic{size: size, now: time.Now}
+}
+
+// Add stores e, replacing an entry for the same query and scope.
+func (s *Semantic) Add(e *SemanticEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size <= 0 {
+		return
+	}
+	s.clock++
+	item := &semanticItem{entry: e, norm: norm(e.Vector), lastUsed: s.clock}
+	for i, it := range s.entries {
+		if it.entry.Query == e.Query && it.entry.Scope == e.Scope {
+			s.entries[i] = item
+			return
+		}
+	}
+	if len(s.entries) < s.size {
+		s.entries = append(s.entries, item)
+		return
+	}
+	oldest := 0
+	for i, it := range s.entries {
+		if it.lastUsed < s.entries[oldest].lastUsed {
+			oldest = i
+		}
+	}
+	s.entries[oldest] = item
+}
+
+// Match returns the unexpired entry of scope most similar to vector, if its
+// cosine similarity is at least threshold, together with the similarity. valid is
+// called on the best candidate, without holding the lock, and entries it
+// rejects, e.g. because an expert changed, are removed and the next best
+// candidate is tried.
+func (s *Semantic) Match(scope string, vector []float32, threshold float64, valid func(*SemanticEntry) bool) (*SemanticEntry, float64) {
+	n := norm(vector)
+	if n == 0 {
+		return nil, 0
+	}
+	rejected := map[*SemanticEntry]bool{}
+	for {
+		best, similarity := s.best(scope, vector, n, threshold, rejected)
+		if best == nil {
+			return nil, 0
+		}
+		if valid(best) {
+			s.touch(best)
+			return best, similarity
+		}
+		rejected[best] = true
+		s.remove(best)
+	}
+}
+
+func (s *Semantic) best(scope string, vector []float32, n, threshold float64, rejected map[*SemanticEntry]bool) (*SemanticEntry, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var best *SemanticEntry
+	bestSimilarity := threshold
+	kept := s.entries[:0]
+	for _, it := range s.entries {
+		if !now.Before(it.entry.Expires) {
+			continue
+		}
+		kept = append(kept, it)
+		if it.entry.Scope != scope || rejected[it.entry] || it.norm == 0 || len(it.entry.Vector) != len(vector) {
+			continue
+		}
+		if sim := dot(vector, it.entry.Vector) / (n * it.norm); sim >= bestSimilarity {
+			best, bestSimilarity = it.entry, sim
+		}
+	}
+	clear(s.entries[len(kept):])
+	s.entries = kept
+	return best, bestSimilarity
+}
+
+func (s *Semantic) touch(e *SemanticEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock++
+	for _, it := range s.entries {
+		if it.entry == e {
+			it.lastUsed = s.clock
+		}
+	}
+}
+
+func (s *Semantic) remove(e *SemanticEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, it := range s.entries {
+		if it.entry == e {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet
+// evicted.
+func (s *Semantic) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func norm(v []float32) float64 {
+	return math.Sqrt(dot(v, v))
+}
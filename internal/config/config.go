@@ -1,3 +1,258 @@
+// Package config loads service configuration from flags, environment variables
+// and an optional YAML file.
+//
+// Options are declared like standard flags. Each option is resolved in the
+// following order, the first source that sets it wins:
+//
+//  1. Command-line flags, e.g. -db-conn=...
+//  2. Environment variables named PORTAL_ plus the upper-cased flag name with
+//     dashes replaced by underscores, e.g. PORTAL_DB_CONN.
+//  3. The YAML file given by -config or PORTAL_CONFIG. Top-level keys are
+//     shared by all services; keys under a section named after the service
+//     (e.g. "rag-service:") override them for that service only.
+//  4. The default value of the flag.
 package config
 
-// TODO: Implement configuration loading from environment variables and flags.
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is prepended to every environment variable name.
+const envPrefix = "PORTAL_"
+
+// redacted replaces secret values in String.
+const redacted = "****"
+
+// Loader declares and resolves the configuration of a single service.
+type Loader struct {
+	service    string
+	fs         *flag.FlagSet
+	configPath string
+	required   map[string]bool
+	secret     map[string]bool
+	source     map[string]string
+}
+
+// New returns a Loader for the named service. The -config flag is registered
+// automatically.
+func New(service string) *Loader {
+	l := &Loader{
+		service:  service,
+		fs:       flag.NewFlagSet(service, flag.ExitOnError),
+		required: map[string]bool{},
+		secret:   map[string]bool{},
+		source:   map[string]string{},
+	}
+	l.fs.StringVar(&l.configPath, "config", "", "Path to a YAML configuration file (env PORTAL_CONFIG)")
+	return l
+}
+
+// StringVar declares a string option.
+func (l *Loader) StringVar(p *string, name, value, usage string) {
+	l.fs.StringVar(p, name, value, usage)
+}
+
+// IntVar declares an int option.
+func (l *Loader) IntVar(p *int, name string, value int, usage string) {
+	l.fs.IntVar(p, name, value, usage)
+}
+
+// BoolVar declares a bool option.
+func (l *Loader) BoolVar(p *bool, name string, value bool, usage string) {
+	l.fs.BoolVar(p, name, value, usage)
+}
+
+// DurationVar declares a time.Duration option.
+func (l *Loader) DurationVar(p *time.Duration, name string, value time.Duration, usage string) {
+	l.fs.DurationVar(p, name, value, usage)
+}
+
+// Float64Var declares a float64 option.
+func (l *Loader) Float64Var(p *float64, name string, value float64, usage string) {
+	l.fs.Float64Var(p, name, value, usage)
+}
+
+// Required marks options that must resolve to a non-empty value.
+func (l *Loader) Required(names ...string) {
+	for _, name := range names {
+		l.required[name] = true
+	}
+}
+
+// Secret marks options whose values must not be printed, such as passwords
+// or connection strings that embed them.
+func (l *Loader) Secret(names ...string) {
+	for _, name := range names {
+		l.secret[name] = true
+	}
+}
+
+// EnvName returns the environment variable that sets the named option.
+func EnvName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Load resolves every option from args (usually os.Args[1:]), the environment
+// and the configuration file, then validates required options.
+func (l *Loader) Load(args []string) error {
+	if err := l.fs.Parse(args); err != nil {
+		return err
+	}
+	l.fs.Visit(func(f *flag.Flag) { l.source[f.Name] = "flag" })
+
+	if l.configPath == "" {
+		if l.configPath = os.Getenv(EnvName("config")); l.configPath != "" {
+			l.source["config"] = "env"
+		}
+	}
+	file, err := l.readFile()
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	l.fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := l.source[f.Name]; ok || f.Name == "config" {
+			return
+		}
+		if v, ok := os.LookupEnv(EnvName(f.Name)); ok {
+			if err := f.Value.Set(v); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: invalid value %q: %v", EnvName(f.Name), v, err))
+			}
+			l.source[f.Name] = "env"
+			return
+		}
+		if v, ok := file[f.Name]; ok {
+			if err := f.Value.Set(v); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s: invalid value %q: %v", l.configPath, f.Name, v, err))
+			}
+			l.source[f.Name] = "file"
+			return
+		}
+		l.source[f.Name] = "default"
+	})
+
+	names := make([]string, 0, len(l.required))
+	for name := range l.required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := l.fs.Lookup(name)
+		if f == nil {
+			errs = append(errs, fmt.Sprintf("required option %s is not declared", name))
+		} else if f.Value.String() == "" {
+			errs = append(errs, fmt.Sprintf("%s is required (flag -%s or env %s)", name, name, EnvName(name)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// readFile returns the option values from the configuration file, with the
+// service's own section applied on top of the shared top-level keys.
+func (l *Loader) readFile() (map[string]string, error) {
+	values := map[string]string{}
+	if l.configPath == "" {
+		return values, nil
+	}
+	data, err := os.ReadFile(l.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", l.configPath, err)
+	}
+
+	for key, v := range doc {
+		if _, isSection := v.(map[string]any); !isSection {
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	if section, ok := doc[l.service].(map[string]any); ok {
+		for key, v := range section {
+			if l.fs.Lookup(key) == nil {
+				return nil, fmt.Errorf("config: %s: unknown option %s.%s", l.configPath, l.service, key)
+			}
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
+
+// Args returns the arguments remaining after the flags, e.g. a subcommand.
+func (l *Loader) Args() []string {
+	return l.fs.Args()
+}
+
+// effective calls fn with every option's printable value and source.
+func (l *Loader) effective(fn func(name, value, source string)) {
+	l.fs.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if l.secret[f.Name] {
+			value = Redact(value)
+		}
+		src := l.source[f.Name]
+		if src == "" {
+			src = "default"
+		}
+		fn(f.Name, value, src)
+	})
+}
+
+// String returns the effective configuration, one option per line with the
+// source it was resolved from. Secret values are redacted.
+func (l *Loader) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s configuration:", l.service)
+	l.effective(func(name, value, source string) {
+		fmt.Fprintf(&b, "\n  %s = %q (%s)", name, value, source)
+	})
+	return b.String()
+}
+
+// LogValue implements slog.LogValuer so the effective configuration can be
+// logged as structured attributes. Secret values are redacted.
+func (l *Loader) LogValue() slog.Value {
+	var attrs []slog.Attr
+	l.effective(func(name, value, _ string) {
+		attrs = append(attrs, slog.String(name, value))
+	})
+	return slog.GroupValue(attrs...)
+}
+
+var (
+	dsnPassword = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+	urlPassword = regexp.MustCompile(`^([a-z][a-z0-9+.-]*://[^:/@]*:)[^@]*@`)
+)
+
+// Redact hides the secret parts of a value. Passwords in Postgres key/value
+// connection strings and in URLs are masked so the rest stays readable; URLs
+// without a password are shown as is and any other non-empty value is
+// replaced entirely.
+func Redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	if dsnPassword.MatchString(value) {
+		return dsnPassword.ReplaceAllString(value, "${1}"+redacted)
+	}
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return urlPassword.ReplaceAllString(value, "${1}"+redacted+"@")
+	}
+	return redacted
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "portal.yaml")
+	err := os.WriteFile(path, []byte(`
+grpc-port: 1000
+db-conn: host=shared
+nats-url: nats://shared:4222
+test-service:
+  db-conn: host=file password=hunter2
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PORTAL_NATS_URL", "nats://env:4222")
+
+	var port, dbConn, natsURL, addr string
+	l := New("test-service")
+	l.StringVar(&port, "grpc-port", "50051", "")
+	l.StringVar(&dbConn, "db-conn", "", "")
+	l.StringVar(&natsURL, "nats-url", "", "")
+	l.StringVar(&addr, "addr", "default", "")
+	l.Secret("db-conn")
+
+	if err := l.Load([]string{"-config", path, "-grpc-port", "2000", "migrate", "up"}); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if port != "2000" {
+		t.Errorf("flag should win over file, got grpc-port=%s", port)
+	}
+	if natsURL != "nats://env:4222" {
+		t.Errorf("env should win over file, got nats-url=%s", natsURL)
+	}
+	if dbConn != "host=file password=hunter2" {
+		t.Errorf("service section should win over shared keys, got db-conn=%s", dbConn)
+	}
+	if addr != "default" {
+		t.Errorf("expected default, got addr=%s", addr)
+	}
+	if got := strings.Join(l.Args(), " "); got != "migrate up" {
+		t.Errorf("expected remaining args, got %q", got)
+	}
+
+	out := l.String()
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("secret leaked in effective configuration:\n%s", out)
+	}
+	for _, want := range []string{`grpc-port = "2000" (flag)`, `nats-url = "nats://env:4222" (env)`, `db-conn = "host=file password=****" (file)`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in effective configuration:\n%s", want, out)
+		}
+	}
+}
+
+func TestRequired(t *testing.T) {
+	var model string
+	l := New("test-job")
+	l.StringVar(&model, "model", "", "")
+	l.Required("model")
+	if err := l.Load(nil); err == nil || !strings.Contains(err.Error(), "PORTAL_MODEL") {
+		t.Errorf("expected required error naming the env var, got %v", err)
+	}
+}
+
+func TestUnknownServiceOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portal.yaml")
+	if err := os.WriteFile(path, []byte("test-service:\n  typo: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l := New("test-service")
+	if err := l.Load([]string{"-config", path}); err == nil {
+		t.Error("expected error for unknown option in service section")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := map[string]string{
+		"host=postgres user=postgres password=secret dbname=portal": "host=postgres user=postgres password=**** dbname=portal",
+		"postgres://user:secret@db:5432/portal":                     "postgres://user:****@db:5432/portal",
+		"nats://nats:4222":                                          "nats://nats:4222",
+		"an-api-key":                                                "****",
+		"":                                                          "",
+	}
+	for in, want := range tests {
+		if got := Redact(in); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
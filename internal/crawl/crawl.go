@@ -0,0 +1,39 @@
+// Package crawl defines the crawl requests the API Gateway and the Expert
+// service send the crawler over NATS, so every binary agrees on their
+// subject and format.
+package crawl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"portal.com/portal/internal/logger"
+	"portal.com/portal/internal/telemetry"
+)
+
+// RequestSubject is the NATS subject crawl requests are published on.
+const RequestSubject = "crawl-requests"
+
+// RequestMessage asks the crawler to fetch a page, when a caller requests a
+// crawl of a URL without an expert or an administrator reindexes an expert.
+type RequestMessage struct {
+	URL         string    `json:"url"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// NewRequestMsg returns the message requesting a crawl of url, carrying the
+// request ID and trace context of ctx.
+func NewRequestMsg(ctx context.Context, url string) (*nats.Msg, error) {
+	data, err := json.Marshal(RequestMessage{URL: url, RequestedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	msg := nats.NewMsg(RequestSubject)
+	msg.Data = data
+	logger.InjectNATS(ctx, msg)
+	telemetry.InjectNATS(ctx, msg)
+	return msg, nil
+}
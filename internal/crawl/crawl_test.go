@@ -0,0 +1,27 @@
+package crawl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"portal.com/portal/internal/logger"
+)
+
+func TestNewRequestMsg(t *testing.T) {
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	msg, err := NewRequestMsg(ctx, "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != RequestSubject {
+		t.Errorf("subject = %q, want %q", msg.Subject, RequestSubject)
+	}
+	var req RequestMessage
+	if err := json.Unmarshal(msg.Data, &req); err != nil || req.URL != "https://example.com/" || req.RequestedAt.IsZero() {
+		t.Errorf("message %s decodes to %+v, %v", msg.Data, req, err)
+	}
+	if got := logger.RequestID(logger.ExtractNATS(context.Background(), msg)); got != "req-1" {
+		t.Errorf("request ID = %q, want req-1", got)
+	}
+}
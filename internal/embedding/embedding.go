@@ -0,0 +1,115 @@
+// Package embedding provides the text embedding models used by the RAG pipeline.
+//
+// Every vector stored by Portal is tagged with the name and dimension of the
+// model that produced it, so several embedding spaces can coexist in the
+// database while chunks are migrated from one model to another.
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Embedder converts text into vectors in a single embedding space.
+type Embedder interface {
+	// Model returns the name of the model, e.g. "hash-v1".
+	Model() string
+	// Dimension returns the length of the vectors produced by Embed.
+	Dimension() int
+	// Embed returns one vector per input text, in the same order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// New returns the Embedder for the given model name and dimension.
+//
+// Only the mock "hash-" model family is available for now. It performs feature
+// hashing over lowercased tokens, which is deterministic and cheap but still
+// ranks lexically similar text close together.
+// TODO: Add a client for a hosted embedding API (e.g. text-embedding-004).
+func New(model string, dim int) (Embedder, error) {
+	if dim <= 0 {
+		return nil, fmt.Errorf("embedding: invalid dimension %d for model %q", dim, model)
+	}
+	if strings.HasPrefix(model, "hash-") {
+		return &hashEmbedder{model: model, dim: dim}, nil
+	}
+	return nil, fmt.Errorf("embedding: unknown model %q", model)
+}
+
+// hashEmbedder is a mock Embedder based on the hashing trick. The model name
+// seeds the hash, so two hash models produce incompatible spaces just like two
+// real models would.
+type hashEmbedder struct {
+	model string
+	dim   int
+}
+
+func (h *hashEmbedder) Model() string  { return h.model }
+func (h *hashEmbedder) Dimension() int { return h.dim }
+
+func (h *hashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = h.embedOne(text)
+	}
+	return vectors, nil
+}
+
+func (h *hashEmbedder) embedOne(text string) []float32 {
+	v := make([]float32, h.dim)
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, tok := range tokens {
+		f := fnv.New64a()
+		f.Write([]byte(h.model))
+		f.Write([]byte{0})
+		f.Write([]byte(tok))
+		sum := f.Sum64()
+		sign := float32(1)
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		v[sum%uint64(h.dim)] += sign
+	}
+	normalize(v)
+	return v
+}
+
+// normalize scales v to unit length in place. Zero vectors are left as is.
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// VectorLiteral formats v in the text representation accepted by pgvector,
+// e.g. "[0.1,0.2,0.3]", so it can be passed as a query parameter.
+func VectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func dot(a, b []float32) float64 {
+	var s float64
+	for i := range a {
+		s += float64(a[i]) * float64(b[i])
+	}
+	return s
+}
+
+func TestHashEmbedder(t *testing.T) {
+	e, err := New("hash-v1", 64)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if e.Model() != "hash-v1" || e.Dimension() != 64 {
+		t.Fatalf("unexpected model %s/%d", e.Model(), e.Dimension())
+	}
+
+	vecs, err := e.Embed(context.Background(), []string{
+		"gocolly is a scraping framework for Go",
+		"Scraping framework for Go: gocolly!",
+		"postgres vector extension",
+	})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(vecs) != 3 || len(vecs[0]) != 64 {
+		t.Fatalf("unexpected vector shape: %d x %d", len(vecs), len(vecs[0]))
+	}
+	if n := dot(vecs[0], vecs[0]); math.Abs(n-1) > 1e-5 {
+		t.Errorf("expected unit vector, got squared norm %f", n)
+	}
+	if same, other := dot(vecs[0], vecs[1]), dot(vecs[0], vecs[2]); same <= other {
+		t.Errorf("expected paraphrase to be closer (%f) than unrelated text (%f)", same, other)
+	}
+}
+
+func TestHashEmbedderSpacesDiffer(t *testing.T) {
+	a, _ := New("hash-v1", 32)
+	b, _ := New("hash-v2", 32)
+	va, _ := a.Embed(context.Background(), []string{"same text"})
+	vb, _ := b.Embed(context.Background(), []string{"same text"})
+	if VectorLiteral(va[0]) == VectorLiteral(vb[0]) {
+		t.Error("expected different models to produce different vectors")
+	}
+}
+
+func TestNewRejectsUnknownModel(t *testing.T) {
+	if _, err := New("unknown", 768); err == nil {
+		t.Error("expected error for unknown model")
+	}
+	if _, err := New("hash-v1", 0); err == nil {
+		t.Error("expected error for zero dimension")
+	}
+}
+
+func TestVectorLiteral(t *testing.T) {
+	if got := VectorLiteral([]float32{0.5, -1, 0}); got != "[0.5,-1,0]" {
+		t.Errorf("VectorLiteral() = %s", got)
+	}
+}
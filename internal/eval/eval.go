@@ -0,0 +1,284 @@
+// Package eval measures the quality of Portal's search: how well the pages
+// and passages it finds match those a dataset expects, and how close its
+// answers are to reference answers.
+//
+// Every metric is between 0 and 1, higher is better, and is computed the
+// same way for every target, so reports of different runs over the same
+// dataset can be compared.
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+
+	"portal.com/portal/internal/urlnorm"
+)
+
+// Case is a query of a dataset and what answering it should find.
+type Case struct {
+	// ID names the case in reports. It defaults to "line-N".
+	ID    string `json:"id"`
+	Query string `json:"query"`
+	// ExpectedURLs are the pages relevant to the query.
+	ExpectedURLs []string `json:"expected_urls"`
+	// ExpectedPassages are texts of those pages an answer should be drawn
+	// from.
+	ExpectedPassages []string `json:"expected_passages"`
+	// ReferenceAnswer is a good answer to the query.
+	ReferenceAnswer string `json:"reference_answer"`
+	// URLs are the experts to consult for targets without expert selection.
+	// They default to ExpectedURLs.
+	URLs []string `json:"urls"`
+}
+
+// Candidates returns the experts to consult for c.
+func (c *Case) Candidates() []string {
+	if len(c.URLs) > 0 {
+		return c.URLs
+	}
+	return c.ExpectedURLs
+}
+
+// LoadDataset reads cases from JSONL, one case per line. Blank lines are
+// skipped. Every case needs a query and a unique ID.
+func LoadDataset(r io.Reader) ([]Case, error) {
+	var cases []Case
+	ids := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var c Case
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if strings.TrimSpace(c.Query) == "" {
+			return nil, fmt.Errorf("line %d: query is required", n)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", n)
+		}
+		if ids[c.ID] {
+			return nil, fmt.Errorf("line %d: duplicate id %q", n, c.ID)
+		}
+		ids[c.ID] = true
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no cases")
+	}
+	return cases, nil
+}
+
+// Output is what a target found for a case.
+type Output struct {
+	// URLs are the pages found, most relevant first.
+	URLs []string
+	// Passages are the texts found, most relevant first.
+	Passages []string
+	// Answer is empty for targets that do not answer.
+	Answer string
+}
+
+// Names of the metrics, in the order reports list them.
+const (
+	RecallAtK        = "recall@k"
+	MRR              = "mrr"
+	NDCGAtK          = "ndcg@k"
+	PassageRecallAtK = "passage_recall@k"
+	PassageMRR       = "passage_mrr"
+	PassageNDCGAtK   = "passage_ndcg@k"
+	AnswerF1         = "answer_f1"
+	AnswerRougeL     = "answer_rouge_l"
+)
+
+// Metrics are the names of the metrics, in the order reports list them.
+var Metrics = []string{RecallAtK, MRR, NDCGAtK, PassageRecallAtK, PassageMRR, PassageNDCGAtK, AnswerF1, AnswerRougeL}
+
+// Score returns the metrics of out for c, considering the first k URLs and
+// passages. Metrics c has no expectations for, and answer metrics of targets
+// that do not answer, are left out. Failed cases are scored with a nil out,
+// which scores 0 on every metric c has expectations for.
+func Score(c *Case, out *Output, k int, answers bool) map[string]float64 {
+	if out == nil {
+		out = &Output{}
+	}
+	scores := map[string]float64{}
+	if len(c.ExpectedURLs) > 0 {
+		hits := match(out.URLs, c.ExpectedURLs, sameURL)
+		scores[RecallAtK] = recall(hits, len(c.ExpectedURLs), k)
+		scores[MRR] = reciprocalRank(hits)
+		scores[NDCGAtK] = ndcg(hits, len(c.ExpectedURLs), k)
+	}
+	if len(c.ExpectedPassages) > 0 {
+		hits := match(out.Passages, c.ExpectedPassages, containsPassage)
+		scores[PassageRecallAtK] = recall(hits, len(c.ExpectedPassages), k)
+		scores[PassageMRR] = reciprocalRank(hits)
+		scores[PassageNDCGAtK] = ndcg(hits, len(c.ExpectedPassages), k)
+	}
+	if c.ReferenceAnswer != "" && answers {
+		scores[AnswerF1] = TokenF1(out.Answer, c.ReferenceAnswer)
+		scores[AnswerRougeL] = RougeL(out.Answer, c.ReferenceAnswer)
+	}
+	return scores
+}
+
+// match returns, for each of the found items, the index of the expected
+// item it is the first match of, or -1: an expected item found twice counts
+// once.
+func match(found, expected []string, matches func(found, expected string) bool) []int {
+	hits := make([]int, len(found))
+	seen := make([]bool, len(expected))
+	for i, f := range found {
+		hits[i] = -1
+		for j, e := range expected {
+			if !seen[j] && matches(f, e) {
+				hits[i], seen[j] = j, true
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// recall returns the share of the relevant items among the first k found.
+func recall(hits []int, relevant, k int) float64 {
+	n := 0
+	for _, h := range hits[:min(k, len(hits))] {
+		if h >= 0 {
+			n++
+		}
+	}
+	return float64(n) / float64(relevant)
+}
+
+// reciprocalRank returns 1/rank of the first relevant item found, or 0.
+func reciprocalRank(hits []int) float64 {
+	for i, h := range hits {
+		if h >= 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// ndcg returns the normalized discounted cumulative gain of the first k
+// items found, with binary relevance.
+func ndcg(hits []int, relevant, k int) float64 {
+	dcg, ideal := 0.0, 0.0
+	for i, h := range hits[:min(k, len(hits))] {
+		if h >= 0 {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+	for i := range min(k, relevant) {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+	return dcg / ideal
+}
+
+// sameURL reports whether two URLs name the same page.
+func sameURL(a, b string) bool {
+	if ca, err := urlnorm.Canonical(a); err == nil {
+		a = ca
+	}
+	if cb, err := urlnorm.Canonical(b); err == nil {
+		b = cb
+	}
+	return a == b
+}
+
+// passageCoverage is the share of the words of an expected passage a found
+// passage must contain to match it.
+const passageCoverage = 0.8
+
+// containsPassage reports whether found contains most of the words of the
+// expected passage. Found passages are often chunks larger than the
+// expected text, or cut it at different words.
+func containsPassage(found, expected string) bool {
+	want := Tokens(expected)
+	if len(want) == 0 {
+		return false
+	}
+	have := map[string]bool{}
+	for _, w := range Tokens(found) {
+		have[w] = true
+	}
+	n := 0
+	for _, w := range want {
+		if have[w] {
+			n++
+		}
+	}
+	return float64(n)/float64(len(want)) >= passageCoverage
+}
+
+// Tokens returns the lowercased words of text, without punctuation.
+func Tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// TokenF1 returns the F1 score of the words answer shares with reference,
+// counting repeated words as often as both have them.
+func TokenF1(answer, reference string) float64 {
+	got, want := Tokens(answer), Tokens(reference)
+	if len(got) == 0 || len(want) == 0 {
+		return 0
+	}
+	counts := map[string]int{}
+	for _, w := range want {
+		counts[w]++
+	}
+	common := 0
+	for _, w := range got {
+		if counts[w] > 0 {
+			counts[w]--
+			common++
+		}
+	}
+	return f1(common, len(got), len(want))
+}
+
+// RougeL returns the ROUGE-L F1 score of answer against reference: the F1
+// score of their longest common subsequence of words.
+func RougeL(answer, reference string) float64 {
+	got, want := Tokens(answer), Tokens(reference)
+	if len(got) == 0 || len(want) == 0 {
+		return 0
+	}
+	prev, cur := make([]int, len(want)+1), make([]int, len(want)+1)
+	for _, g := range got {
+		for j, w := range want {
+			if g == w {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return f1(prev[len(want)], len(got), len(want))
+}
+
+func f1(common, got, want int) float64 {
+	if common == 0 {
+		return 0
+	}
+	precision, recall := float64(common)/float64(got), float64(common)/float64(want)
+	return 2 * precision * recall / (precision + recall)
+}
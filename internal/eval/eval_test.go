@@ -0,0 +1,162 @@
+package eval
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestLoadDataset(t *testing.T) {
+	cases, err := LoadDataset(strings.NewReader(`{"id": "install", "query": "how do I install colly?", "expected_urls": ["go-colly.org"]}
+
+{"query": "what is nats?", "urls": ["https://nats.io/", "https://go-colly.org/"]}
+`))
+	if err != nil {
+		t.Fatalf("LoadDataset() error = %v", err)
+	}
+	if len(cases) != 2 || cases[0].ID != "install" || cases[1].ID != "line-3" {
+		t.Fatalf("LoadDataset() = %+v", cases)
+	}
+	if got := cases[0].Candidates(); len(got) != 1 || got[0] != "go-colly.org" {
+		t.Errorf("Candidates() = %q, want the expected URLs", got)
+	}
+	if got := cases[1].Candidates(); len(got) != 2 {
+		t.Errorf("Candidates() = %q, want the URLs", got)
+	}
+
+	for _, bad := range []string{
+		`{"id": "a"}`,
+		`{"query": "q", "expected": []}`,
+		`{"id": "a", "query": "q"}` + "\n" + `{"id": "a", "query": "r"}`,
+		"\n",
+	} {
+		if _, err := LoadDataset(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadDataset(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	c := &Case{
+		ExpectedURLs:     []string{"https://go-colly.org/", "https://pkg.go.dev/github.com/gocolly/colly"},
+		ExpectedPassages: []string{"Run go get github.com/gocolly/colly/v2"},
+		ReferenceAnswer:  "Install it with go get.",
+	}
+	out := &Output{
+		URLs: []string{"https://nats.io/", "https://GO-COLLY.org", "https://go-colly.org/"},
+		Passages: []string{
+			"NATS is a messaging system.",
+			"Install Colly: run `go get github.com/gocolly/colly/v2` in your module.",
+		},
+		Answer: "You install it with go get.",
+	}
+	got := Score(c, out, 2, true)
+	want := map[string]float64{
+		// One of two pages in the first two, found second; a page found
+		// twice counts once.
+		RecallAtK: 0.5,
+		MRR:       0.5,
+		NDCGAtK:   (1 / math.Log2(3)) / (1 + 1/math.Log2(3)),
+		// The passage is found second, inside a larger one.
+		PassageRecallAtK: 1,
+		PassageMRR:       0.5,
+		PassageNDCGAtK:   1 / math.Log2(3),
+		AnswerF1:         2 * (5.0 / 6) * 1 / (5.0/6 + 1),
+		AnswerRougeL:     2 * (5.0 / 6) * 1 / (5.0/6 + 1),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Score() = %v, want %v", got, want)
+	}
+	for name, v := range want {
+		if !near(got[name], v) {
+			t.Errorf("%s = %v, want %v", name, got[name], v)
+		}
+	}
+
+	// A failed case scores 0, and targets that do not answer have no answer
+	// metrics.
+	got = Score(c, nil, 2, false)
+	if len(got) != 6 || got[RecallAtK] != 0 || got[PassageMRR] != 0 {
+		t.Errorf("Score() of a failure = %v", got)
+	}
+	if got := Score(&Case{Query: "q"}, out, 2, true); len(got) != 0 {
+		t.Errorf("Score() without expectations = %v, want no metrics", got)
+	}
+}
+
+func TestAnswerOverlap(t *testing.T) {
+	tests := []struct {
+		answer, reference string
+		f1, rougeL        float64
+	}{
+		{"Colly is a Go framework.", "colly is a go framework", 1, 1},
+		{"framework Go a is Colly", "Colly is a Go framework", 1, 0.2},
+		{"", "Colly is a Go framework", 0, 0},
+		{"NATS streams messages", "Colly is a Go framework", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := TokenF1(tt.answer, tt.reference); !near(got, tt.f1) {
+			t.Errorf("TokenF1(%q, %q) = %v, want %v", tt.answer, tt.reference, got, tt.f1)
+		}
+		if got := RougeL(tt.answer, tt.reference); !near(got, tt.rougeL) {
+			t.Errorf("RougeL(%q, %q) = %v, want %v", tt.answer, tt.reference, got, tt.rougeL)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	base := &Report{DatasetSHA256: HashDataset([]byte("cases")), Target: "offline", K: 5, Cases: []CaseResult{
+		{ID: "a", LatencyMS: 10, Metrics: map[string]float64{RecallAtK: 1, MRR: 1}},
+		{ID: "b", LatencyMS: 30, Metrics: map[string]float64{RecallAtK: 0, MRR: 0, AnswerF1: 0.5}},
+	}}
+	base.Summarize()
+	if base.Metrics[RecallAtK] != 0.5 || base.Metrics[AnswerF1] != 0.5 || base.LatencyMS != 20 || base.Failed != 0 {
+		t.Fatalf("Summarize() = %+v", base)
+	}
+
+	var buf bytes.Buffer
+	if err := base.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := t.TempDir() + "/report.json"
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadReport(path)
+	if err != nil || read.Metrics[MRR] != 0.5 || len(read.Cases) != 2 {
+		t.Fatalf("ReadReport() = %+v, %v", read, err)
+	}
+
+	cur := &Report{DatasetSHA256: base.DatasetSHA256, Target: "orchestrator", K: 5, Cases: []CaseResult{
+		{ID: "a", LatencyMS: 10, Metrics: map[string]float64{RecallAtK: 1, MRR: 0.5}},
+		{ID: "b", Error: "unavailable", Metrics: map[string]float64{RecallAtK: 0, MRR: 0, AnswerF1: 0}},
+	}}
+	cur.Summarize()
+	if err := cur.Comparable(read); err != nil {
+		t.Errorf("Comparable() error = %v", err)
+	}
+	if err := (&Report{DatasetSHA256: "other", K: 5}).Comparable(read); err == nil {
+		t.Error("Comparable() succeeded for another dataset")
+	}
+	if err := (&Report{DatasetSHA256: base.DatasetSHA256, K: 3}).Comparable(read); err == nil {
+		t.Error("Comparable() succeeded for another k")
+	}
+	changes := cur.Changes(read)
+	if len(changes) != 2 || changes[0] != (Change{Case: "a", Metric: MRR, From: 1, To: 0.5}) || changes[1].Metric != AnswerF1 {
+		t.Errorf("Changes() = %+v", changes)
+	}
+
+	buf.Reset()
+	if err := cur.WriteText(&buf, read); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"mrr  0.2500    0.5000  -0.2500", "2 cases, 1 failed", "b: unavailable", "a mrr: 1.0000 -> 0.5000"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteText() = %s, want it to contain %q", buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Report is the result of a run over a dataset. Reports of runs with the
+// same dataset and k are comparable.
+type Report struct {
+	// Dataset is the path of the dataset and DatasetSHA256 the hash of its
+	// content, so runs over a changed dataset are not compared.
+	Dataset       string `json:"dataset"`
+	DatasetSHA256 string `json:"dataset_sha256"`
+	// Target is what was evaluated: orchestrator, expert, rag or offline.
+	Target    string    `json:"target"`
+	K         int       `json:"k"`
+	StartedAt time.Time `json:"started_at"`
+	// Metrics are the means of the metrics over the cases that have them.
+	Metrics map[string]float64 `json:"metrics"`
+	// Failed is the number of cases the target failed to answer.
+	Failed int `json:"failed"`
+	// Latency is the mean latency of the cases, in milliseconds.
+	LatencyMS float64      `json:"latency_ms"`
+	Cases     []CaseResult `json:"cases"`
+}
+
+// CaseResult is the result of a case.
+type CaseResult struct {
+	ID        string             `json:"id"`
+	URLs      []string           `json:"urls,omitempty"`
+	Answer    string             `json:"answer,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	LatencyMS float64            `json:"latency_ms"`
+	Metrics   map[string]float64 `json:"metrics"`
+}
+
+// HashDataset returns the hex SHA-256 of a dataset's content.
+func HashDataset(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Summarize fills the report's means from its cases.
+func (r *Report) Summarize() {
+	sums, counts := map[string]float64{}, map[string]int{}
+	r.Failed, r.LatencyMS = 0, 0
+	for _, c := range r.Cases {
+		for name, v := range c.Metrics {
+			sums[name] += v
+			counts[name]++
+		}
+		if c.Error != "" {
+			r.Failed++
+		}
+		r.LatencyMS += c.LatencyMS
+	}
+	r.Metrics = map[string]float64{}
+	for name, sum := range sums {
+		r.Metrics[name] = sum / float64(counts[name])
+	}
+	if len(r.Cases) > 0 {
+		r.LatencyMS /= float64(len(r.Cases))
+	}
+}
+
+// ReadReport reads a report written by WriteJSON.
+func ReadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Comparable returns an error if runs of base and r cannot be compared.
+func (r *Report) Comparable(base *Report) error {
+	if base.DatasetSHA256 != r.DatasetSHA256 {
+		return fmt.Errorf("the baseline was run over a different dataset (%s, sha256 %.12s)", base.Dataset, base.DatasetSHA256)
+	}
+	if base.K != r.K {
+		return fmt.Errorf("the baseline was run with k=%d, not %d", base.K, r.K)
+	}
+	return nil
+}
+
+// Change is how a case's metric changed since a baseline.
+type Change struct {
+	Case   string
+	Metric string
+	From   float64
+	To     float64
+}
+
+// Changes returns the metrics of cases that changed since base, by case and
+// metric.
+func (r *Report) Changes(base *Report) []Change {
+	before := map[string]map[string]float64{}
+	for _, c := range base.Cases {
+		before[c.ID] = c.Metrics
+	}
+	var changes []Change
+	for _, c := range r.Cases {
+		prev, ok := before[c.ID]
+		if !ok {
+			continue
+		}
+		for _, name := range metricNames(c.Metrics) {
+			if from, ok := prev[name]; ok && from != c.Metrics[name] {
+				changes = append(changes, Change{Case: c.ID, Metric: name, From: from, To: c.Metrics[name]})
+			}
+		}
+	}
+	return changes
+}
+
+// WriteText writes the report's metrics as a table, with their change since
+// base if it is not nil, followed by the failed cases and the changes of
+// every case.
+func (r *Report) WriteText(w io.Writer, base *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "metric\tvalue\t"
+	if base != nil {
+		header += "baseline\tchange\t"
+	}
+	fmt.Fprintln(tw, header)
+	for _, name := range metricNames(r.Metrics) {
+		line := fmt.Sprintf("%s\t%.4f\t", name, r.Metrics[name])
+		if base != nil {
+			if from, ok := base.Metrics[name]; ok {
+				line += fmt.Sprintf("%.4f\t%+.4f\t", from, r.Metrics[name]-from)
+			} else {
+				line += "-\t-\t"
+			}
+		}
+		fmt.Fprintln(tw, line)
+	}
+	fmt.Fprintf(tw, "latency_ms\t%.1f\t", r.LatencyMS)
+	if base != nil {
+		fmt.Fprintf(tw, "%.1f\t%+.1f\t", base.LatencyMS, r.LatencyMS-base.LatencyMS)
+	}
+	fmt.Fprintln(tw)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d cases, %d failed (target %s, k=%d)\n", len(r.Cases), r.Failed, r.Target, r.K)
+	for _, c := range r.Cases {
+		if c.Error != "" {
+			fmt.Fprintf(w, "  %s: %s\n", c.ID, c.Error)
+		}
+	}
+	if base != nil {
+		changes := r.Changes(base)
+		fmt.Fprintf(w, "\n%d changes since the baseline\n", len(changes))
+		for _, c := range changes {
+			fmt.Fprintf(w, "  %s %s: %.4f -> %.4f\n", c.Case, c.Metric, c.From, c.To)
+		}
+	}
+	return nil
+}
+
+// metricNames returns the names of metrics in the order of Metrics, followed
+// by unknown ones sorted.
+func metricNames(metrics map[string]float64) []string {
+	var names, other []string
+	for _, name := range Metrics {
+		if _, ok := metrics[name]; ok {
+			names = append(names, name)
+		}
+	}
+	for name := range metrics {
+		if !slices.Contains(Metrics, name) {
+			other = append(other, name)
+		}
+	}
+	sort.Strings(other)
+	return append(names, other...)
+}
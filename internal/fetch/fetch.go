@@ -0,0 +1,164 @@
+// Package fetch downloads single web pages and extracts their text, for
+// experts created on demand rather than by the crawler.
+//
+// Pages are named by untrusted callers, so connections to loopback, private,
+// link-local and other non-public addresses are refused, including after
+// redirects.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrBlocked is returned for pages on hosts that resolve to a non-public
+// address.
+var ErrBlocked = errors.New("address is not public")
+
+// maxRedirects bounds the redirects followed for a page.
+const maxRedirects = 5
+
+// Fetcher fetches pages over HTTP.
+type Fetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// New returns a Fetcher giving up on a page after timeout and refusing pages
+// larger than maxBytes.
+func New(timeout time.Duration, maxBytes int64) *Fetcher {
+	return newFetcher(timeout, maxBytes, false)
+}
+
+func newFetcher(timeout time.Duration, maxBytes int64, allowPrivate bool) *Fetcher {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		// Checking the address being dialed, rather than the host name,
+		// covers DNS answers and redirects alike.
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !public(ap.Addr()) {
+				return fmt.Errorf("%s: %w", ap.Addr(), ErrBlocked)
+			}
+			return nil
+		}
+	}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &Fetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return nil
+			},
+		},
+		maxBytes: maxBytes,
+	}
+}
+
+// public reports whether addr may be fetched.
+func public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() &&
+		// Shared address space (RFC 6598), used by carrier-grade NAT and
+		// some cloud networks.
+		!netip.MustParsePrefix("100.64.0.0/10").Contains(addr)
+}
+
+// Page is a fetched page.
+type Page struct {
+	// Text is the page's text, one line per block of text.
+	Text string
+	// Language is the language the page declares, from its lang attribute
+	// or Content-Language header, or empty.
+	Language string
+}
+
+// Fetch downloads the HTML or plain text page at url and returns its text.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (*Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9")
+	req.Header.Set("User-Agent", "portal-expert-service")
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/html"
+	}
+	if mediaType != "text/html" && mediaType != "text/plain" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %w", err)
+	}
+	if int64(len(body)) > f.maxBytes {
+		return nil, fmt.Errorf("page is larger than %d bytes", f.maxBytes)
+	}
+
+	// Content-Language may list several languages; the first is used.
+	language, _, _ := strings.Cut(res.Header.Get("Content-Language"), ",")
+	page := &Page{Language: strings.TrimSpace(language)}
+	if mediaType == "text/plain" {
+		page.Text = compact(string(body))
+		return page, nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+	// Like the crawler, this keeps all of the body's text rather than trying
+	// to find the main article.
+	doc.Find("script, style, noscript, template").Remove()
+	if lang := strings.TrimSpace(doc.Find("html").AttrOr("lang", "")); lang != "" {
+		page.Language = lang
+	}
+	page.Text = compact(doc.Find("body").Text())
+	return page, nil
+}
+
+// compact collapses the spaces within each line of text and drops blank
+// lines.
+func compact(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,83 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html lang="en-US"><head><title>T</title><style>p{}</style></head>
+<body><h1>Colly</h1>
+<p>Fast   and elegant
+scraping.</p><script>alert(1)</script></body></html>`))
+		case "/moved":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		case "/plain":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Language", "de, en")
+			w.Write([]byte("  Hallo \n\n Welt"))
+		case "/large":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("a", 2048)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := newFetcher(5*time.Second, 1024, true)
+	for _, path := range []string{"/page", "/moved"} {
+		page, err := f.Fetch(context.Background(), srv.URL+path)
+		if err != nil || page.Text != "Colly\nFast and elegant\nscraping." || page.Language != "en-US" {
+			t.Errorf("Fetch(%s) = %+v, %v", path, page, err)
+		}
+	}
+	if page, err := f.Fetch(context.Background(), srv.URL+"/plain"); err != nil || page.Text != "Hallo\nWelt" || page.Language != "de" {
+		t.Errorf("Fetch(/plain) = %+v, %v", page, err)
+	}
+	for _, path := range []string{"/image", "/large", "/missing"} {
+		if page, err := f.Fetch(context.Background(), srv.URL+path); err == nil {
+			t.Errorf("Fetch(%s) = %+v; want an error", path, page)
+		}
+	}
+
+	// The default fetcher refuses the test server's loopback address.
+	if _, err := New(5*time.Second, 1024).Fetch(context.Background(), srv.URL+"/page"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected ErrBlocked, got %v", err)
+	}
+}
+
+func TestPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:2800::1":     true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if got := public(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("public(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package grpcclient
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"portal.com/portal/internal/telemetry"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// Closed lets every call through.
+	Closed State = iota
+	// HalfOpen lets a single call through to probe the service.
+	HalfOpen
+	// Open fails calls without sending them.
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+// breaker is a circuit breaker. It opens after a number of consecutive
+// failures, fails calls fast while open, and after a while lets one call
+// through: its success closes the breaker and its failure opens it again.
+type breaker struct {
+	name     string
+	failures int
+	openFor  time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failed   int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(name string, failures int, openFor time.Duration) *breaker {
+	b := &breaker{name: name, failures: failures, openFor: openFor, now: time.Now}
+	telemetry.CircuitBreakerState.WithLabelValues(name).Set(float64(Closed))
+	return b
+}
+
+// allow reports whether a call may be sent.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.openFor {
+			return false
+		}
+		b.set(HalfOpen)
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+	default:
+		return true
+	}
+	b.probing = true
+	return true
+}
+
+// outcome is how a call counts towards opening the breaker.
+type outcome int
+
+const (
+	success outcome = iota
+	failure
+	// neutral calls, such as those cancelled by the caller, say nothing
+	// about the service.
+	neutral
+)
+
+// classify returns the outcome of a call that ended with err. Only errors
+// pointing at the service count as failures: client errors such as
+// NOT_FOUND are successes, and calls the caller cancelled or let expire are
+// neutral. Calls exceeding the method's own timeout are failures.
+func classify(ctx context.Context, err error) outcome {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal, codes.Unknown, codes.DataLoss:
+		return failure
+	case codes.DeadlineExceeded:
+		if ctx.Err() == nil {
+			return failure
+		}
+		return neutral
+	case codes.Canceled:
+		return neutral
+	}
+	return success
+}
+
+// record counts the outcome of a call let through by allow.
+func (b *breaker) record(o outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case HalfOpen:
+		b.probing = false
+		switch o {
+		case success:
+			b.failed = 0
+			b.set(Closed)
+		case failure:
+			b.openedAt = b.now()
+			b.set(Open)
+		}
+	case Closed:
+		switch o {
+		case success:
+			b.failed = 0
+		case failure:
+			if b.failed++; b.failed >= b.failures {
+				b.openedAt = b.now()
+				b.set(Open)
+			}
+		}
+	}
+	// Calls sent before the breaker opened do not change it.
+}
+
+// set changes the state, which must be locked.
+func (b *breaker) set(s State) {
+	if b.state == s {
+		return
+	}
+	slog.Warn("Circuit breaker changed state", "service", b.name, "from", b.state.String(), "to", s.String())
+	b.state = s
+	telemetry.CircuitBreakerState.WithLabelValues(b.name).Set(float64(s))
+}
+
+func (b *breaker) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		// Health checks report on the service themselves, and must not
+		// take the place of a probe.
+		if method == grpc_health_v1.Health_Check_FullMethodName {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if !b.allow() {
+			telemetry.CircuitBreakerRejections.WithLabelValues(b.name).Inc()
+			return status.Errorf(codes.Unavailable, "circuit breaker for %s is open", b.name)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(classify(ctx, err))
+		return err
+	}
+}
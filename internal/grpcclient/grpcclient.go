@@ -0,0 +1,121 @@
+// Package grpcclient creates the connections between Portal services, so
+// every edge gets the same protection: calls that fail because the server
+// is unavailable are retried, every method has a default deadline, and a
+// circuit breaker fails calls fast while the server keeps failing.
+package grpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"portal.com/portal/internal/telemetry"
+)
+
+// Config configures the connection to one service.
+type Config struct {
+	// Name names the service in logs and metrics, e.g. "expert-service".
+	Name string
+	// MaxAttempts is the number of attempts of a call failing with
+	// UNAVAILABLE, including the first, at most 5. 0 or 1 disables retries.
+	MaxAttempts int
+	// Timeout is the default deadline of calls, and Timeouts overrides it
+	// by full method name, e.g. "/expert.v1.ExpertService/QueryExpert".
+	// Callers may still set a sooner deadline. 0 means no deadline.
+	Timeout  time.Duration
+	Timeouts map[string]time.Duration
+	// BreakerFailures is the number of consecutive failures that opens the
+	// circuit breaker, 0 to disable it. It stays open for BreakerOpenFor
+	// before a single call is let through to probe the service.
+	BreakerFailures int
+	BreakerOpenFor  time.Duration
+}
+
+// maxAttempts is the most attempts gRPC allows in a retry policy.
+const maxAttempts = 5
+
+// New returns a client connection to target with the service config and
+// circuit breaker of cfg, after opts such as the transport credentials and
+// interceptors.
+func New(target string, cfg Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	sc, err := serviceConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithDefaultServiceConfig(sc))
+	if cfg.BreakerFailures > 0 {
+		b := newBreaker(cfg.Name, cfg.BreakerFailures, cfg.BreakerOpenFor)
+		// The breaker is the innermost interceptor, so the logs and metrics
+		// of the others see the calls it rejects.
+		opts = append(opts, grpc.WithChainUnaryInterceptor(b.unaryInterceptor()))
+	} else {
+		telemetry.CircuitBreakerState.DeleteLabelValues(cfg.Name)
+	}
+	return grpc.NewClient(target, opts...)
+}
+
+// The service config is documented at
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md.
+type (
+	methodName struct {
+		Service string `json:"service,omitempty"`
+		Method  string `json:"method,omitempty"`
+	}
+	retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	methodConfig struct {
+		Name        []methodName `json:"name"`
+		Timeout     string       `json:"timeout,omitempty"`
+		RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+	}
+)
+
+// serviceConfig returns the JSON service config of cfg.
+func serviceConfig(cfg Config) (string, error) {
+	if cfg.MaxAttempts < 0 || cfg.MaxAttempts > maxAttempts {
+		return "", fmt.Errorf("grpcclient: %s: max attempts must be between 0 and %d, got %d", cfg.Name, maxAttempts, cfg.MaxAttempts)
+	}
+	var retry *retryPolicy
+	if cfg.MaxAttempts > 1 {
+		retry = &retryPolicy{
+			MaxAttempts:          cfg.MaxAttempts,
+			InitialBackoff:       duration(100 * time.Millisecond),
+			MaxBackoff:           duration(time.Second),
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+	}
+	// Method configs replace the default one rather than extending it, so
+	// each carries the retry policy.
+	configs := []methodConfig{{Name: []methodName{{}}, Timeout: duration(cfg.Timeout), RetryPolicy: retry}}
+	for method, timeout := range cfg.Timeouts {
+		service, name, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+		if !ok || service == "" || name == "" {
+			return "", fmt.Errorf("grpcclient: %s: invalid method name %q", cfg.Name, method)
+		}
+		configs = append(configs, methodConfig{
+			Name:        []methodName{{Service: service, Method: name}},
+			Timeout:     duration(timeout),
+			RetryPolicy: retry,
+		})
+	}
+	b, err := json.Marshal(map[string]any{"methodConfig": configs})
+	return string(b), err
+}
+
+// duration formats d as a service config duration, or "" for 0.
+func duration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,199 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "portal.com/portal/pkg/expert/v1"
+)
+
+// flakyServer fails the first failures calls to QueryExpert with code, and
+// sleeps for delay before answering.
+type flakyServer struct {
+	pb.UnimplementedExpertServiceServer
+	failures int32
+	code     codes.Code
+	delay    time.Duration
+	calls    atomic.Int32
+}
+
+func (s *flakyServer) QueryExpert(ctx context.Context, in *pb.QueryExpertRequest) (*pb.QueryExpertResponse, error) {
+	if s.calls.Add(1) <= s.failures {
+		return nil, status.Error(s.code, "failing")
+	}
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &pb.QueryExpertResponse{Answer: "ok"}, nil
+}
+
+func dial(t *testing.T, srv pb.ExpertServiceServer, cfg Config) pb.ExpertServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterExpertServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := New("passthrough:///bufnet", cfg,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewExpertServiceClient(conn)
+}
+
+func TestRetries(t *testing.T) {
+	srv := &flakyServer{failures: 2, code: codes.Unavailable}
+	client := dial(t, srv, Config{Name: "test", MaxAttempts: 3})
+	res, err := client.QueryExpert(context.Background(), &pb.QueryExpertRequest{})
+	if err != nil {
+		t.Fatalf("QueryExpert() error = %v", err)
+	}
+	if res.Answer != "ok" || srv.calls.Load() != 3 {
+		t.Errorf("got answer %q after %d calls, want \"ok\" after 3", res.Answer, srv.calls.Load())
+	}
+
+	// Other codes are not retried.
+	srv = &flakyServer{failures: 1, code: codes.Internal}
+	client = dial(t, srv, Config{Name: "test", MaxAttempts: 3})
+	if _, err := client.QueryExpert(context.Background(), &pb.QueryExpertRequest{}); status.Code(err) != codes.Internal {
+		t.Errorf("QueryExpert() error = %v, want Internal", err)
+	}
+	if srv.calls.Load() != 1 {
+		t.Errorf("got %d calls, want 1", srv.calls.Load())
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	srv := &flakyServer{delay: time.Second}
+	client := dial(t, srv, Config{
+		Name:     "test",
+		Timeout:  time.Minute,
+		Timeouts: map[string]time.Duration{pb.ExpertService_QueryExpert_FullMethodName: 50 * time.Millisecond},
+	})
+	start := time.Now()
+	_, err := client.QueryExpert(context.Background(), &pb.QueryExpertRequest{})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("QueryExpert() error = %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("QueryExpert() took %v, want the method timeout", d)
+	}
+}
+
+func TestBreakerRejectsWhileOpen(t *testing.T) {
+	srv := &flakyServer{failures: 2, code: codes.Internal}
+	client := dial(t, srv, Config{Name: "test", BreakerFailures: 2, BreakerOpenFor: time.Hour})
+	for range 2 {
+		client.QueryExpert(context.Background(), &pb.QueryExpertRequest{})
+	}
+	_, err := client.QueryExpert(context.Background(), &pb.QueryExpertRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("QueryExpert() error = %v, want Unavailable from the open breaker", err)
+	}
+	if srv.calls.Load() != 2 {
+		t.Errorf("got %d calls, want 2", srv.calls.Load())
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := newBreaker("test", 3, time.Minute)
+	b.now = func() time.Time { return now }
+	fail := func() {
+		t.Helper()
+		if !b.allow() {
+			t.Fatalf("allow() = false in state %v", b.state)
+		}
+		b.record(failure)
+	}
+
+	fail()
+	fail()
+	b.allow()
+	b.record(success)
+	fail()
+	fail()
+	if b.state != Closed {
+		t.Fatalf("state = %v after a success and 2 failures, want closed", b.state)
+	}
+	fail()
+	if b.state != Open || b.allow() {
+		t.Fatalf("state = %v after 3 failures, want open and rejecting", b.state)
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() || b.state != HalfOpen {
+		t.Fatalf("state = %v after the open period, want half-open and probing", b.state)
+	}
+	if b.allow() {
+		t.Errorf("allow() = true during a probe")
+	}
+	b.record(failure)
+	if b.state != Open {
+		t.Fatalf("state = %v after a failed probe, want open", b.state)
+	}
+
+	now = now.Add(time.Minute)
+	b.allow()
+	b.record(neutral)
+	if b.state != HalfOpen || !b.allow() {
+		t.Fatalf("state = %v after a neutral probe, want half-open and probing again", b.state)
+	}
+	b.record(success)
+	if b.state != Closed {
+		t.Errorf("state = %v after a successful probe, want closed", b.state)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want outcome
+	}{
+		{"ok", context.Background(), nil, success},
+		{"not found", context.Background(), status.Error(codes.NotFound, ""), success},
+		{"unavailable", context.Background(), status.Error(codes.Unavailable, ""), failure},
+		{"internal", context.Background(), status.Error(codes.Internal, ""), failure},
+		{"non-status error", context.Background(), errors.New("boom"), failure},
+		{"method timeout", context.Background(), status.Error(codes.DeadlineExceeded, ""), failure},
+		{"caller deadline", expired, status.Error(codes.DeadlineExceeded, ""), neutral},
+		{"canceled", context.Background(), status.Error(codes.Canceled, ""), neutral},
+	}
+	for _, tt := range tests {
+		if got := classify(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: classify() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Name: "test", MaxAttempts: 6},
+		{Name: "test", Timeouts: map[string]time.Duration{"QueryExpert": time.Second}},
+	} {
+		if _, err := New("passthrough:///bufnet", cfg, grpc.WithTransportCredentials(insecure.NewCredentials())); err == nil {
+			t.Errorf("New(%+v) error = nil, want an error", cfg)
+		}
+	}
+}
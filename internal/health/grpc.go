@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// watchInterval is how often Watch re-runs the checks for a stream.
+const watchInterval = 5 * time.Second
+
+// grpcServer implements grpc.health.v1.Health on top of a Checker. The empty
+// service name reports the health of the whole server; every registered
+// service shares the same checks.
+type grpcServer struct {
+	healthpb.UnimplementedHealthServer
+	checker  *Checker
+	services map[string]bool
+}
+
+// RegisterGRPC registers the grpc.health.v1 service on s, answering for the
+// server as a whole and for each of the given fully qualified service names.
+func RegisterGRPC(s *grpc.Server, checker *Checker, services ...string) {
+	srv := &grpcServer{checker: checker, services: map[string]bool{"": true}}
+	for _, name := range services {
+		srv.services[name] = true
+	}
+	healthpb.RegisterHealthServer(s, srv)
+}
+
+func (s *grpcServer) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	failures := s.checker.Check(ctx)
+	if len(failures) == 0 {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	for name, err := range failures {
+		slog.WarnContext(ctx, "Health check failed", "check", name, "error", err)
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// Check implements grpc.health.v1.HealthServer.
+func (s *grpcServer) Check(ctx context.Context, in *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.services[in.Service] {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", in.Service)
+	}
+	return &healthpb.HealthCheckResponse{Status: s.status(ctx)}, nil
+}
+
+// Watch implements grpc.health.v1.HealthServer. It sends the current status
+// and then every change, until the client goes away.
+func (s *grpcServer) Watch(in *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	last := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if s.services[in.Service] {
+			current = s.status(ctx)
+		}
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
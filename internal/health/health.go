@@ -0,0 +1,130 @@
+// Package health reports whether a Portal service and its dependencies are
+// able to serve.
+//
+// A Checker runs named dependency checks, such as pinging the database or
+// asking a downstream service for its own health. gRPC services expose the
+// result through the standard grpc.health.v1 service, and the other commands
+// serve it on HTTP /healthz and /readyz endpoints.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// checkTimeout bounds each individual check, so one hung dependency does not
+// stall the whole report.
+const checkTimeout = 2 * time.Second
+
+// CheckFunc returns an error if a dependency is unavailable.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker runs a set of named dependency checks.
+type Checker struct {
+	mu       sync.RWMutex
+	checks   []check
+	draining bool
+}
+
+// New returns a Checker without any checks, which always reports ready.
+func New() *Checker {
+	return &Checker{}
+}
+
+// Add registers a check under the given name.
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// Shutdown makes the Checker report the service as unavailable from now on,
+// so load balancers stop sending it new work while it drains.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = true
+}
+
+// Check runs every check concurrently and returns the failures keyed by
+// check name. An empty result means the service is ready.
+func (c *Checker) Check(ctx context.Context) map[string]error {
+	c.mu.RLock()
+	checks, draining := c.checks, c.draining
+	c.mu.RUnlock()
+	if draining {
+		return map[string]error{"shutdown": errShuttingDown}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		failures = map[string]error{}
+	)
+	for _, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			if err := chk.fn(ctx); err != nil {
+				mu.Lock()
+				failures[chk.name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return failures
+}
+
+var errShuttingDown = errors.New("service is shutting down")
+
+// Pinger is implemented by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// DB returns a check that pings a database.
+func DB(db Pinger) CheckFunc {
+	return db.PingContext
+}
+
+// GRPC returns a check that asks a downstream service for its health through
+// the grpc.health.v1 service. service is the fully qualified service name,
+// e.g. "rag.v1.RAGService".
+func GRPC(conn grpc.ClientConnInterface, service string) CheckFunc {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("%s is %s", service, res.Status)
+		}
+		return nil
+	}
+}
+
+// NATS returns a check that the connection to the NATS server is up.
+func NATS(nc *nats.Conn) CheckFunc {
+	return func(ctx context.Context) error {
+		if status := nc.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("connection is %s", status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckerReportsFailures(t *testing.T) {
+	c := New()
+	c.Add("ok", func(ctx context.Context) error { return nil })
+	c.Add("db", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	failures := c.Check(context.Background())
+	if len(failures) != 1 || failures["db"] == nil {
+		t.Errorf("expected only the db check to fail, got %v", failures)
+	}
+
+	c.Shutdown()
+	if failures := c.Check(context.Background()); failures["shutdown"] == nil {
+		t.Errorf("expected the checker to report shutdown, got %v", failures)
+	}
+}
+
+func TestHTTPEndpoints(t *testing.T) {
+	healthy := true
+	c := New()
+	c.Add("dep", func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("down")
+		}
+		return nil
+	})
+	mux := http.NewServeMux()
+	RegisterHTTP(mux, c)
+
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Errorf("expected /readyz to be 200, got %d", code)
+	}
+	healthy = false
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected /readyz to be 503, got %d", code)
+	}
+	if code := get("/healthz"); code != http.StatusOK {
+		t.Errorf("expected /healthz to stay 200, got %d", code)
+	}
+}
+
+func TestServe(t *testing.T) {
+	c := New()
+	srv, err := Serve("127.0.0.1:0", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Shutdown()
+	resp, err := http.Get("http://" + srv.Addr + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected /readyz to be 503 after shutdown, got %d", resp.StatusCode)
+	}
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestGRPCCheck(t *testing.T) {
+	healthy := true
+	c := New()
+	c.Add("dep", func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("down")
+		}
+		return nil
+	})
+	s := &grpcServer{checker: c, services: map[string]bool{"": true, "rag.v1.RAGService": true}}
+
+	res, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "rag.v1.RAGService"})
+	if err != nil || res.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got %v, %v", res, err)
+	}
+	healthy = false
+	res, err = s.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || res.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING, got %v, %v", res, err)
+	}
+	if _, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "other"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown service, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// report is the JSON body of the HTTP endpoints.
+type report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// RegisterHTTP adds the /healthz and /readyz endpoints to mux. /healthz
+// reports that the process is alive and never runs the checks; /readyz
+// returns 503 Service Unavailable while any check fails.
+func RegisterHTTP(mux *http.ServeMux, checker *Checker) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, report{Status: "ok"})
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		failures := checker.Check(r.Context())
+		if len(failures) == 0 {
+			writeReport(w, http.StatusOK, report{Status: "ok"})
+			return
+		}
+		rep := report{Status: "unavailable", Checks: map[string]string{}}
+		for name, err := range failures {
+			rep.Checks[name] = err.Error()
+		}
+		writeReport(w, http.StatusServiceUnavailable, rep)
+	})
+}
+
+// Serve serves the /healthz and /readyz endpoints of checker on addr in the
+// background, for processes without an HTTP server of their own. The
+// returned server, whose Addr is the address listened on, must be shut down
+// by the caller.
+func Serve(addr string, checker *Checker) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	RegisterHTTP(mux, checker)
+	srv := &http.Server{Addr: lis.Addr().String(), Handler: mux}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Health server failed", "error", err)
+		}
+	}()
+	slog.Info("Serving health endpoints", "addr", srv.Addr)
+	return srv, nil
+}
+
+func writeReport(w http.ResponseWriter, code int, rep report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(rep)
+}
@@ -0,0 +1,152 @@
+// Package hedge cuts tail latency by sending a second copy of a call that
+// is slower than usual and keeping whichever answers first.
+package hedge
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Outcome describes how a hedged call went.
+type Outcome struct {
+	// Hedged reports whether a second attempt was sent.
+	Hedged bool
+	// HedgeWon reports whether the result is the second attempt's.
+	HedgeWon bool
+}
+
+// Call runs call, and runs it again if the first attempt has not finished
+// after delay; a delay of 0 or less disables the second attempt. It returns
+// the result of the first attempt to succeed, or the error of the last one
+// to fail, and cancels the context of the attempt still running.
+//
+// An attempt failing before delay is not retried.
+func Call[T any](ctx context.Context, delay time.Duration, call func(ctx context.Context) (T, error)) (T, Outcome, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		res   T
+		err   error
+		hedge bool
+	}
+	// Both attempts can finish without blocking after Call returns.
+	results := make(chan result, 2)
+	start := func(hedge bool) {
+		go func() {
+			res, err := call(ctx)
+			results <- result{res, err, hedge}
+		}()
+	}
+	start(false)
+	running := 1
+
+	var out Outcome
+	var timer <-chan time.Time
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		timer = t.C
+	}
+	for {
+		select {
+		case <-timer:
+			timer = nil
+			out.Hedged = true
+			start(true)
+			running++
+		case r := <-results:
+			running--
+			if r.err != nil && running > 0 {
+				// The other attempt may still succeed.
+				continue
+			}
+			out.HedgeWon = r.err == nil && r.hedge
+			return r.res, out, r.err
+		}
+	}
+}
+
+// Latencies keeps the recent latencies of calls to many backends, such as
+// experts, to pick the delay before hedging a call to each of them.
+type Latencies struct {
+	mu         sync.Mutex
+	window     int
+	minSamples int
+	maxKeys    int
+	keys       map[string]*samples
+	all        *samples
+}
+
+// samples is a ring of the last latencies observed.
+type samples struct {
+	values []time.Duration
+	next   int
+}
+
+func (s *samples) add(d time.Duration, window int) {
+	if len(s.values) < window {
+		s.values = append(s.values, d)
+		return
+	}
+	s.values[s.next] = d
+	s.next = (s.next + 1) % window
+}
+
+func (s *samples) quantile(q float64) time.Duration {
+	sorted := slices.Clone(s.values)
+	slices.Sort(sorted)
+	i := int(q*float64(len(sorted))+0.5) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// NewLatencies returns a Latencies keeping the last window latencies of up
+// to maxKeys backends, and of all backends together. Quantiles need at
+// least minSamples latencies.
+func NewLatencies(window, minSamples, maxKeys int) *Latencies {
+	return &Latencies{
+		window:     window,
+		minSamples: minSamples,
+		maxKeys:    maxKeys,
+		keys:       map[string]*samples{},
+		all:        &samples{},
+	}
+}
+
+// Observe records the latency of a successful call to key.
+func (l *Latencies) Observe(key string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.keys[key]
+	if !ok {
+		if len(l.keys) >= l.maxKeys {
+			// Any backend makes room; the busy ones soon have samples
+			// again.
+			for k := range l.keys {
+				delete(l.keys, k)
+				break
+			}
+		}
+		s = &samples{}
+		l.keys[key] = s
+	}
+	s.add(d, l.window)
+	l.all.add(d, l.window)
+}
+
+// Quantile returns the q quantile of the recent latencies of key, or of all
+// backends if key has too few samples. It reports false if there are too
+// few of either.
+func (l *Latencies) Quantile(key string, q float64) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s := l.keys[key]; s != nil && len(s.values) >= l.minSamples {
+		return s.quantile(q), true
+	}
+	if len(l.all.values) >= max(l.minSamples, 1) {
+		return l.all.quantile(q), true
+	}
+	return 0, false
+}
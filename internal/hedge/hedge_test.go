@@ -0,0 +1,89 @@
+package hedge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCall(t *testing.T) {
+	// A fast call is not hedged.
+	res, out, err := Call(context.Background(), time.Second, func(ctx context.Context) (string, error) {
+		return "fast", nil
+	})
+	if res != "fast" || err != nil || out.Hedged {
+		t.Errorf("Call() = %q, %+v, %v", res, out, err)
+	}
+
+	// A slow first attempt is hedged, and cancelled once the hedge answers.
+	var attempts atomic.Int32
+	cancelled := make(chan struct{})
+	res, out, err = Call(context.Background(), 10*time.Millisecond, func(ctx context.Context) (string, error) {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done()
+			close(cancelled)
+			return "", ctx.Err()
+		}
+		return "hedge", nil
+	})
+	if res != "hedge" || err != nil || !out.Hedged || !out.HedgeWon {
+		t.Errorf("Call() = %q, %+v, %v", res, out, err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the slow attempt to be cancelled")
+	}
+
+	// A failed hedge leaves the first attempt to answer.
+	attempts.Store(0)
+	res, out, err = Call(context.Background(), 10*time.Millisecond, func(ctx context.Context) (string, error) {
+		if attempts.Add(1) == 1 {
+			time.Sleep(50 * time.Millisecond)
+			return "slow", nil
+		}
+		return "", errors.New("hedge failed")
+	})
+	if res != "slow" || err != nil || !out.Hedged || out.HedgeWon {
+		t.Errorf("Call() = %q, %+v, %v", res, out, err)
+	}
+
+	// An early failure is returned without hedging.
+	attempts.Store(0)
+	_, out, err = Call(context.Background(), time.Second, func(ctx context.Context) (string, error) {
+		attempts.Add(1)
+		return "", errors.New("failed")
+	})
+	if err == nil || out.Hedged || attempts.Load() != 1 {
+		t.Errorf("expected a single failed attempt, got %+v, %v after %d attempts", out, err, attempts.Load())
+	}
+}
+
+func TestLatencies(t *testing.T) {
+	l := NewLatencies(10, 5, 2)
+	if _, ok := l.Quantile("a", 0.95); ok {
+		t.Error("expected no quantile without samples")
+	}
+	for i := 1; i <= 20; i++ {
+		l.Observe("a", time.Duration(i)*time.Millisecond)
+	}
+	// Only the last 10 latencies, 11ms to 20ms, are kept.
+	if d, ok := l.Quantile("a", 0.95); !ok || d != 20*time.Millisecond {
+		t.Errorf("Quantile(a, 0.95) = %v, %v", d, ok)
+	}
+	if d, ok := l.Quantile("a", 0.5); !ok || d != 15*time.Millisecond {
+		t.Errorf("Quantile(a, 0.5) = %v, %v", d, ok)
+	}
+	// Backends with few samples get the quantile of all backends.
+	l.Observe("b", time.Hour)
+	if d, ok := l.Quantile("b", 0.5); !ok || d != 16*time.Millisecond {
+		t.Errorf("Quantile(b, 0.5) = %v, %v", d, ok)
+	}
+	// The number of backends is bounded.
+	l.Observe("c", time.Millisecond)
+	if len(l.keys) != 2 {
+		t.Errorf("expected 2 backends, got %d", len(l.keys))
+	}
+}
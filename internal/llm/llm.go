@@ -0,0 +1,57 @@
+// Package llm defines the interface Portal services use to call large
+// language models.
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// Completion is the result of a single LLM call.
+type Completion struct {
+	Text             string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Client generates text from a prompt.
+type Client interface {
+	// Model returns the name of the model, used to label usage metrics.
+	Model() string
+	// Generate returns the model's completion for the prompt.
+	Generate(ctx context.Context, prompt string) (*Completion, error)
+}
+
+// Stub is a deterministic Client that always answers with the same text. It
+// stands in for a real model until one is integrated, and keeps tests and
+// offline runs reproducible.
+// TODO: Add a Gemini client.
+type Stub struct {
+	Answer string
+}
+
+// NewStub returns a Stub that answers with the given text.
+func NewStub(answer string) *Stub {
+	return &Stub{Answer: answer}
+}
+
+// Model implements Client.
+func (s *Stub) Model() string { return "stub" }
+
+// Generate implements Client.
+func (s *Stub) Generate(ctx context.Context, prompt string) (*Completion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Completion{
+		Text:             s.Answer,
+		PromptTokens:     CountTokens(prompt),
+		CompletionTokens: CountTokens(s.Answer),
+	}, nil
+}
+
+// CountTokens estimates the number of tokens in text. Whitespace-separated
+// words are a close enough proxy for usage accounting with the stub.
+func CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
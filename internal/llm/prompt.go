@@ -0,0 +1,26 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers of the content and the question in an expert prompt.
+const (
+	contentMarker  = "\n\nContent:\n"
+	questionMarker = "\n\nQuestion: "
+)
+
+// ExpertPrompt is the prompt asking the model of the expert of url to answer
+// query from content.
+func ExpertPrompt(url, content, query string) string {
+	return fmt.Sprintf("Answer the question using only the content of %s.%s%s%s%s", url, contentMarker, content, questionMarker, query)
+}
+
+// ParseExpertPrompt returns the content and the question of a prompt built
+// by ExpertPrompt.
+func ParseExpertPrompt(prompt string) (content, query string) {
+	_, rest, _ := strings.Cut(prompt, contentMarker)
+	content, query, _ = strings.Cut(rest, questionMarker)
+	return content, query
+}
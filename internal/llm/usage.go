@@ -0,0 +1,31 @@
+package llm
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UsageTrailerKey is the gRPC trailer reporting how many LLM tokens an RPC
+// consumed, including those of the backend calls it made. The API Gateway
+// charges it against the caller's daily quota.
+const UsageTrailerKey = "x-portal-llm-tokens"
+
+// SetUsageTrailer reports the tokens used by the current RPC.
+func SetUsageTrailer(ctx context.Context, tokens int) error {
+	return grpc.SetTrailer(ctx, metadata.Pairs(UsageTrailerKey, strconv.Itoa(tokens)))
+}
+
+// UsageFromTrailer returns the tokens reported in a trailer received from a
+// backend, or 0 if it reported none.
+func UsageFromTrailer(md metadata.MD) int {
+	total := 0
+	for _, v := range md.Get(UsageTrailerKey) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			total += n
+		}
+	}
+	return total
+}
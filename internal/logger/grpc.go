@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor takes the request ID from the incoming metadata, or
+// creates one, stores it in the handler's context and logs each call.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(MetadataKey); len(v) > 0 {
+				id = v[0]
+			}
+		}
+		if id == "" {
+			id = NewRequestID()
+		}
+		ctx = WithRequestID(ctx, id)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		level := slog.LevelInfo
+		attrs := []any{
+			"method", info.FullMethod,
+			"code", status.Code(err).String(),
+			"duration", time.Since(start),
+		}
+		if err != nil {
+			level = slog.LevelWarn
+			attrs = append(attrs, "error", status.Convert(err).Message())
+		}
+		slog.Log(ctx, level, "Handled RPC", attrs...)
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor forwards the request ID in ctx to the called service.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := RequestID(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
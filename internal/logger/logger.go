@@ -1,3 +1,106 @@
+// Package logger provides the structured logger shared by all Portal services
+// and carries a request ID across service boundaries.
+//
+// The API gateway assigns every incoming request an ID (or accepts the
+// caller's X-Request-Id header). The ID travels in the context, in gRPC
+// metadata between services and in NATS message headers between the crawler
+// and the indexing job, and is added to every log record written with a
+// context, so a single search can be followed through the whole system.
 package logger
 
-// TODO: Implement a structured logger (e.g., using slog).
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	// HeaderName is the HTTP and NATS header that carries the request ID.
+	HeaderName = "X-Request-Id"
+	// MetadataKey is the gRPC metadata key that carries the request ID.
+	MetadataKey = "x-request-id"
+	// requestIDKey is the attribute name used in log records.
+	requestIDKey = "request_id"
+)
+
+type ctxKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a new random request ID.
+func NewRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms.
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// New creates the logger for a service and installs it as the slog default.
+// level is one of debug, info, warn or error; format is json or text.
+func New(service, level, format string) (*slog.Logger, error) {
+	return newLogger(os.Stderr, service, level, format)
+}
+
+func newLogger(w io.Writer, service, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("logger: invalid level %q", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("logger: invalid format %q (want json or text)", format)
+	}
+
+	l := slog.New(contextHandler{h}).With("service", service)
+	slog.SetDefault(l)
+	return l, nil
+}
+
+// Fatal logs msg at error level and exits, like log.Fatal.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// contextHandler adds the request ID from the context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(requestIDKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
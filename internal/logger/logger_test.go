@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDInLogRecords(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := newLogger(&buf, "test-service", "debug", "json")
+	if err != nil {
+		t.Fatalf("newLogger() error = %v", err)
+	}
+
+	l.InfoContext(WithRequestID(context.Background(), "abc123"), "hello", "k", "v")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("expected a JSON record, got %q: %v", buf.String(), err)
+	}
+	if rec["request_id"] != "abc123" || rec["service"] != "test-service" || rec["k"] != "v" {
+		t.Errorf("unexpected record: %v", rec)
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	if _, err := newLogger(&bytes.Buffer{}, "s", "loud", "json"); err == nil {
+		t.Error("expected error for invalid level")
+	}
+	if _, err := newLogger(&bytes.Buffer{}, "s", "info", "xml"); err == nil {
+		t.Error("expected error for invalid format")
+	}
+}
+
+func TestGRPCPropagation(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	// The client interceptor puts the ID in the outgoing metadata...
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	ctx := WithRequestID(context.Background(), "req-1")
+	if err := UnaryClientInterceptor()(ctx, "/svc/M", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if got := outgoing.Get(MetadataKey); len(got) != 1 || got[0] != "req-1" {
+		t.Fatalf("expected request ID in outgoing metadata, got %v", outgoing)
+	}
+
+	// ...and the server interceptor restores it in the handler's context.
+	var seen string
+	handler := func(ctx context.Context, req any) (any, error) {
+		seen = RequestID(ctx)
+		return nil, nil
+	}
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	if _, err := UnaryServerInterceptor()(incoming, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/M"}, handler); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "req-1" {
+		t.Errorf("expected handler to see req-1, got %q", seen)
+	}
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	var seen string
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req.Header.Set(HeaderName, "from-client")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if seen != "from-client" || rec.Header().Get(HeaderName) != "from-client" {
+		t.Errorf("expected caller's request ID to be used, got %q / %q", seen, rec.Header().Get(HeaderName))
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search", nil))
+	if seen == "" || rec.Header().Get(HeaderName) != seen {
+		t.Errorf("expected a generated request ID to be echoed, got %q / %q", seen, rec.Header().Get(HeaderName))
+	}
+}
+
+func TestNATSPropagation(t *testing.T) {
+	msg := nats.NewMsg("crawled-content")
+	InjectNATS(WithRequestID(context.Background(), "crawl-1"), msg)
+	if got := RequestID(ExtractNATS(context.Background(), msg)); got != "crawl-1" {
+		t.Errorf("expected crawl-1, got %q", got)
+	}
+	if got := RequestID(ExtractNATS(context.Background(), &nats.Msg{})); got == "" {
+		t.Error("expected a new request ID for a message without headers")
+	}
+}
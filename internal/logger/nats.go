@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// InjectNATS copies the request ID in ctx into the message headers.
+func InjectNATS(ctx context.Context, msg *nats.Msg) {
+	id := RequestID(ctx)
+	if id == "" {
+		return
+	}
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+	msg.Header.Set(HeaderName, id)
+}
+
+// ExtractNATS returns a copy of ctx carrying the request ID from the message
+// headers, or a new one if the publisher did not set it.
+func ExtractNATS(ctx context.Context, msg *nats.Msg) context.Context {
+	id := msg.Header.Get(HeaderName)
+	if id == "" {
+		id = NewRequestID()
+	}
+	return WithRequestID(ctx, id)
+}
@@ -0,0 +1,2 @@
+DROP TABLE experts;
+DROP TYPE expert_type;
@@ -0,0 +1,14 @@
+CREATE TYPE expert_type AS ENUM ('ROOT', 'MIDDLEMAN', 'LEAF');
+
+CREATE TABLE experts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type expert_type NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT UNIQUE,
+    is_rag_based BOOLEAN NOT NULL DEFAULT FALSE,
+    raw_content TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_experts_url ON experts(url);
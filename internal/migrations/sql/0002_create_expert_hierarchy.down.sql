@@ -0,0 +1 @@
+DROP TABLE expert_hierarchy;
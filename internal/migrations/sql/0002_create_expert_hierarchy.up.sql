@@ -0,0 +1,7 @@
+CREATE TABLE expert_hierarchy (
+    parent_expert_id UUID NOT NULL REFERENCES experts(id) ON DELETE CASCADE,
+    child_expert_id UUID NOT NULL REFERENCES experts(id) ON DELETE CASCADE,
+    PRIMARY KEY (parent_expert_id, child_expert_id)
+);
+
+CREATE INDEX idx_expert_hierarchy_parent ON expert_hierarchy(parent_expert_id);
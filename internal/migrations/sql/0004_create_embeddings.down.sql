@@ -0,0 +1,2 @@
+DROP TABLE chunk_embeddings;
+DROP TABLE embedding_models;
@@ -0,0 +1,22 @@
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE embedding_models (
+    model TEXT PRIMARY KEY,
+    dimension INTEGER NOT NULL CHECK (dimension > 0),
+    status TEXT NOT NULL CHECK (status IN ('active', 'backfilling', 'retired')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_embedding_models_active ON embedding_models(status) WHERE status = 'active';
+
+CREATE TABLE chunk_embeddings (
+    chunk_id UUID NOT NULL REFERENCES document_chunks(id) ON DELETE CASCADE,
+    model TEXT NOT NULL REFERENCES embedding_models(model),
+    dimension INTEGER NOT NULL,
+    embedding vector NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chunk_id, model),
+    CHECK (vector_dims(embedding) = dimension)
+);
+
+CREATE INDEX idx_chunk_embeddings_model ON chunk_embeddings(model);
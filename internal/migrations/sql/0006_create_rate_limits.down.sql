@@ -0,0 +1,2 @@
+DROP TABLE quota_usage;
+DROP TABLE rate_limit_buckets;
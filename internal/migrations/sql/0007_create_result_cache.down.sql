@@ -0,0 +1,3 @@
+DROP TABLE result_cache;
+ALTER TABLE experts DROP COLUMN content_version;
+DROP SEQUENCE expert_content_versions;
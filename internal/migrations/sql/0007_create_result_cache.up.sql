@@ -0,0 +1,16 @@
+-- Content versions come from a sequence and change whenever an expert's
+-- content changes, so cached results computed from other content, even
+-- that of a deleted expert with the same URL, are never served.
+CREATE SEQUENCE expert_content_versions;
+ALTER TABLE experts ADD COLUMN content_version BIGINT NOT NULL DEFAULT nextval('expert_content_versions');
+
+CREATE UNLOGGED TABLE result_cache (
+    key TEXT PRIMARY KEY,
+    value BYTEA NOT NULL,
+    -- The URLs of the experts the value was computed from
+    tags TEXT[] NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_result_cache_tags ON result_cache USING GIN (tags);
+CREATE INDEX idx_result_cache_expires_at ON result_cache(expires_at);
@@ -0,0 +1 @@
+ALTER TABLE experts DROP COLUMN language;
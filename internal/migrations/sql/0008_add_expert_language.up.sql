@@ -0,0 +1,5 @@
+-- The language the expert's page declares, as a lowercase BCP 47 tag such as
+-- en or pt-br, or NULL if it declares none.
+ALTER TABLE experts ADD COLUMN language TEXT;
+
+CREATE INDEX idx_experts_language ON experts(language);
@@ -0,0 +1,4 @@
+ALTER TABLE document_chunks
+    DROP COLUMN start_offset,
+    DROP COLUMN end_offset,
+    DROP COLUMN heading;
@@ -0,0 +1,7 @@
+-- Where each chunk comes from in the page's content: the character offsets of
+-- its first and last words, and the heading of the section it starts in. They
+-- are NULL for chunks indexed before they were recorded.
+ALTER TABLE document_chunks
+    ADD COLUMN start_offset INTEGER,
+    ADD COLUMN end_offset INTEGER,
+    ADD COLUMN heading TEXT;
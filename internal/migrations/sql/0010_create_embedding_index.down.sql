@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_chunk_embeddings_hash_v1;
@@ -0,0 +1,6 @@
+-- The vector index of the default embedding space, hash-v1 with 768
+-- dimensions. The re-embedding job creates the index of every model it
+-- backfills, with the same name and definition.
+CREATE INDEX IF NOT EXISTS idx_chunk_embeddings_hash_v1 ON chunk_embeddings
+    USING hnsw ((embedding::vector(768)) vector_cosine_ops)
+    WHERE model = 'hash-v1' AND dimension = 768;
@@ -0,0 +1,3 @@
+ALTER TABLE rate_limit_buckets
+    DROP COLUMN rate,
+    DROP COLUMN burst;
@@ -0,0 +1,6 @@
+-- The limit each bucket refills with, so buckets that have refilled
+-- completely can be pruned. Rows from before are pruned on the first pass,
+-- which is harmless as a missing bucket is a full one.
+ALTER TABLE rate_limit_buckets
+    ADD COLUMN rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN burst INTEGER NOT NULL DEFAULT 0;
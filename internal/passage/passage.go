@@ -0,0 +1,167 @@
+// Package passage locates the parts of a page's content answers are drawn
+// from, so callers can quote the page itself: its sections, the lines of
+// simple experts, and how much of a query or an answer a passage covers.
+//
+// Offsets are in Unicode code points, as the page's text is shown by
+// browsers, and are the same for the RAG service's chunks and for the lines
+// of simple experts.
+package passage
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"portal.com/portal/internal/query"
+	"portal.com/portal/internal/verify"
+)
+
+// Passage is a part of a page's content.
+type Passage struct {
+	Text string
+	// Index is the position of the passage in the page, from 0.
+	Index int
+	// Start and End are the offsets of the passage in the content, in runes;
+	// End is exclusive.
+	Start, End int
+	// Heading is the heading of the section the passage is in, if any.
+	Heading string
+	// Score is how relevant the passage is, from 0 to 1.
+	Score float64
+}
+
+// maxHeadingWords bounds the words of a heading that is not marked up.
+const maxHeadingWords = 8
+
+// Heading returns the heading line is, if it is one. next are the lines
+// following it. The text of pages has no markup, so besides Markdown
+// headings, short lines starting with a capital or a digit, without closing
+// punctuation, followed by a longer line are taken for headings.
+func Heading(line string, next []string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if h, ok := strings.CutPrefix(line, "#"); ok {
+		h = strings.TrimSpace(strings.TrimLeft(h, "#"))
+		return h, h != ""
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 || len(fields) > maxHeadingWords || strings.ContainsAny(line[len(line)-1:], ".,;:!?") {
+		return "", false
+	}
+	if first, _ := utf8.DecodeRuneInString(line); !unicode.IsUpper(first) && !unicode.IsDigit(first) {
+		return "", false
+	}
+	for _, l := range next {
+		if f := strings.Fields(l); len(f) > len(fields) {
+			return strings.Join(fields, " "), true
+		} else if len(f) > 0 {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// Lines returns the lines of content that are neither blank nor headings, as
+// passages under the heading before them. Index is the line number, from 0.
+func Lines(content string) []Passage {
+	lines := strings.Split(content, "\n")
+	var passages []Passage
+	heading := ""
+	offset := 0
+	for i, line := range lines {
+		start := offset
+		offset += utf8.RuneCountInString(line) + 1
+		if h, ok := Heading(line, lines[i+1:]); ok {
+			heading = h
+			continue
+		}
+		text := strings.TrimSpace(line)
+		if text == "" {
+			continue
+		}
+		start += utf8.RuneCountInString(line[:strings.Index(line, text)])
+		passages = append(passages, Passage{
+			Text:    text,
+			Index:   i,
+			Start:   start,
+			End:     start + utf8.RuneCountInString(text),
+			Heading: heading,
+		})
+	}
+	return passages
+}
+
+// Overlap returns the share of the keywords of q that text contains, from 0
+// to 1. It is 0 if q has no keywords.
+func Overlap(text, q string) float64 {
+	want := strings.Fields(query.Keywords(q))
+	if len(want) == 0 {
+		return 0
+	}
+	have := map[string]bool{}
+	for _, w := range strings.Fields(query.Keywords(text)) {
+		have[w] = true
+	}
+	matched := 0
+	for _, w := range want {
+		if have[w] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(want))
+}
+
+// Rank scores passages by their Overlap with q and returns the n best, most
+// relevant first, leaving out those without a keyword of q. If none has one,
+// the first passage is returned, so an answer always has a source.
+func Rank(passages []Passage, q string, n int) []Passage {
+	var ranked []Passage
+	for _, p := range passages {
+		if p.Score = Overlap(p.Text, q); p.Score > 0 {
+			ranked = append(ranked, p)
+		}
+	}
+	if len(ranked) == 0 {
+		return passages[:min(len(passages), 1)]
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	return ranked[:min(len(ranked), n)]
+}
+
+// Snippet quotes the passages where they back answer best: from the sentence
+// sharing the most keywords with answer, as many of the sentences following
+// it in its passage as fit in max bytes. A first sentence longer than max is
+// cut at a word. It returns "" if there are no passages.
+func Snippet(passages []string, answer string, max int) string {
+	var best []string
+	bestScore := -1.0
+	for _, p := range passages {
+		sentences := verify.Sentences(p)
+		for i, s := range sentences {
+			if score := Overlap(s, answer); score > bestScore {
+				best, bestScore = sentences[i:], score
+			}
+		}
+	}
+	if len(best) == 0 {
+		return ""
+	}
+	snippet := best[0]
+	if len(snippet) > max {
+		cut := strings.LastIndex(snippet[:max], " ")
+		if cut <= 0 {
+			cut = max
+			for cut > 0 && !utf8.RuneStart(snippet[cut]) {
+				cut--
+			}
+		}
+		return strings.TrimRight(snippet[:cut], " ,;:") + "…"
+	}
+	for _, s := range best[1:] {
+		if len(snippet)+1+len(s) > max {
+			break
+		}
+		snippet += " " + s
+	}
+	return snippet
+}
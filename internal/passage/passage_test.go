@@ -0,0 +1,84 @@
+package passage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const content = `Colly
+Élégant scraping framework for Go.
+
+## Install
+  Run go get to add the module.
+Colly requires Go 1.20.`
+
+func TestHeading(t *testing.T) {
+	tests := []struct {
+		line, next string
+		want       string
+		ok         bool
+	}{
+		{"## Install it ", "", "Install it", true},
+		{"#", "Some text here.", "", false},
+		{"Getting started", "Colly is a scraping framework.", "Getting started", true},
+		{"getting started", "Colly is a scraping framework.", "", false},
+		{"Getting started:", "Colly is a scraping framework.", "", false},
+		{"Getting started", "Go", "", false},
+		{"Getting started", "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Heading(tt.line, []string{"", tt.next})
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Heading(%q, %q) = %q, %v, want %q, %v", tt.line, tt.next, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	got := Lines(content)
+	want := []Passage{
+		{Text: "Élégant scraping framework for Go.", Index: 1, Start: 6, End: 40, Heading: "Colly"},
+		{Text: "Run go get to add the module.", Index: 4, Start: 55, End: 84, Heading: "Install"},
+		{Text: "Colly requires Go 1.20.", Index: 5, Start: 85, End: 108, Heading: "Install"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lines() =\n%+v\nwant\n%+v", got, want)
+	}
+	runes := []rune(content)
+	for _, p := range got {
+		if s := string(runes[p.Start:p.End]); s != p.Text {
+			t.Errorf("content[%d:%d] = %q, want %q", p.Start, p.End, s, p.Text)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	lines := Lines(content)
+	got := Rank(lines, "how do I run go get?", 2)
+	if len(got) != 2 || got[0].Index != 4 || got[0].Score != 1 || got[1].Index != 1 {
+		t.Errorf("Rank() = %+v, want the go get line, then the first other one mentioning Go", got)
+	}
+	if got := Rank(lines, "pricing", 2); len(got) != 1 || got[0].Index != 1 || got[0].Score != 0 {
+		t.Errorf("Rank() without a match = %+v, want the first line", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	passages := []string{
+		"Colly is a scraping framework. It is fast. It supports caching.",
+		"Install Colly with go get. Then import it. Requires Go 1.20.",
+	}
+	if got := Snippet(passages, "You install it with go get.", 50); got != "Install Colly with go get. Then import it." {
+		t.Errorf("Snippet() = %q", got)
+	}
+	if got := Snippet(passages, "Install Colly with go get, then import it.", 20); got != "Install Colly with…" {
+		t.Errorf("Snippet() cut = %q", got)
+	}
+	if got := Snippet(nil, "answer", 60); got != "" {
+		t.Errorf("Snippet() without passages = %q, want empty", got)
+	}
+	if got := Snippet([]string{strings.Repeat("é", 30)}, "answer", 9); got != "éééé…" {
+		t.Errorf("Snippet() of one long word = %q", got)
+	}
+}
@@ -0,0 +1,266 @@
+// Package query works out what a search query asks before experts are
+// consulted: its intent, the questions a compound query is made of, and
+// rewrites of them that retrieve better than the user's wording.
+//
+// Queries are analyzed by keyword rules.
+package query
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"portal.com/portal/internal/cache"
+)
+
+// Intent is what a query is after.
+type Intent string
+
+const (
+	// Navigational queries look for a specific site or page.
+	Navigational Intent = "navigational"
+	// Factual queries ask for a specific fact.
+	Factual Intent = "factual"
+	// Comparative queries compare several things.
+	Comparative Intent = "comparative"
+	// Exploratory queries ask for an overview of a topic.
+	Exploratory Intent = "exploratory"
+)
+
+// maxSubQueries bounds the sub-queries of a query.
+const maxSubQueries = 5
+
+// Analysis is what is understood of a query.
+type Analysis struct {
+	Intent Intent
+	// SubQueries are the questions a compound query asks, or the things a
+	// comparative query compares, in order. A simple query is its own only
+	// sub-query.
+	SubQueries []string
+	// Rewrites are keyword forms of the sub-queries, without question words
+	// and filler, that differ from them.
+	Rewrites []string
+	// Target is the domain a navigational query names, or empty.
+	Target string
+	// Analyzer names what analyzed the query, such as "rules".
+	Analyzer string
+}
+
+// RetrievalQueries returns the forms of query worth retrieving context for
+// besides query itself: the sub-queries of a compound query and the
+// rewrites.
+func (a *Analysis) RetrievalQueries(query string) []string {
+	seen := map[string]bool{cache.NormalizeQuery(query): true}
+	var queries []string
+	add := func(q string) {
+		if n := cache.NormalizeQuery(q); n != "" && !seen[n] {
+			seen[n] = true
+			queries = append(queries, q)
+		}
+	}
+	if len(a.SubQueries) > 1 {
+		for _, q := range a.SubQueries {
+			add(q)
+		}
+	}
+	for _, q := range a.Rewrites {
+		add(q)
+	}
+	return queries
+}
+
+// Analyzer analyzes queries.
+type Analyzer interface {
+	// Analyze returns the analysis of query. It does not fail: an analyzer
+	// that cannot do better returns the analysis of the rules.
+	Analyze(ctx context.Context, query string) *Analysis
+}
+
+// Rules analyzes English queries with keyword rules. It is fast and free,
+// but only recognises the common ways of phrasing each intent.
+type Rules struct{}
+
+var (
+	// questionWords start questions.
+	questionWords = words("who what when where which why how whose whom is are was were do does did can could should would will")
+	// stopWords carry no meaning for retrieval.
+	stopWords = words(`a an the of to in on at for from with by about into as and or but
+		i me my we our you your it its this that these those there here
+		be been being am has have had please tell show give explain find
+		who what when where which why how whose whom is are was were do does did can could should would will`)
+	// exploratoryPhrases ask for an overview rather than a fact.
+	exploratoryPhrases = []string{"overview", "introduction", "tell me about", "learn about", "explain",
+		"guide", "tutorial", "ideas", "examples of", "ways to", "best practices", "resources"}
+	// navigationalPhrases ask for a site rather than its content.
+	navigationalPhrases = []string{"go to", "open", "visit", "navigate to", "homepage", "home page",
+		"official site", "official website", "website", "login page", "docs site"}
+
+	comparativePattern = regexp.MustCompile(`(?i)\b(vs\.?|versus|compared? (to|with)|comparison|differences? between|better than|worse than|pros and cons|which is (better|faster|best))\b|^compare\b`)
+	// comparedPattern separates the things compared.
+	comparedPattern = regexp.MustCompile(`(?i)\s+(?:vs\.?|versus|compared (?:to|with)|(?:better|worse|faster|slower) than|or)\s+`)
+	domainPattern   = regexp.MustCompile(`^(?:https?://)?((?:[a-z0-9-]+\.)+[a-z]{2,})(?:[/:?#].*)?$`)
+)
+
+func words(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+// Analyze implements Analyzer.
+func (Rules) Analyze(ctx context.Context, query string) *Analysis {
+	query = strings.Join(strings.Fields(query), " ")
+	lower := strings.ToLower(query)
+	a := &Analysis{Analyzer: "rules", Target: Target(query)}
+	question := strings.HasSuffix(query, "?") || questionWords[firstWord(lower)]
+	switch {
+	case comparativePattern.MatchString(lower):
+		a.Intent = Comparative
+		a.SubQueries = compared(query)
+	case a.Target != "" && (containsAny(lower, navigationalPhrases) || !question && len(strings.Fields(query)) <= 3):
+		a.Intent = Navigational
+	case containsAny(lower, exploratoryPhrases):
+		a.Intent = Exploratory
+	case question:
+		a.Intent = Factual
+	default:
+		a.Intent = Exploratory
+	}
+	if len(a.SubQueries) < 2 {
+		a.SubQueries = split(query)
+	}
+	if len(a.SubQueries) > maxSubQueries {
+		a.SubQueries = a.SubQueries[:maxSubQueries]
+	}
+	for _, q := range a.SubQueries {
+		if k := Keywords(q); k != "" && k != cache.NormalizeQuery(q) && !slices.Contains(a.Rewrites, k) {
+			a.Rewrites = append(a.Rewrites, k)
+		}
+	}
+	return a
+}
+
+// Target returns the domain named in query, e.g. "gocolly.dev" for "open
+// https://gocolly.dev/docs", or empty.
+func Target(query string) string {
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		w = strings.TrimRight(w, ".,;!?)")
+		if m := domainPattern.FindStringSubmatch(w); m != nil {
+			return strings.TrimPrefix(m[1], "www.")
+		}
+	}
+	return ""
+}
+
+// Keywords returns the words of query that matter for retrieval, lowercased
+// and without punctuation around them.
+func Keywords(query string) string {
+	var kept []string
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		// Punctuation inside words, as in node.js or c++, is kept.
+		w = strings.TrimFunc(w, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+		})
+		if w != "" && !stopWords[w] {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// split returns the questions of a compound query: sentences, and clauses
+// joined by "and" or "also" that start with a question word, as in "what is
+// colly and how do I install it".
+func split(query string) []string {
+	var parts []string
+	start := 0
+	for i, r := range query {
+		if (r == '?' || r == ';') && i+1 < len(query) && query[i+1] == ' ' {
+			parts = append(parts, strings.TrimSuffix(query[start:i+1], ";"))
+			start = i + 2
+		}
+	}
+	parts = append(parts, query[start:])
+
+	var questions []string
+	for _, part := range parts {
+		fields := strings.Fields(part)
+		begin := 0
+		for i := 1; i+1 < len(fields); i++ {
+			conj := strings.ToLower(fields[i])
+			if (conj == "and" || conj == "also") && i > begin && questionWords[strings.ToLower(fields[i+1])] {
+				questions = append(questions, strings.Join(fields[begin:i], " "))
+				begin = i + 1
+			}
+		}
+		questions = append(questions, strings.Join(fields[begin:], " "))
+	}
+	questions = slices.DeleteFunc(questions, func(q string) bool { return strings.Trim(q, "?; ") == "" })
+	if len(questions) == 0 {
+		return []string{query}
+	}
+	return questions
+}
+
+// compared returns the things a comparative query compares, e.g. "colly"
+// and "scrapy" for "what is the difference between colly and scrapy?", or
+// nil if they cannot be told apart.
+func compared(query string) []string {
+	q := strings.TrimRight(query, "?!. ")
+	lower := strings.ToLower(q)
+	var things []string
+	switch {
+	case strings.Contains(lower, " between "):
+		i := strings.Index(lower, " between ")
+		things = splitAnd(q[i+len(" between "):])
+	case strings.HasPrefix(lower, "compare "):
+		things = splitAnd(q[len("compare "):])
+	default:
+		// A question comes before the things compared, as in "which is
+		// faster, colly or scrapy".
+		if _, rest, ok := strings.Cut(q, ", "); ok && questionWords[firstWord(lower)] {
+			q = rest
+		}
+		things = comparedPattern.Split(q, -1)
+	}
+	var kept []string
+	for _, t := range things {
+		fields := strings.Fields(t)
+		// The first thing may follow a question, as in "is colly better
+		// than scrapy".
+		for len(kept) == 0 && len(fields) > 1 && questionWords[strings.ToLower(fields[0])] {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 {
+			kept = append(kept, strings.Join(fields, " "))
+		}
+	}
+	if len(kept) < 2 {
+		return nil
+	}
+	return kept
+}
+
+var andPattern = regexp.MustCompile(`(?i),?\s+(?:and|with|to)\s+|,\s+`)
+
+func splitAnd(s string) []string {
+	return andPattern.Split(s, -1)
+}
+
+func firstWord(s string) string {
+	w, _, _ := strings.Cut(s, " ")
+	return w
+}
+
+func containsAny(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.HasPrefix(s, p+" ") || strings.Contains(s, " "+p+" ") || strings.HasSuffix(s, " "+p) || s == p {
+			return true
+		}
+	}
+	return false
+}
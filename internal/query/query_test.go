@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		query      string
+		intent     Intent
+		subQueries []string
+		rewrites   []string
+		target     string
+	}{
+		{"gocolly.dev", Navigational, []string{"gocolly.dev"}, nil, "gocolly.dev"},
+		{"open the https://www.gocolly.dev/docs website", Navigational, []string{"open the https://www.gocolly.dev/docs website"}, []string{"open https://www.gocolly.dev/docs website"}, "gocolly.dev"},
+		{"What license is Colly released under?", Factual, []string{"What license is Colly released under?"}, []string{"license colly released under"}, ""},
+		{"what is colly and how do I install it", Factual, []string{"what is colly", "how do I install it"}, []string{"colly", "install"}, ""},
+		{"Who wrote colly? When was it released?", Factual, []string{"Who wrote colly?", "When was it released?"}, []string{"wrote colly", "released"}, ""},
+		{"colly vs scrapy for crawling", Comparative, []string{"colly", "scrapy for crawling"}, []string{"scrapy crawling"}, ""},
+		{"What is the difference between Colly, Scrapy and Puppeteer?", Comparative, []string{"Colly", "Scrapy", "Puppeteer"}, nil, ""},
+		{"Which is faster, colly or goquery?", Comparative, []string{"colly", "goquery"}, nil, ""},
+		{"is colly better than scrapy", Comparative, []string{"colly", "scrapy"}, nil, ""},
+		{"web scraping in go", Exploratory, []string{"web scraping in go"}, []string{"web scraping go"}, ""},
+		{"tell me about web scraping", Exploratory, []string{"tell me about web scraping"}, []string{"web scraping"}, ""},
+	}
+	for _, tt := range tests {
+		a := Rules{}.Analyze(context.Background(), tt.query)
+		if a.Intent != tt.intent || !slices.Equal(a.SubQueries, tt.subQueries) || !slices.Equal(a.Rewrites, tt.rewrites) || a.Target != tt.target || a.Analyzer != "rules" {
+			t.Errorf("Analyze(%q) = %+v", tt.query, a)
+		}
+	}
+}
+
+func TestRetrievalQueries(t *testing.T) {
+	a := &Analysis{SubQueries: []string{"what is colly", "how do I install it"}, Rewrites: []string{"colly", "install"}}
+	got := a.RetrievalQueries("What is colly and how do I install it?")
+	if want := []string{"what is colly", "how do I install it", "colly", "install"}; !slices.Equal(got, want) {
+		t.Errorf("RetrievalQueries() = %q, want %q", got, want)
+	}
+	a = &Analysis{SubQueries: []string{"colly?"}, Rewrites: []string{"Colly"}}
+	if got := a.RetrievalQueries("colly?"); len(got) != 0 {
+		t.Errorf("expected no other forms of a simple query, got %q", got)
+	}
+}
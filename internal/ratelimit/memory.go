@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often the stores drop full buckets and past days.
+const pruneInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  int
+}
+
+// MemoryStore keeps buckets and quotas in the process. Limits are not shared
+// between replicas and reset when the process restarts.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	usage   map[string]map[string]int64 // day -> key -> tokens
+	pruned  time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		usage:   map[string]map[string]int64{},
+		now:     time.Now,
+	}
+}
+
+// Take implements Store.
+func (m *MemoryStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.prune(now)
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		m.buckets[key] = b
+	}
+	b.rate, b.burst = rate, burst
+
+	available, wait := refill(b.tokens, now.Sub(b.last), rate, burst)
+	b.tokens, b.last = available, now
+	if wait > 0 {
+		return false, wait, nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// Usage implements Store.
+func (m *MemoryStore) Usage(ctx context.Context, key, day string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage[day][key], nil
+}
+
+// AddUsage implements Store.
+func (m *MemoryStore) AddUsage(ctx context.Context, key, day string, tokens int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.usage[day] == nil {
+		m.usage[day] = map[string]int64{}
+	}
+	m.usage[day][key] += tokens
+	return nil
+}
+
+// prune drops buckets that have refilled completely, which behave exactly
+// like new ones, and the usage of days before yesterday.
+func (m *MemoryStore) prune(now time.Time) {
+	if now.Sub(m.pruned) < pruneInterval {
+		return
+	}
+	m.pruned = now
+	for key, b := range m.buckets {
+		if available, _ := refill(b.tokens, now.Sub(b.last), b.rate, b.burst); available >= float64(b.burst) {
+			delete(m.buckets, key)
+		}
+	}
+	oldest := day(now.UTC().AddDate(0, 0, -1))
+	for d := range m.usage {
+		if d < oldest {
+			delete(m.usage, d)
+		}
+	}
+}
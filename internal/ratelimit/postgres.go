@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table and quotas in
+// the quota_usage table, so every gateway replica sees the same limits. Each
+// request is a single atomic statement, and the database clock is used so
+// replicas with skewed clocks agree.
+type PostgresStore struct {
+	db *sql.DB
+
+	mu     sync.Mutex
+	pruned time.Time
+	now    func() time.Time
+}
+
+// NewPostgresStore returns a Store backed by db.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db, now: time.Now}
+}
+
+// Take implements Store. The bucket is refilled and decremented in one
+// upsert, which only changes the row when a request is available.
+func (p *PostgresStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	p.prune(ctx)
+	var remaining float64
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at, rate, burst)
+		VALUES ($1, $3::float8 - 1, NOW(), $2, $3)
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = LEAST($3::float8, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - b.updated_at)) * $2::float8) - 1,
+			updated_at = NOW(), rate = $2, burst = $3
+		WHERE LEAST($3::float8, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - b.updated_at)) * $2::float8) >= 1
+		RETURNING tokens`, key, rate, burst).Scan(&remaining)
+	if err == nil {
+		return true, 0, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, 0, err
+	}
+
+	// The bucket is empty; work out when the next request is available.
+	var tokens, elapsed float64
+	err = p.db.QueryRowContext(ctx,
+		`SELECT tokens, EXTRACT(EPOCH FROM NOW() - updated_at) FROM rate_limit_buckets WHERE key = $1`, key).Scan(&tokens, &elapsed)
+	if err != nil {
+		return false, 0, err
+	}
+	_, wait := refill(tokens, time.Duration(elapsed*float64(time.Second)), rate, burst)
+	return false, wait, nil
+}
+
+// Usage implements Store.
+func (p *PostgresStore) Usage(ctx context.Context, key, day string) (int64, error) {
+	var tokens int64
+	err := p.db.QueryRowContext(ctx,
+		`SELECT tokens FROM quota_usage WHERE key = $1 AND day = $2`, key, day).Scan(&tokens)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return tokens, err
+}
+
+// AddUsage implements Store.
+func (p *PostgresStore) AddUsage(ctx context.Context, key, day string, tokens int64) error {
+	_, err := p.db.ExecContext(ctx, `
+		INSERT INTO quota_usage (key, day, tokens) VALUES ($1, $2, $3)
+		ON CONFLICT (key, day) DO UPDATE SET tokens = quota_usage.tokens + EXCLUDED.tokens`,
+		key, day, tokens)
+	return err
+}
+
+// prune deletes buckets that have refilled completely, which behave exactly
+// like new ones, and the usage of days before yesterday, at most once per
+// pruneInterval. A failure is logged and retried on the next interval, since
+// the rows left behind do not change any limit.
+func (p *PostgresStore) prune(ctx context.Context) {
+	p.mu.Lock()
+	now := p.now()
+	if now.Sub(p.pruned) < pruneInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.pruned = now
+	p.mu.Unlock()
+
+	_, err := p.db.ExecContext(ctx, `
+		DELETE FROM rate_limit_buckets
+		WHERE tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - updated_at)) * rate >= burst`)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to prune rate limit buckets", "error", err)
+	}
+	oldest := day(now.UTC().AddDate(0, 0, -1))
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM quota_usage WHERE day < $1`, oldest); err != nil {
+		slog.WarnContext(ctx, "Failed to prune quota usage", "error", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package ratelimit limits how often clients may call the API Gateway and how
+// many LLM tokens they may consume per day.
+//
+// Request rates are limited with token buckets: a bucket holds up to Burst
+// requests and refills at Rate requests per second. Daily quotas count the
+// LLM tokens reported by the backends and reset at midnight UTC. State lives
+// in a Store, either in memory for a single gateway or in Postgres so that
+// several replicas share the limits.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Store keeps the state of the buckets and quotas.
+type Store interface {
+	// Take removes one request from the bucket for key, which refills at
+	// rate requests per second up to burst. If the bucket is empty it
+	// returns false and the time until a request is available.
+	Take(ctx context.Context, key string, rate float64, burst int) (ok bool, retryAfter time.Duration, err error)
+	// Usage returns the LLM tokens charged to key on day (YYYY-MM-DD, UTC).
+	Usage(ctx context.Context, key, day string) (int64, error)
+	// AddUsage charges tokens to key on day.
+	AddUsage(ctx context.Context, key, day string, tokens int64) error
+}
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate per
+// second. A zero Rate disables the limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Config holds the limits applied by a Limiter.
+type Config struct {
+	// Key limits requests authenticated with an API key or token, per
+	// subject.
+	Key Limit
+	// IP limits anonymous requests, per client IP address.
+	IP Limit
+	// DailyTokens is the number of LLM tokens each client may consume per
+	// UTC day. Zero disables the quota.
+	DailyTokens int64
+}
+
+// Limiter applies a Config using a Store.
+type Limiter struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+}
+
+// New returns a Limiter.
+func New(store Store, cfg Config) *Limiter {
+	return &Limiter{store: store, cfg: cfg, now: time.Now}
+}
+
+// Decision is the outcome of Allow.
+type Decision struct {
+	Allowed bool
+	// RetryAfter is how long the client should wait when not allowed.
+	RetryAfter time.Duration
+	// Reason explains a rejection.
+	Reason string
+}
+
+// ClientKey returns the key a client is limited under: its subject when it
+// is authenticated, or its IP address otherwise.
+func ClientKey(subject, ip string) string {
+	if subject != "" {
+		return "subject:" + subject
+	}
+	return "ip:" + ip
+}
+
+// Allow decides whether the client may make a request now. authenticated
+// selects between the per-key and the per-IP limit.
+func (l *Limiter) Allow(ctx context.Context, client string, authenticated bool) (Decision, error) {
+	if l.cfg.DailyTokens > 0 {
+		now := l.now().UTC()
+		used, err := l.store.Usage(ctx, client, day(now))
+		if err != nil {
+			return Decision{}, fmt.Errorf("ratelimit: failed to read usage: %w", err)
+		}
+		if used >= l.cfg.DailyTokens {
+			midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			return Decision{
+				RetryAfter: midnight.Sub(now),
+				Reason:     fmt.Sprintf("daily quota of %d LLM tokens exceeded", l.cfg.DailyTokens),
+			}, nil
+		}
+	}
+
+	limit := l.cfg.IP
+	if authenticated {
+		limit = l.cfg.Key
+	}
+	if limit.Rate <= 0 {
+		return Decision{Allowed: true}, nil
+	}
+	ok, retryAfter, err := l.store.Take(ctx, client, limit.Rate, limit.Burst)
+	if err != nil {
+		return Decision{}, fmt.Errorf("ratelimit: failed to take from bucket: %w", err)
+	}
+	if !ok {
+		return Decision{RetryAfter: retryAfter, Reason: "rate limit exceeded"}, nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// Charge records LLM tokens consumed by the client's request.
+func (l *Limiter) Charge(ctx context.Context, client string, tokens int) error {
+	if l.cfg.DailyTokens <= 0 || tokens <= 0 {
+		return nil
+	}
+	if err := l.store.AddUsage(ctx, client, day(l.now().UTC()), int64(tokens)); err != nil {
+		return fmt.Errorf("ratelimit: failed to record usage: %w", err)
+	}
+	return nil
+}
+
+func day(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// refill returns the requests available in a bucket that held tokens at
+// last, and the wait until one request is available.
+func refill(tokens float64, elapsed time.Duration, rate float64, burst int) (available float64, wait time.Duration) {
+	available = math.Min(float64(burst), tokens+math.Max(0, elapsed.Seconds())*rate)
+	if available >= 1 {
+		return available, 0
+	}
+	return available, time.Duration((1 - available) / rate * float64(time.Second))
+}
@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// fakeClock is a controllable time source.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.now
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := store.Take(ctx, "k", 1, 3); !ok {
+			t.Fatalf("request %d within the burst was rejected", i)
+		}
+	}
+	ok, retryAfter, _ := store.Take(ctx, "k", 1, 3)
+	if ok || retryAfter != time.Second {
+		t.Fatalf("expected rejection with a 1s retry, got %v %v", ok, retryAfter)
+	}
+	if ok, _, _ := store.Take(ctx, "other", 1, 3); !ok {
+		t.Error("buckets of different keys must be independent")
+	}
+
+	clock.advance(time.Second)
+	if ok, _, _ := store.Take(ctx, "k", 1, 3); !ok {
+		t.Error("expected a request to be available after refilling")
+	}
+}
+
+func TestLimiterDailyQuota(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 1, 2, 23, 0, 0, 0, time.UTC)}
+	l := New(NewMemoryStore(), Config{DailyTokens: 100})
+	l.now = clock.now
+	ctx := context.Background()
+
+	if d, _ := l.Allow(ctx, "ip:1.2.3.4", false); !d.Allowed {
+		t.Fatalf("expected the first request to be allowed, got %+v", d)
+	}
+	if err := l.Charge(ctx, "ip:1.2.3.4", 100); err != nil {
+		t.Fatal(err)
+	}
+	d, _ := l.Allow(ctx, "ip:1.2.3.4", false)
+	if d.Allowed || d.RetryAfter != time.Hour {
+		t.Fatalf("expected the quota to be exhausted until midnight, got %+v", d)
+	}
+
+	clock.advance(time.Hour)
+	if d, _ := l.Allow(ctx, "ip:1.2.3.4", false); !d.Allowed {
+		t.Errorf("expected the quota to reset at midnight UTC, got %+v", d)
+	}
+}
+
+func TestLimiterSelectsLimit(t *testing.T) {
+	l := New(NewMemoryStore(), Config{
+		Key: Limit{Rate: 1, Burst: 2},
+		IP:  Limit{Rate: 1, Burst: 1},
+	})
+	ctx := context.Background()
+
+	l.Allow(ctx, ClientKey("", "1.2.3.4"), false)
+	if d, _ := l.Allow(ctx, ClientKey("", "1.2.3.4"), false); d.Allowed {
+		t.Error("expected the IP limit to apply to anonymous clients")
+	}
+	l.Allow(ctx, ClientKey("team-a", "1.2.3.4"), true)
+	if d, _ := l.Allow(ctx, ClientKey("team-a", "1.2.3.4"), true); !d.Allowed {
+		t.Error("expected the larger key limit to apply to authenticated clients")
+	}
+}
+
+func TestPostgresStoreTake(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewPostgresStore(db)
+	// Pruning is covered by TestPostgresStorePrune.
+	store.pruned = store.now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO rate_limit_buckets AS b`)).
+		WithArgs("k", 2.0, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"tokens"}).AddRow(3.0))
+	if ok, _, err := store.Take(context.Background(), "k", 2, 4); !ok || err != nil {
+		t.Fatalf("expected the request to be allowed, got %v %v", ok, err)
+	}
+
+	// An empty bucket updates no row; the wait is derived from its state.
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO rate_limit_buckets AS b`)).
+		WithArgs("k", 2.0, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"tokens"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT tokens, EXTRACT(EPOCH FROM NOW() - updated_at) FROM rate_limit_buckets`)).
+		WithArgs("k").
+		WillReturnRows(sqlmock.NewRows([]string{"tokens", "elapsed"}).AddRow(0.0, 0.25))
+	ok, retryAfter, err := store.Take(context.Background(), "k", 2, 4)
+	if ok || err != nil || retryAfter != 250*time.Millisecond {
+		t.Fatalf("expected a 250ms wait, got %v %v %v", ok, retryAfter, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresStorePrune(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	clock := &fakeClock{t: time.Date(2026, 1, 3, 0, 30, 0, 0, time.UTC)}
+	store := NewPostgresStore(db)
+	store.now = clock.now
+
+	take := func() {
+		t.Helper()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO rate_limit_buckets AS b`)).
+			WithArgs("k", 2.0, 4).
+			WillReturnRows(sqlmock.NewRows([]string{"tokens"}).AddRow(3.0))
+		if ok, _, err := store.Take(context.Background(), "k", 2, 4); !ok || err != nil {
+			t.Fatalf("expected the request to be allowed, got %v %v", ok, err)
+		}
+	}
+	expectPrune := func(oldest string) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM rate_limit_buckets`)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM quota_usage WHERE day < $1`)).
+			WithArgs(oldest).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	// The first request prunes, the next ones within pruneInterval do not.
+	expectPrune("2026-01-02")
+	take()
+	clock.advance(pruneInterval / 2)
+	take()
+	clock.advance(pruneInterval)
+	expectPrune("2026-01-02")
+	take()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"portal.com/portal/internal/logger"
+)
+
+// ErrorBody is the JSON body of every error response.
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// httpStatuses maps gRPC codes to HTTP statuses and to the names used in
+// error bodies.
+var httpStatuses = map[codes.Code]struct {
+	status int
+	name   string
+}{
+	codes.Canceled:           {499, "CANCELLED"},
+	codes.Unknown:            {http.StatusInternalServerError, "UNKNOWN"},
+	codes.InvalidArgument:    {http.StatusBadRequest, "INVALID_ARGUMENT"},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, "DEADLINE_EXCEEDED"},
+	codes.NotFound:           {http.StatusNotFound, "NOT_FOUND"},
+	codes.AlreadyExists:      {http.StatusConflict, "ALREADY_EXISTS"},
+	codes.PermissionDenied:   {http.StatusForbidden, "PERMISSION_DENIED"},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, "RESOURCE_EXHAUSTED"},
+	codes.FailedPrecondition: {http.StatusBadRequest, "FAILED_PRECONDITION"},
+	codes.Aborted:            {http.StatusConflict, "ABORTED"},
+	codes.OutOfRange:         {http.StatusBadRequest, "OUT_OF_RANGE"},
+	codes.Unimplemented:      {http.StatusNotImplemented, "UNIMPLEMENTED"},
+	codes.Internal:           {http.StatusInternalServerError, "INTERNAL"},
+	codes.Unavailable:        {http.StatusServiceUnavailable, "UNAVAILABLE"},
+	codes.DataLoss:           {http.StatusInternalServerError, "DATA_LOSS"},
+	codes.Unauthenticated:    {http.StatusUnauthorized, "UNAUTHENTICATED"},
+}
+
+// WriteError writes a JSON error body with the given HTTP status.
+func WriteError(w http.ResponseWriter, r *http.Request, httpStatus int, code codes.Code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(ErrorBody{
+		Code:      httpStatuses[code].name,
+		Message:   message,
+		RequestID: logger.RequestID(r.Context()),
+	})
+}
+
+// WriteRPCError writes the error returned by a backend service, with the
+// HTTP status matching its gRPC code. Details of server-side failures are
+// logged rather than returned to the caller.
+func WriteRPCError(w http.ResponseWriter, r *http.Request, method string, err error) {
+	st := status.Convert(err)
+	code := st.Code()
+	if _, ok := httpStatuses[code]; !ok {
+		code = codes.Unknown
+	}
+	message := st.Message()
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		slog.ErrorContext(r.Context(), "Error from backend service", "method", method, "code", code.String(), "error", st.Message())
+		message = "internal error"
+	default:
+		slog.WarnContext(r.Context(), "Error from backend service", "method", method, "code", code.String(), "error", st.Message())
+	}
+	WriteError(w, r, httpStatuses[code].status, code, message)
+}
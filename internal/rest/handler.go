@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// maxBodyBytes matches the default maximum message size of a gRPC server,
+// so any body accepted here can be forwarded.
+const maxBodyBytes = 4 << 20
+
+var marshalOptions = protojson.MarshalOptions{EmitUnpopulated: true}
+
+// Handler serves a Route by calling its RPC on Conn.
+type Handler struct {
+	Route Route
+	Conn  grpc.ClientConnInterface
+	// OnTrailer, if set, is called with the trailer of every call, including
+	// failed ones.
+	OnTrailer func(r *http.Request, trailer metadata.MD)
+	// Status, if set, returns the HTTP status of a successful response and
+	// may set headers for it. Responses are 200 OK otherwise.
+	Status func(res proto.Message, header http.Header) int
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := newMessage(h.Route.RPC.Input())
+	if err := h.decode(r, req); err != nil {
+		WriteError(w, r, http.StatusBadRequest, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	res := newMessage(h.Route.RPC.Output())
+	var trailer metadata.MD
+	err := h.Conn.Invoke(r.Context(), h.Route.FullMethod(), req, res, grpc.Trailer(&trailer))
+	if h.OnTrailer != nil {
+		h.OnTrailer(r, trailer)
+	}
+	if err != nil {
+		WriteRPCError(w, r, h.Route.FullMethod(), err)
+		return
+	}
+	code := http.StatusOK
+	if h.Status != nil {
+		code = h.Status(res, w.Header())
+	}
+	WriteMessage(w, code, res)
+}
+
+// decode fills req from the body, the path and, for routes without a body,
+// the query string. Path parameters take precedence over the body.
+func (h *Handler) decode(r *http.Request, req proto.Message) error {
+	if h.Route.Body == "*" {
+		if err := ReadBody(r, req); err != nil {
+			return err
+		}
+	} else if err := bind(req, r.URL.Query()); err != nil {
+		return err
+	}
+
+	path := map[string][]string{}
+	for _, name := range h.Route.pathParams() {
+		path[name] = []string{r.PathValue(name)}
+	}
+	return bind(req, path)
+}
+
+// ReadBody decodes the protojson body of r into msg. An empty body leaves
+// msg unchanged; unknown fields are rejected.
+func ReadBody(r *http.Request, msg proto.Message) error {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("request body is larger than %d bytes", tooLarge.Limit)
+		}
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := protojson.Unmarshal(body, msg); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// WriteMessage writes msg as a protojson response with the given status.
+// Unset fields are written with their zero value so clients see every field
+// of the schema.
+func WriteMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	b, err := marshalOptions.Marshal(msg)
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+// bind merges string values, from the query string or the path, into the
+// top-level fields of msg named by their JSON or proto names. The values are
+// converted to JSON and decoded with protojson, so they follow the same
+// rules as in a body: enums by name or number, 64-bit integers and
+// timestamps as strings.
+func bind(msg proto.Message, values map[string][]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	obj := map[string]any{}
+	for name, vs := range values {
+		fd := fields.ByJSONName(name)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(name))
+		}
+		if fd == nil || fd.IsMap() {
+			return fmt.Errorf("unknown parameter %q", name)
+		}
+		if !fd.IsList() && len(vs) > 1 {
+			return fmt.Errorf("parameter %q must not be repeated", name)
+		}
+		var list []any
+		for _, v := range vs {
+			jv, err := jsonValue(fd, v)
+			if err != nil {
+				return fmt.Errorf("invalid parameter %q: %w", name, err)
+			}
+			list = append(list, jv)
+		}
+		if fd.IsList() {
+			obj[fd.JSONName()] = list
+		} else {
+			obj[fd.JSONName()] = list[0]
+		}
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	partial := msg.ProtoReflect().New().Interface()
+	if err := protojson.Unmarshal(b, partial); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	proto.Merge(msg, partial)
+	return nil
+}
+
+func jsonValue(fd protoreflect.FieldDescriptor, v string) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.ParseBool(v)
+	case protoreflect.EnumKind:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, nil
+		}
+	}
+	return v, nil
+}
+
+// newMessage returns an empty message of the generated type for md, which
+// is registered by importing the service's package.
+func newMessage(md protoreflect.MessageDescriptor) proto.Message {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+	if err != nil {
+		return dynamicpb.NewMessage(md)
+	}
+	return mt.New().Interface()
+}
@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Info describes the API in the OpenAPI document.
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// OpenAPI returns an OpenAPI 3.0 document describing routes, with a schema
+// for every message they use. Message schemas follow the protojson mapping.
+func OpenAPI(info Info, routes []Route) ([]byte, error) {
+	g := &openAPIGen{schemas: map[string]any{
+		"Error": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"code":       map[string]any{"type": "string", "description": "The gRPC status code name, e.g. NOT_FOUND."},
+				"message":    map[string]any{"type": "string"},
+				"request_id": map[string]any{"type": "string"},
+			},
+		},
+	}}
+
+	paths := map[string]map[string]any{}
+	for _, route := range routes {
+		path := openAPIPath(route.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(route.Method)] = g.operation(route)
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": g.schemas},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ServeOpenAPI returns a handler serving a document built by OpenAPI.
+func ServeOpenAPI(doc []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	})
+}
+
+type openAPIGen struct {
+	schemas map[string]any
+}
+
+func (g *openAPIGen) operation(route Route) map[string]any {
+	in := route.RPC.Input()
+	op := map[string]any{
+		"operationId": string(route.RPC.Parent().Name()) + "_" + string(route.RPC.Name()),
+		"tags":        []string{string(route.RPC.Parent().Name())},
+		"description": "Calls the " + route.FullMethod() + " RPC.",
+		"responses": map[string]any{
+			"200": map[string]any{
+				"description": "OK",
+				"content":     jsonContent(g.ref(route.RPC.Output())),
+			},
+			"default": map[string]any{
+				"description": "Error",
+				"content":     jsonContent(map[string]any{"$ref": "#/components/schemas/Error"}),
+			},
+		},
+	}
+
+	var params []any
+	pathParams := route.pathParams()
+	for _, name := range pathParams {
+		params = append(params, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+	if route.Body == "*" {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  jsonContent(g.ref(in)),
+		}
+	} else {
+		fields := in.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if fd.IsMap() || slices.Contains(pathParams, string(fd.Name())) {
+				continue
+			}
+			params = append(params, map[string]any{
+				"name":   fd.JSONName(),
+				"in":     "query",
+				"schema": g.field(fd),
+			})
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return op
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// ref returns a reference to the schema of md, adding it and the messages
+// it uses to the components.
+func (g *openAPIGen) ref(md protoreflect.MessageDescriptor) map[string]any {
+	if s := wellKnown(md); s != nil {
+		return s
+	}
+	name := string(md.FullName())
+	if _, ok := g.schemas[name]; !ok {
+		props := map[string]any{}
+		g.schemas[name] = map[string]any{"type": "object", "properties": props}
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			props[fd.JSONName()] = g.field(fd)
+		}
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func (g *openAPIGen) field(fd protoreflect.FieldDescriptor) map[string]any {
+	switch {
+	case fd.IsMap():
+		return map[string]any{"type": "object", "additionalProperties": g.scalar(fd.MapValue())}
+	case fd.IsList():
+		return map[string]any{"type": "array", "items": g.scalar(fd)}
+	}
+	return g.scalar(fd)
+}
+
+// scalar returns the schema of a single value of fd.
+func (g *openAPIGen) scalar(fd protoreflect.FieldDescriptor) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson writes 64-bit integers as strings.
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind:
+		return map[string]any{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]any{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		var names []string
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return map[string]any{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.ref(fd.Message())
+	}
+	return map[string]any{"type": "string"}
+}
+
+// wellKnown returns the inline schema of well-known types that protojson
+// encodes as plain JSON values, or nil.
+func wellKnown(md protoreflect.MessageDescriptor) map[string]any {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration":
+		return map[string]any{"type": "string", "example": "1.5s"}
+	case "google.protobuf.Empty":
+		return map[string]any{"type": "object"}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"portal.com/portal/internal/logger"
+	expertpb "portal.com/portal/pkg/expert/v1"
+	orchpb "portal.com/portal/pkg/orchestrator/v1"
+)
+
+// fakeConn records the last call and answers it with res or err.
+type fakeConn struct {
+	res proto.Message
+	err error
+
+	method string
+	req    proto.Message
+}
+
+func (c *fakeConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	c.method, c.req = method, args.(proto.Message)
+	for _, o := range opts {
+		if t, ok := o.(grpc.TrailerCallOption); ok {
+			*t.TrailerAddr = metadata.Pairs("x-portal-llm-tokens", "7")
+		}
+	}
+	if c.err != nil {
+		return c.err
+	}
+	proto.Merge(reply.(proto.Message), c.res)
+	return nil
+}
+
+func (c *fakeConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("streams are not supported")
+}
+
+// serve registers the routes of the orchestrator and expert services on a
+// mux calling conn.
+func serve(t *testing.T, conn *fakeConn) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	for _, sd := range []protoreflect.ServiceDescriptor{
+		orchpb.File_api_orchestrator_v1_orchestrator_proto.Services().ByName("QueryOrchestratorService"),
+		expertpb.File_api_expert_v1_expert_proto.Services().ByName("ExpertService"),
+	} {
+		routes, err := Routes(sd)
+		if err != nil {
+			t.Fatalf("Routes(%s): %v", sd.FullName(), err)
+		}
+		for _, route := range routes {
+			mux.Handle(route.Pattern(), &Handler{Route: route, Conn: conn})
+		}
+	}
+	return mux
+}
+
+func TestRoutesFromAnnotations(t *testing.T) {
+	routes, err := Routes(orchpb.File_api_orchestrator_v1_orchestrator_proto.Services().ByName("QueryOrchestratorService"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var patterns []string
+	for _, r := range routes {
+		patterns = append(patterns, r.Pattern())
+		if r.FullMethod() != orchpb.QueryOrchestratorService_Search_FullMethodName {
+			t.Errorf("unexpected method %s", r.FullMethod())
+		}
+	}
+	if got := strings.Join(patterns, ","); got != "POST /api/v1/search,GET /api/v1/search" {
+		t.Errorf("unexpected patterns %s", got)
+	}
+
+	if got := muxPath("/api/v1/experts/{url=**}"); got != "/api/v1/experts/{url...}" {
+		t.Errorf("unexpected mux path %s", got)
+	}
+}
+
+func TestHandlerDecodesProtoJSON(t *testing.T) {
+	conn := &fakeConn{res: &expertpb.CreateOrUpdateExpertResponse{ExpertId: "42"}}
+	mux := serve(t, conn)
+
+	body := `{"url": "https://example.com/", "content": "text", "expertType": "EXPERT_TYPE_RAG"}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/experts", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	want := &expertpb.CreateOrUpdateExpertRequest{Url: "https://example.com/", Content: "text", ExpertType: expertpb.ExpertType_EXPERT_TYPE_RAG}
+	if conn.method != expertpb.ExpertService_CreateOrUpdateExpert_FullMethodName || !proto.Equal(conn.req, want) {
+		t.Errorf("unexpected call %s %v", conn.method, conn.req)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"expertId":"42"}` {
+		t.Errorf("unexpected body %s", got)
+	}
+}
+
+func TestHandlerBindsQueryParameters(t *testing.T) {
+	conn := &fakeConn{res: &orchpb.SearchResponse{Summary: "s"}}
+	mux := serve(t, conn)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search?query=go+modules", nil))
+	if rec.Code != http.StatusOK || !proto.Equal(conn.req, &orchpb.SearchRequest{Query: "go modules"}) {
+		t.Errorf("unexpected call %v with status %d", conn.req, rec.Code)
+	}
+	var res map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res["summary"] != "s" {
+		t.Errorf("unexpected body %s", rec.Body)
+	}
+	if _, ok := res["sources"]; !ok {
+		t.Errorf("expected unpopulated fields in %s", rec.Body)
+	}
+
+	// Repeated fields are read from repeated parameters.
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search?query=x&includeDomains=a.com&includeDomains=b.com&language=en&crawledAfter=2024-01-01T00:00:00Z&expertType=EXPERT_TYPE_RAG&maxSources=5", nil))
+	wantSearch := &orchpb.SearchRequest{
+		Query:          "x",
+		IncludeDomains: []string{"a.com", "b.com"},
+		Language:       "en",
+		CrawledAfter:   timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		ExpertType:     expertpb.ExpertType_EXPERT_TYPE_RAG,
+		MaxSources:     5,
+	}
+	if rec.Code != http.StatusOK || !proto.Equal(conn.req, wantSearch) {
+		t.Errorf("unexpected call %v with status %d: %s", conn.req, rec.Code, rec.Body)
+	}
+
+	conn.res = &expertpb.ListExpertsResponse{}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/experts?pageSize=10&expert_type=EXPERT_TYPE_RAG&domain=example.com&updatedBefore=2024-01-01T00:00:00Z", nil))
+	want := &expertpb.ListExpertsRequest{
+		PageSize:      10,
+		ExpertType:    expertpb.ExpertType_EXPERT_TYPE_RAG,
+		Domain:        []string{"example.com"},
+		UpdatedBefore: timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	if rec.Code != http.StatusOK || !proto.Equal(conn.req, want) {
+		t.Errorf("unexpected call %v with status %d: %s", conn.req, rec.Code, rec.Body)
+	}
+
+	conn.res = &expertpb.DeleteExpertResponse{}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/admin/experts/abc", nil))
+	if rec.Code != http.StatusOK || !proto.Equal(conn.req, &expertpb.DeleteExpertRequest{Id: "abc"}) {
+		t.Errorf("unexpected call %v with status %d", conn.req, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search?q=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown parameter, got %d", rec.Code)
+	}
+}
+
+func TestHandlerRejectsBadBodies(t *testing.T) {
+	mux := serve(t, &fakeConn{res: &orchpb.SearchResponse{}})
+	for _, body := range []string{`{`, `{"query": 1}`, `{"unknown": "x"}`} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/search", strings.NewReader(body)))
+		var res ErrorBody
+		if rec.Code != http.StatusBadRequest || json.NewDecoder(rec.Body).Decode(&res) != nil || res.Code != "INVALID_ARGUMENT" {
+			t.Errorf("%s: expected a 400 INVALID_ARGUMENT body, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestHandlerMapsStatusCodes(t *testing.T) {
+	tests := []struct {
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{nil, http.StatusOK, "", ""},
+		{status.Error(codes.InvalidArgument, "query is required"), http.StatusBadRequest, "INVALID_ARGUMENT", "query is required"},
+		{status.Error(codes.NotFound, "no expert"), http.StatusNotFound, "NOT_FOUND", "no expert"},
+		{status.Error(codes.DeadlineExceeded, "too slow"), http.StatusGatewayTimeout, "DEADLINE_EXCEEDED", "too slow"},
+		{status.Error(codes.ResourceExhausted, "quota"), http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", "quota"},
+		{status.Error(codes.Unavailable, "down"), http.StatusServiceUnavailable, "UNAVAILABLE", "down"},
+		{status.Error(codes.Internal, "pq: connection reset"), http.StatusInternalServerError, "INTERNAL", "internal error"},
+	}
+	for _, tt := range tests {
+		var trailer metadata.MD
+		routes, _ := Routes(orchpb.File_api_orchestrator_v1_orchestrator_proto.Services().ByName("QueryOrchestratorService"))
+		h := logger.HTTPMiddleware(&Handler{
+			Route:     routes[0],
+			Conn:      &fakeConn{res: &orchpb.SearchResponse{}, err: tt.err},
+			OnTrailer: func(r *http.Request, md metadata.MD) { trailer = md },
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/search", strings.NewReader(`{"query": "q"}`))
+		req.Header.Set(logger.HeaderName, "req-1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.wantStatus, rec.Code)
+		}
+		if len(trailer.Get("x-portal-llm-tokens")) == 0 {
+			t.Errorf("%v: expected the trailer to be passed to OnTrailer", tt.err)
+		}
+		if tt.err == nil {
+			continue
+		}
+		var body ErrorBody
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%v: expected a JSON error body: %v", tt.err, err)
+		}
+		if body.Code != tt.wantCode || body.Message != tt.wantMessage || body.RequestID != "req-1" {
+			t.Errorf("%v: unexpected error body %+v", tt.err, body)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	routes, err := Routes(expertpb.File_api_expert_v1_expert_proto.Services().ByName("ExpertService"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := OpenAPI(Info{Title: "Portal API", Version: "v1"}, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths["/api/v1/experts:query"]["post"] == nil || doc.Paths["/api/v1/admin/experts"]["post"] == nil {
+		t.Errorf("missing paths in %v", doc.Paths)
+	}
+	req := doc.Components.Schemas["expert.v1.CreateOrUpdateExpertRequest"].Properties
+	enum, _ := req["expertType"]["enum"].([]any)
+	if len(enum) != 3 || enum[2] != "EXPERT_TYPE_RAG" {
+		t.Errorf("unexpected expertType schema %v", req["expertType"])
+	}
+	if _, ok := doc.Components.Schemas["Error"]; !ok {
+		t.Error("missing Error schema")
+	}
+}
+
+func TestHandlerStatus(t *testing.T) {
+	routes, _ := Routes(expertpb.File_api_expert_v1_expert_proto.Services().ByName("ExpertService"))
+	h := &Handler{
+		Route: routes[1],
+		Conn:  &fakeConn{res: &expertpb.QueryExpertResponse{Status: expertpb.ExpertStatus_EXPERT_STATUS_PENDING}},
+		Status: func(res proto.Message, header http.Header) int {
+			header.Set("Retry-After", "1")
+			return http.StatusAccepted
+		},
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/experts:query", strings.NewReader(`{"url": "https://example.com/", "query": "q"}`)))
+	if rec.Code != http.StatusAccepted || rec.Header().Get("Retry-After") != "1" || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,156 @@
+// Package rest serves gRPC services as a JSON/HTTP API. The routes come from
+// the google.api.http annotations on the RPCs in api/, messages are encoded
+// with protojson, and an OpenAPI document is generated from the same
+// descriptors, so the REST surface cannot drift from the protos.
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Route is one HTTP binding of an RPC.
+type Route struct {
+	// Method is the HTTP method, e.g. "POST".
+	Method string
+	// Path is the path template of the binding, e.g. "/api/v1/search" or
+	// "/api/v1/experts/{url=**}".
+	Path string
+	// Body is "*" when the request message is read from the body, or empty
+	// when its fields are read from the query string.
+	Body string
+	// RPC is the method called.
+	RPC protoreflect.MethodDescriptor
+}
+
+// FullMethod returns the gRPC method name of the route's RPC, e.g.
+// "/orchestrator.v1.QueryOrchestratorService/Search".
+func (r Route) FullMethod() string {
+	return fmt.Sprintf("/%s/%s", r.RPC.Parent().FullName(), r.RPC.Name())
+}
+
+// Pattern returns the http.ServeMux pattern matching the route.
+func (r Route) Pattern() string {
+	return r.Method + " " + muxPath(r.Path)
+}
+
+// pathParams returns the names of the fields bound from the path, in order.
+func (r Route) pathParams() []string {
+	var params []string
+	for _, seg := range strings.Split(r.Path, "/") {
+		if name, ok := param(seg); ok {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// Routes returns the routes of every annotated RPC of sd. RPCs without a
+// google.api.http annotation are not exposed.
+func Routes(sd protoreflect.ServiceDescriptor) ([]Route, error) {
+	var routes []Route
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+			route, err := newRoute(md, r)
+			if err != nil {
+				return nil, fmt.Errorf("rest: %s: %w", md.FullName(), err)
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+func newRoute(md protoreflect.MethodDescriptor, rule *annotations.HttpRule) (Route, error) {
+	route := Route{Body: rule.GetBody(), RPC: md}
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		route.Method, route.Path = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		route.Method, route.Path = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		route.Method, route.Path = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		route.Method, route.Path = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		route.Method, route.Path = http.MethodPatch, p.Patch
+	default:
+		return Route{}, fmt.Errorf("unsupported http rule pattern %T", p)
+	}
+	if route.Body != "" && route.Body != "*" {
+		return Route{}, fmt.Errorf("unsupported body %q, only \"*\" or none", route.Body)
+	}
+	if rule.GetResponseBody() != "" {
+		return Route{}, fmt.Errorf("response_body is not supported")
+	}
+
+	segs := strings.Split(route.Path, "/")
+	for i, seg := range segs {
+		if !strings.ContainsAny(seg, "{}") {
+			continue
+		}
+		name, ok := param(seg)
+		if !ok {
+			return Route{}, fmt.Errorf("unsupported path segment %q", seg)
+		}
+		if strings.HasSuffix(seg, "=**}") && i != len(segs)-1 {
+			return Route{}, fmt.Errorf("%q must be the last path segment", seg)
+		}
+		fd := md.Input().Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+			return Route{}, fmt.Errorf("path parameter %q is not a string field of %s", name, md.Input().FullName())
+		}
+	}
+	return route, nil
+}
+
+// param returns the field name of a path segment of the form {name},
+// {name=*} or {name=**}.
+func param(seg string) (string, bool) {
+	if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+		return "", false
+	}
+	name, pattern, _ := strings.Cut(seg[1:len(seg)-1], "=")
+	if name == "" || (pattern != "" && pattern != "*" && pattern != "**") {
+		return "", false
+	}
+	return name, true
+}
+
+// muxPath converts a path template to the http.ServeMux syntax, where a
+// multi-segment {name=**} is written {name...}.
+func muxPath(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if name, ok := param(seg); ok {
+			if strings.HasSuffix(seg, "=**}") {
+				segs[i] = "{" + name + "...}"
+			} else {
+				segs[i] = "{" + name + "}"
+			}
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// openAPIPath converts a path template to the OpenAPI syntax.
+func openAPIPath(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if name, ok := param(seg); ok {
+			segs[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
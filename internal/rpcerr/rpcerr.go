@@ -0,0 +1,43 @@
+// Package rpcerr turns errors into gRPC status errors with the right code.
+//
+// Handlers return InvalidArgument or NotFound with status.Errorf themselves;
+// Wrap covers everything else, so failures of a downstream RPC keep their
+// code, expired or cancelled contexts are reported as such, and database and
+// other unexpected errors become Internal.
+package rpcerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Wrap returns err as a gRPC status error whose message is prefixed with the
+// formatted description.
+func Wrap(err error, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if st, ok := status.FromError(err); ok {
+		return status.Errorf(st.Code(), "%s: %s", msg, st.Message())
+	}
+	return status.Errorf(Code(err), "%s: %v", msg, err)
+}
+
+// Code returns the gRPC code for err: the code of a status error, Canceled or
+// DeadlineExceeded for context errors, and Internal otherwise.
+func Code(err error) codes.Code {
+	switch {
+	case err == nil:
+		return codes.OK
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+	return codes.Internal
+}
@@ -0,0 +1,33 @@
+package rpcerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{errors.New("connection refused"), codes.Internal},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{status.Error(codes.NotFound, "no expert"), codes.NotFound},
+	}
+	for _, tt := range tests {
+		err := Wrap(tt.err, "failed to %s", "retrieve")
+		st, _ := status.FromError(err)
+		if st.Code() != tt.want {
+			t.Errorf("Wrap(%v) code = %v, want %v", tt.err, st.Code(), tt.want)
+		}
+		if want := "failed to retrieve: "; len(st.Message()) < len(want) || st.Message()[:len(want)] != want {
+			t.Errorf("Wrap(%v) message = %q, want prefix %q", tt.err, st.Message(), want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package shutdown helps Portal commands stop cleanly on SIGINT and SIGTERM.
+//
+// A command waits on the context returned by Signals, then stops accepting
+// work, lets in-flight work finish within a deadline, and finally releases
+// its resources, so a rolling deploy does not drop requests or messages.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+)
+
+// Signals returns a context that is cancelled when the process receives
+// SIGINT or SIGTERM. Calling stop restores the default signal behaviour, so a
+// second signal kills the process immediately.
+func Signals() (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// GRPC stops s gracefully: it stops accepting connections and waits for
+// active RPCs to finish. RPCs still running after timeout are cancelled.
+func GRPC(s *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		slog.Warn("Graceful stop timed out, cancelling active RPCs", "timeout", timeout)
+		s.Stop()
+	}
+}
+
+// Run calls fn with a context that expires after timeout and logs a failure.
+// It is meant for deferred cleanup such as flushing telemetry or shutting
+// down an HTTP server.
+func Run(name string, fn func(context.Context) error, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := fn(ctx); err != nil {
+		slog.Error("Shutdown step failed", "step", name, "error", err)
+	}
+}
+
+// NATS drains nc: subscriptions stop receiving new messages, messages already
+// delivered are handled, pending publishes are flushed and the connection is
+// closed. The connection is closed regardless once timeout expires.
+func NATS(nc *nats.Conn, timeout time.Duration) error {
+	closed := make(chan struct{})
+	var once sync.Once
+	nc.SetClosedHandler(func(*nats.Conn) { once.Do(func() { close(closed) }) })
+	if err := nc.Drain(); err != nil {
+		return err
+	}
+
+	select {
+	case <-closed:
+		return nil
+	case <-time.After(timeout):
+		nc.Close()
+		return errors.New("shutdown: timed out draining NATS connection")
+	}
+}
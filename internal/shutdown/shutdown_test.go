@@ -0,0 +1,42 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestGRPCStopsServer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(lis) }()
+
+	GRPC(s, time.Second)
+	select {
+	case err := <-served:
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			t.Errorf("unexpected Serve error after a graceful stop: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Serve did not return after GRPC")
+	}
+}
+
+func TestRunAppliesTimeout(t *testing.T) {
+	var deadline time.Time
+	Run("test", func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}, time.Minute)
+	if time.Until(deadline) <= 0 || time.Until(deadline) > time.Minute {
+		t.Errorf("expected a deadline about a minute away, got %v", deadline)
+	}
+}
@@ -0,0 +1,111 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Registry holds every Portal metric. It is served on /metrics by Setup.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RPCDuration measures gRPC call latency on both sides of a connection.
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "portal_rpc_duration_seconds",
+		Help:    "Latency of gRPC calls, by side (server or client), method and status code.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 16),
+	}, []string{"side", "method", "code"})
+
+	// PagesCrawled counts pages handled by the crawler.
+	PagesCrawled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_pages_crawled_total",
+		Help: "Pages crawled, by outcome (published, empty or failed).",
+	}, []string{"outcome"})
+
+	// ChunksIndexed counts chunks written to the vector store.
+	ChunksIndexed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_chunks_indexed_total",
+		Help: "Document chunks embedded and stored, by embedding model.",
+	}, []string{"model"})
+
+	// RetrievalRequests counts context retrievals by whether any chunk matched.
+	RetrievalRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_retrieval_requests_total",
+		Help: "Context retrievals, by result (hit or miss).",
+	}, []string{"result"})
+
+	// RetrievedChunks measures how many chunks each retrieval returned.
+	RetrievedChunks = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "portal_retrieval_chunks",
+		Help:    "Number of chunks returned per context retrieval.",
+		Buckets: prometheus.LinearBuckets(0, 1, 11),
+	})
+
+	// LLMTokens counts tokens sent to and generated by LLMs.
+	LLMTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_llm_tokens_total",
+		Help: "LLM tokens used, by model and kind (prompt or completion).",
+	}, []string{"model", "kind"})
+
+	// CacheRequests counts result cache lookups.
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_cache_requests_total",
+		Help: "Result cache lookups, by cache (expert or search) and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	// HedgedRequests counts duplicate requests sent to slow experts.
+	HedgedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_hedged_requests_total",
+		Help: "Hedged expert queries, by the attempt that answered first (primary, hedge or none).",
+	}, []string{"winner"})
+
+	// SkippedExperts counts experts left out of search results.
+	SkippedExperts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_skipped_experts_total",
+		Help: "Experts whose answer is missing from a search, by reason (budget_exceeded or failed).",
+	}, []string{"reason"})
+
+	// CircuitBreakerState reports the circuit breaker of each gRPC client.
+	CircuitBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "portal_circuit_breaker_state",
+		Help: "State of the circuit breaker of each gRPC client, by service (0 closed, 1 half-open, 2 open).",
+	}, []string{"service"})
+
+	// CircuitBreakerRejections counts calls failed by an open circuit breaker.
+	CircuitBreakerRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_circuit_breaker_rejections_total",
+		Help: "gRPC calls failed without being sent because the circuit breaker was open, by service.",
+	}, []string{"service"})
+
+	// VerifiedClaims counts the claims of expert answers checked against
+	// their content.
+	VerifiedClaims = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "portal_verified_claims_total",
+		Help: "Claims of expert answers verified against the content, by result (supported or unsupported).",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RPCDuration,
+		PagesCrawled,
+		ChunksIndexed,
+		RetrievalRequests,
+		RetrievedChunks,
+		LLMTokens,
+		CacheRequests,
+		HedgedRequests,
+		SkippedExperts,
+		CircuitBreakerState,
+		CircuitBreakerRejections,
+		VerifiedClaims,
+	)
+}
+
+// RecordLLMUsage adds the tokens of one LLM call to LLMTokens.
+func RecordLLMUsage(model string, promptTokens, completionTokens int) {
+	LLMTokens.WithLabelValues(model, "prompt").Add(float64(promptTokens))
+	LLMTokens.WithLabelValues(model, "completion").Add(float64(completionTokens))
+}
@@ -0,0 +1,46 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// natsCarrier adapts NATS message headers to a propagation.TextMapCarrier.
+type natsCarrier struct {
+	msg *nats.Msg
+}
+
+func (c natsCarrier) Get(key string) string {
+	return c.msg.Header.Get(key)
+}
+
+func (c natsCarrier) Set(key, value string) {
+	if c.msg.Header == nil {
+		c.msg.Header = nats.Header{}
+	}
+	c.msg.Header.Set(key, value)
+}
+
+func (c natsCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Header))
+	for k := range c.msg.Header {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+var _ propagation.TextMapCarrier = natsCarrier{}
+
+// InjectNATS writes the trace context in ctx into the message headers.
+func InjectNATS(ctx context.Context, msg *nats.Msg) {
+	otel.GetTextMapPropagator().Inject(ctx, natsCarrier{msg})
+}
+
+// ExtractNATS returns a copy of ctx carrying the trace context from the
+// message headers, so the consumer's spans join the publisher's trace.
+func ExtractNATS(ctx context.Context, msg *nats.Msg) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, natsCarrier{msg})
+}
@@ -1,3 +1,129 @@
+// Package telemetry configures OpenTelemetry tracing and Prometheus metrics
+// for Portal services.
+//
+// Traces are exported with OTLP over gRPC or printed to stdout, and the W3C
+// trace context is propagated in gRPC metadata and NATS message headers.
+// Metrics are served in the Prometheus text format on a separate /metrics
+// listener.
 package telemetry
 
-// TODO: Implement OpenTelemetry configuration for Prometheus metrics.
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"portal.com/portal/internal/tlsconfig"
+)
+
+// Config selects the exporters for a service.
+type Config struct {
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// TraceExporter is "none", "stdout" or "otlp".
+	TraceExporter string
+	// OTLPEndpoint is the host:port of the OTLP gRPC collector. If empty, the
+	// standard OTEL_EXPORTER_OTLP_ENDPOINT variable is honoured.
+	OTLPEndpoint string
+	// MetricsAddr is the address of the /metrics listener, e.g. ":9090".
+	// Metrics are not served if it is empty.
+	MetricsAddr string
+	// Certs are the TLS files the OTLP exporter connects with, like the
+	// service's other clients. Without them, or without a CA bundle, it
+	// connects to OTLPEndpoint in plaintext.
+	Certs *tlsconfig.Certs
+}
+
+// Setup installs the global tracer provider and propagator and starts the
+// metrics listener. The returned function flushes pending spans and stops
+// the listener; it must be called before the process exits.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.TraceExporter {
+	case "", "none":
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		var opts []otlptracegrpc.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		switch {
+		case cfg.Certs != nil && cfg.Certs.ClientTLS():
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(cfg.Certs.ClientCredentials()))
+		case cfg.OTLPEndpoint != "":
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("telemetry: unknown trace exporter %q (want none, stdout or otlp)", cfg.TraceExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: failed to create %s exporter: %w", cfg.TraceExporter, err)
+	}
+
+	var shutdowns []func(context.Context) error
+	if exporter != nil {
+		res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(cfg.ServiceName),
+		))
+		if err != nil {
+			return nil, fmt.Errorf("telemetry: failed to build resource: %w", err)
+		}
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+		)
+		otel.SetTracerProvider(tp)
+		shutdowns = append(shutdowns, tp.Shutdown)
+	}
+
+	if cfg.MetricsAddr != "" {
+		lis, err := net.Listen("tcp", cfg.MetricsAddr)
+		if err != nil {
+			return nil, fmt.Errorf("telemetry: failed to listen for metrics: %w", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+		srv := &http.Server{Handler: mux}
+		go func() {
+			if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Metrics server failed", "error", err)
+			}
+		}()
+		slog.Info("Serving metrics", "addr", lis.Addr().String())
+		shutdowns = append(shutdowns, srv.Shutdown)
+	}
+
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, fn := range shutdowns {
+			errs = append(errs, fn(ctx))
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// Tracer returns a tracer for manual spans, named after the instrumented code.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
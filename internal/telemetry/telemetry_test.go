@@ -0,0 +1,156 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"portal.com/portal/internal/tlsconfig"
+)
+
+func TestNATSPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	msg := nats.NewMsg("crawled-content")
+	InjectNATS(trace.ContextWithSpanContext(context.Background(), sc), msg)
+
+	got := trace.SpanContextFromContext(ExtractNATS(context.Background(), msg))
+	if got.TraceID() != sc.TraceID() || got.SpanID() != sc.SpanID() || !got.IsRemote() {
+		t.Errorf("expected remote span context %v, got %v", sc, got)
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{ServiceName: "s", TraceExporter: "zipkin"}); err == nil {
+		t.Error("expected error for unknown trace exporter")
+	}
+}
+
+func TestRecordLLMUsage(t *testing.T) {
+	RecordLLMUsage("test-model", 3, 2)
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0.0
+	for _, f := range families {
+		if f.GetName() != "portal_llm_tokens_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			total += m.GetCounter().GetValue()
+		}
+	}
+	if total != 5 {
+		t.Errorf("expected 5 tokens recorded, got %v", total)
+	}
+}
+
+// writeCA writes a self-signed CA certificate, and returns its path.
+func writeCA(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// firstByte exports a span to a collector listening on a local port, and
+// returns the first byte the exporter sent it.
+func firstByte(t *testing.T, certs *tlsconfig.Certs) byte {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	got := make(chan byte, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		b := make([]byte, 1)
+		if _, err := io.ReadFull(conn, b); err == nil {
+			got <- b[0]
+		}
+	}()
+
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "s", TraceExporter: "otlp", OTLPEndpoint: lis.Addr().String(), Certs: certs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := Tracer("test").Start(context.Background(), "span")
+	span.End()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	shutdown(ctx)
+	select {
+	case b := <-got:
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("the exporter did not connect")
+		return 0
+	}
+}
+
+func TestOTLPExporterUsesTLS(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	// A TLS handshake starts with a handshake record, HTTP/2 in plaintext
+	// with its "PRI * HTTP/2.0" preface.
+	certs, err := tlsconfig.Load(tlsconfig.Config{CAFile: writeCA(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := firstByte(t, certs); b != 0x16 {
+		t.Errorf("the exporter sent %#x with a CA bundle, want a TLS handshake", b)
+	}
+	plain, err := tlsconfig.Load(tlsconfig.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := firstByte(t, plain); b != 'P' {
+		t.Errorf("the exporter sent %#x without a CA bundle, want plaintext HTTP/2", b)
+	}
+}
@@ -0,0 +1,345 @@
+// Package tlsconfig secures the connections of Portal services with TLS: the
+// gRPC servers and the API Gateway's HTTP server, the gRPC clients between
+// services, and the NATS and PostgreSQL connections.
+//
+// A service has a single certificate, which its servers serve and which it
+// presents to the servers it calls when they require client certificates
+// (mTLS), and a single CA bundle, which verifies those servers and, with
+// ClientAuth, its clients. Certificates used for mTLS therefore need both the
+// serverAuth and clientAuth extended key usages.
+//
+// The files are read again when they change on disk, so certificates can be
+// renewed without restarting services.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	conf "portal.com/portal/internal/config"
+)
+
+// Config names the TLS files of a service.
+type Config struct {
+	// CertFile and KeyFile are the PEM certificate chain and key of the
+	// service. Servers use TLS when they are set.
+	CertFile string
+	KeyFile  string
+	// CAFile is a PEM bundle of the CAs trusted to sign the certificates of
+	// peers. Clients use TLS when it is set.
+	CAFile string
+	// ClientAuth makes servers require client certificates signed by CAFile.
+	ClientAuth bool
+}
+
+// Role is how a command uses TLS, which decides the flags it takes.
+type Role int
+
+const (
+	// Client commands only call servers.
+	Client Role = iota
+	// GRPCServer commands also serve gRPC, and can require client
+	// certificates.
+	GRPCServer
+	// HTTPServer commands also serve HTTPS.
+	HTTPServer
+)
+
+// RegisterFlags registers the -tls-* flags setting cfg on loader.
+func RegisterFlags(loader *conf.Loader, cfg *Config, role Role) {
+	switch role {
+	case GRPCServer:
+		loader.StringVar(&cfg.CertFile, "tls-cert", "", "PEM certificate served by the gRPC server, and presented to servers requiring client certificates (empty to serve plaintext)")
+	case HTTPServer:
+		loader.StringVar(&cfg.CertFile, "tls-cert", "", "PEM certificate served over HTTPS, and presented to servers requiring client certificates (empty to serve plain HTTP)")
+	default:
+		loader.StringVar(&cfg.CertFile, "tls-cert", "", "PEM certificate presented to servers requiring client certificates")
+	}
+	loader.StringVar(&cfg.KeyFile, "tls-key", "", "PEM key of -tls-cert")
+	if role == GRPCServer {
+		loader.StringVar(&cfg.CAFile, "tls-ca", "", "PEM bundle of the CAs verifying the servers called and, with -tls-client-auth, the clients (empty to call servers in plaintext)")
+		loader.BoolVar(&cfg.ClientAuth, "tls-client-auth", false, "Require client certificates signed by -tls-ca (mTLS)")
+		return
+	}
+	loader.StringVar(&cfg.CAFile, "tls-ca", "", "PEM bundle of the CAs verifying the servers called (empty to call servers in plaintext)")
+}
+
+// reloadInterval is how often the files are checked for changes.
+const reloadInterval = 10 * time.Second
+
+// Certs holds the certificate and CAs of a service, read again from disk
+// when the files change.
+type Certs struct {
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	checked time.Time
+	stamp   string
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+}
+
+// Load reads the files of cfg.
+func Load(cfg Config) (*Certs, error) {
+	switch {
+	case (cfg.CertFile == "") != (cfg.KeyFile == ""):
+		return nil, errors.New("tlsconfig: the certificate and key must be set together")
+	case cfg.ClientAuth && (cfg.CertFile == "" || cfg.CAFile == ""):
+		return nil, errors.New("tlsconfig: client authentication requires a certificate and a CA bundle")
+	}
+	c := &Certs{cfg: cfg, now: time.Now}
+	stamp, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(stamp); err != nil {
+		return nil, err
+	}
+	c.checked = c.now()
+	return c, nil
+}
+
+// files returns a stamp of the modification times and sizes of the files,
+// which changes when any of them is rewritten.
+func (c *Certs) files() (string, error) {
+	var stamp strings.Builder
+	for _, name := range []string{c.cfg.CertFile, c.cfg.KeyFile, c.cfg.CAFile} {
+		if name == "" {
+			continue
+		}
+		fi, err := os.Stat(name)
+		if err != nil {
+			return "", fmt.Errorf("tlsconfig: %w", err)
+		}
+		fmt.Fprintf(&stamp, "%s:%d:%d;", name, fi.ModTime().UnixNano(), fi.Size())
+	}
+	return stamp.String(), nil
+}
+
+// load reads the files, whose stamp is stamp. c.mu must be held, except
+// during Load.
+func (c *Certs) load(stamp string) error {
+	var cert *tls.Certificate
+	if c.cfg.CertFile != "" {
+		kp, err := tls.LoadX509KeyPair(c.cfg.CertFile, c.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("tlsconfig: %w", err)
+		}
+		cert = &kp
+	}
+	var pool *x509.CertPool
+	if c.cfg.CAFile != "" {
+		pem, err := os.ReadFile(c.cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("tlsconfig: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tlsconfig: no certificate found in %s", c.cfg.CAFile)
+		}
+	}
+	c.cert, c.pool, c.stamp = cert, pool, stamp
+	return nil
+}
+
+// current returns the certificate and CAs, reading the files again if they
+// changed. Files that fail to load, as when they are being replaced, are
+// logged and the previous ones kept.
+func (c *Certs) current() (*tls.Certificate, *x509.CertPool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now := c.now(); now.Sub(c.checked) >= reloadInterval {
+		c.checked = now
+		stamp, err := c.files()
+		if err == nil && stamp != c.stamp {
+			if err = c.load(stamp); err == nil {
+				slog.Info("Reloaded TLS certificates", "cert", c.cfg.CertFile, "ca", c.cfg.CAFile)
+			}
+		}
+		if err != nil {
+			slog.Warn("Failed to reload TLS certificates, keeping the previous ones", "error", err)
+		}
+	}
+	return c.cert, c.pool
+}
+
+func (c *Certs) certificate() (*tls.Certificate, error) {
+	cert, _ := c.current()
+	return cert, nil
+}
+
+// verifyClient verifies the certificate chain of a client against the
+// current CAs.
+func (c *Certs) verifyClient(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tlsconfig: the peer sent no certificate")
+	}
+	_, pool := c.current()
+	opts := x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// Server returns the TLS configuration of servers, or nil if they serve
+// plaintext.
+func (c *Certs) Server() *tls.Config {
+	if c.cfg.CertFile == "" {
+		return nil
+	}
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return c.certificate() },
+	}
+	if c.cfg.ClientAuth {
+		// Client certificates are verified by VerifyConnection rather than
+		// against ClientCAs, which cannot be reloaded.
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = c.verifyClient
+	}
+	return cfg
+}
+
+// client returns the TLS configuration of a client connection, with the
+// current certificate and CAs, or nil if clients connect in plaintext.
+func (c *Certs) client() *tls.Config {
+	if c.cfg.CAFile == "" {
+		return nil
+	}
+	cert, pool := c.current()
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+	return cfg
+}
+
+// ClientTLS reports whether clients connect with TLS, verifying servers
+// against the CA bundle.
+func (c *Certs) ClientTLS() bool {
+	return c.cfg.CAFile != ""
+}
+
+// ServerCredentials returns the transport credentials of gRPC servers.
+func (c *Certs) ServerCredentials() credentials.TransportCredentials {
+	if cfg := c.Server(); cfg != nil {
+		return credentials.NewTLS(cfg)
+	}
+	return insecure.NewCredentials()
+}
+
+// ClientCredentials returns the transport credentials of gRPC clients.
+func (c *Certs) ClientCredentials() credentials.TransportCredentials {
+	if cfg := c.client(); cfg != nil {
+		return &clientCredentials{TransportCredentials: credentials.NewTLS(cfg), certs: c}
+	}
+	return insecure.NewCredentials()
+}
+
+// clientCredentials are TLS credentials taking the current certificate and
+// CAs for every connection: a tls.Config cannot reload RootCAs, and
+// verifying servers with a callback instead would not know the host name
+// they are verified for.
+type clientCredentials struct {
+	credentials.TransportCredentials
+	certs *Certs
+}
+
+func (t *clientCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(t.certs.client()).ClientHandshake(ctx, authority, conn)
+}
+
+func (t *clientCredentials) Clone() credentials.TransportCredentials {
+	return &clientCredentials{TransportCredentials: t.TransportCredentials.Clone(), certs: t.certs}
+}
+
+// NATSOptions returns the options of NATS connections. The NATS client reads
+// the files on every connection. Without a CA bundle, NATS still uses TLS
+// with the system CAs for tls:// URLs.
+func (c *Certs) NATSOptions() []nats.Option {
+	var opts []nats.Option
+	if c.cfg.CAFile != "" {
+		opts = append(opts, nats.RootCAs(c.cfg.CAFile))
+		if c.cfg.CertFile != "" {
+			opts = append(opts, nats.ClientCert(c.cfg.CertFile, c.cfg.KeyFile))
+		}
+	}
+	return opts
+}
+
+// sslMode matches the sslmode setting of a key/value connection string.
+var sslMode = regexp.MustCompile(`(?:^|\s)sslmode\s*=\s*'?([^'\s]*)`)
+
+// PostgresDSN returns dsn with the settings that make lib/pq verify the
+// server against the CAs, and present the certificate if there is one. The
+// driver reads the files on every connection. dsn is returned unchanged if
+// clients use plaintext or it asks for verification itself. With a CA
+// bundle, a dsn setting an sslmode that does not verify the server, as in
+// "sslmode=disable", is an error rather than a silently plaintext
+// connection.
+func (c *Certs) PostgresDSN(dsn string) (string, error) {
+	if c.cfg.CAFile == "" {
+		return dsn, nil
+	}
+	isURL := strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+	var u *url.URL
+	mode := ""
+	if isURL {
+		var err error
+		if u, err = url.Parse(dsn); err != nil {
+			// lib/pq reports the invalid URL.
+			return dsn, nil
+		}
+		mode = u.Query().Get("sslmode")
+	} else if m := sslMode.FindStringSubmatch(dsn); m != nil {
+		mode = m[1]
+	}
+	switch mode {
+	case "":
+	case "verify-ca", "verify-full":
+		return dsn, nil
+	default:
+		return "", fmt.Errorf("the PostgreSQL connection string sets sslmode=%s, which does not verify the server against the CA bundle; remove it or set sslmode=verify-full", mode)
+	}
+	params := [][2]string{{"sslmode", "verify-full"}, {"sslrootcert", c.cfg.CAFile}}
+	if c.cfg.CertFile != "" {
+		params = append(params, [2]string{"sslcert", c.cfg.CertFile}, [2]string{"sslkey", c.cfg.KeyFile})
+	}
+	if isURL {
+		q := u.Query()
+		for _, p := range params {
+			q.Set(p[0], p[1])
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	var b strings.Builder
+	b.WriteString(dsn)
+	for _, p := range params {
+		v := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(p[1])
+		fmt.Fprintf(&b, " %s='%s'", p[0], v)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,295 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	conf "portal.com/portal/internal/config"
+)
+
+// ca is a certificate authority issuing certificates for tests.
+type ca struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newCA(t *testing.T) *ca {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &ca{cert: cert, key: key, dir: t.TempDir()}
+}
+
+// bundle writes the certificate of the CA and returns its path.
+func (a *ca) bundle(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(a.dir, "ca.pem")
+	writePEM(t, path, "CERTIFICATE", a.cert.Raw)
+	return path
+}
+
+// issue writes a certificate for localhost and its key to dir.
+func (a *ca) issue(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func load(t *testing.T, cfg Config) *Certs {
+	t.Helper()
+	c, err := Load(cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return c
+}
+
+// serve starts a gRPC health server with the credentials of certs and
+// returns its address.
+func serve(t *testing.T, certs *Certs) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.Creds(certs.ServerCredentials()))
+	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return "localhost:" + port
+}
+
+func check(t *testing.T, addr string, certs *Certs) error {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(certs.ClientCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	authority := newCA(t)
+	bundle := authority.bundle(t)
+	serverCert, serverKey := authority.issue(t, t.TempDir())
+	addr := serve(t, load(t, Config{CertFile: serverCert, KeyFile: serverKey, CAFile: bundle, ClientAuth: true}))
+
+	clientCert, clientKey := authority.issue(t, t.TempDir())
+	if err := check(t, addr, load(t, Config{CertFile: clientCert, KeyFile: clientKey, CAFile: bundle})); err != nil {
+		t.Errorf("Check() with a client certificate error = %v", err)
+	}
+	if err := check(t, addr, load(t, Config{CAFile: bundle})); err == nil {
+		t.Errorf("Check() without a client certificate error = nil, want an error")
+	}
+
+	other := newCA(t)
+	otherCert, otherKey := other.issue(t, t.TempDir())
+	if err := check(t, addr, load(t, Config{CertFile: otherCert, KeyFile: otherKey, CAFile: bundle})); err == nil {
+		t.Errorf("Check() with a certificate of another CA error = nil, want an error")
+	}
+	if err := check(t, addr, load(t, Config{CertFile: clientCert, KeyFile: clientKey, CAFile: other.bundle(t)})); err == nil {
+		t.Errorf("Check() trusting another CA error = nil, want an error")
+	}
+}
+
+func TestReload(t *testing.T) {
+	first, second := newCA(t), newCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := first.issue(t, dir)
+	server := load(t, Config{CertFile: certFile, KeyFile: keyFile})
+	now := time.Now()
+	server.now = func() time.Time { return now }
+	addr := serve(t, server)
+
+	client := load(t, Config{CAFile: second.bundle(t)})
+	if err := check(t, addr, client); err == nil {
+		t.Fatalf("Check() error = nil before the certificate is renewed, want an error")
+	}
+
+	// The renewed certificate is picked up once the files are checked again.
+	second.issue(t, dir)
+	if err := check(t, addr, client); err == nil {
+		t.Fatalf("Check() error = nil before the files are checked again, want an error")
+	}
+	now = now.Add(reloadInterval)
+	if err := check(t, addr, client); err != nil {
+		t.Errorf("Check() error = %v after the certificate is renewed", err)
+	}
+
+	// A broken file keeps the previous certificate.
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(reloadInterval)
+	if err := check(t, addr, client); err != nil {
+		t.Errorf("Check() error = %v with a broken certificate file", err)
+	}
+}
+
+func TestPlaintext(t *testing.T) {
+	certs := load(t, Config{})
+	if certs.Server() != nil {
+		t.Errorf("Server() = %v, want nil", certs.Server())
+	}
+	if err := check(t, serve(t, certs), certs); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if opts := certs.NATSOptions(); len(opts) != 0 {
+		t.Errorf("NATSOptions() = %d options, want none", len(opts))
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	authority := newCA(t)
+	certFile, keyFile := authority.issue(t, t.TempDir())
+	for _, cfg := range []Config{
+		{CertFile: certFile},
+		{CertFile: certFile, KeyFile: keyFile, ClientAuth: true},
+		{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{CAFile: keyFile},
+	} {
+		if _, err := Load(cfg); err == nil {
+			t.Errorf("Load(%+v) error = nil, want an error", cfg)
+		}
+	}
+}
+
+func TestPostgresDSN(t *testing.T) {
+	certs := load(t, Config{})
+	if got, err := certs.PostgresDSN("host=db sslmode=disable"); err != nil || got != "host=db sslmode=disable" {
+		t.Errorf("PostgresDSN() without TLS = %q, %v, want it unchanged", got, err)
+	}
+
+	authority := newCA(t)
+	bundle := authority.bundle(t)
+	certFile, keyFile := authority.issue(t, t.TempDir())
+	certs = load(t, Config{CertFile: certFile, KeyFile: keyFile, CAFile: bundle})
+	tests := []struct {
+		dsn, want string
+	}{
+		{"host=db sslmode=verify-full sslrootcert=/etc/ca.pem", "host=db sslmode=verify-full sslrootcert=/etc/ca.pem"},
+		{"host=db dbname=portal", "host=db dbname=portal sslmode='verify-full' sslrootcert='" + bundle + "' sslcert='" + certFile + "' sslkey='" + keyFile + "'"},
+	}
+	for _, tt := range tests {
+		if got, err := certs.PostgresDSN(tt.dsn); err != nil || got != tt.want {
+			t.Errorf("PostgresDSN(%q) = %q, %v, want %q", tt.dsn, got, err, tt.want)
+		}
+	}
+
+	// A CA bundle is never silently ignored.
+	for _, dsn := range []string{
+		"host=db sslmode=disable",
+		"host=db sslmode = 'prefer'",
+		"sslmode=require host=db",
+		"postgres://u@db/portal?sslmode=disable",
+	} {
+		if got, err := certs.PostgresDSN(dsn); err == nil {
+			t.Errorf("PostgresDSN(%q) = %q, want an error", dsn, got)
+		}
+	}
+
+	dsn, err := certs.PostgresDSN("postgres://u@db/portal?connect_timeout=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{"connect_timeout": {"5"}, "sslmode": {"verify-full"}, "sslrootcert": {bundle}, "sslcert": {certFile}, "sslkey": {keyFile}}
+	if got := u.Query(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PostgresDSN() of a URL has parameters %v, want %v", got, want)
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	var cfg Config
+	loader := conf.New("test")
+	RegisterFlags(loader, &cfg, GRPCServer)
+	if err := loader.Load([]string{"-tls-cert=c.pem", "-tls-key=k.pem", "-tls-ca=ca.pem", "-tls-client-auth"}); err != nil {
+		t.Fatal(err)
+	}
+	want := Config{CertFile: "c.pem", KeyFile: "k.pem", CAFile: "ca.pem", ClientAuth: true}
+	if cfg != want {
+		t.Errorf("RegisterFlags() loaded %+v, want %+v", cfg, want)
+	}
+
+	cfg = Config{}
+	loader = conf.New("test")
+	RegisterFlags(loader, &cfg, Client)
+	if err := loader.Load([]string{"-tls-ca=ca.pem"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Config{CAFile: "ca.pem"}); cfg != want {
+		t.Errorf("RegisterFlags() loaded %+v, want %+v", cfg, want)
+	}
+}
@@ -0,0 +1,83 @@
+// Package urlnorm puts page URLs in the canonical form experts are stored
+// under, so the crawler, the expert service and the gateway agree on which
+// expert a URL names however it was written.
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Canonical returns the canonical form of a page URL:
+//
+//   - a bare domain or path such as example.com/docs gets the https scheme;
+//   - the scheme and host are lowercased, and a trailing dot and the
+//     scheme's default port are removed from the host;
+//   - user info and the fragment are dropped;
+//   - an empty path becomes "/", dot segments are resolved and percent
+//     escapes are normalized;
+//   - the query string is kept as is, except that an empty one is dropped.
+//
+// Only http and https URLs are accepted.
+func Canonical(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", errors.New("empty URL")
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	defaultPort := map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	if defaultPort == "" {
+		return "", fmt.Errorf("invalid URL %q: scheme must be http or https", raw)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", fmt.Errorf("invalid URL %q: missing host", raw)
+	}
+	if port := u.Port(); port != "" && port != defaultPort {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+
+	u.User = nil
+	u.Fragment, u.RawFragment = "", ""
+	u.ForceQuery = false
+	// Re-encode the path from its decoded form, unless it contains an
+	// escaped slash that decoding would turn into a separator.
+	if !strings.Contains(strings.ToUpper(u.RawPath), "%2F") {
+		u.RawPath = ""
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	// Resolving an empty reference removes dot segments from the path.
+	u = u.ResolveReference(&url.URL{})
+	return u.String(), nil
+}
+
+// Candidates returns the canonical URLs raw may name, in order of
+// preference. A URL with a scheme names a single page; a bare domain may be
+// served over https or plain http.
+func Candidates(raw string) ([]string, error) {
+	c, err := Canonical(raw)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(raw, "://") {
+		return []string{c}, nil
+	}
+	return []string{c, "http://" + strings.TrimPrefix(c, "https://")}, nil
+}
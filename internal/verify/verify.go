@@ -0,0 +1,262 @@
+// Package verify checks whether the claims of an answer are backed by the
+// content it was generated from, so answers an LLM made up can be flagged.
+//
+// Each sentence of an answer is a claim. Claims are scored by lexical
+// entailment heuristics.
+package verify
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"portal.com/portal/internal/query"
+)
+
+// DefaultThreshold is the support below which claims are unsupported.
+const DefaultThreshold = 0.5
+
+// Claim is a sentence of an answer and how well the content supports it.
+type Claim struct {
+	Text string
+	// Support is between 0, not backed by the content at all, and 1, stated
+	// by the content.
+	Support float64
+	// Supported reports whether Support reaches the verifier's threshold.
+	Supported bool
+	// Evidence is the passage backing the claim best, or empty.
+	Evidence string
+}
+
+// Result is the verification of an answer.
+type Result struct {
+	Claims []Claim
+	// Verifier names what verified the claims, such as "lexical".
+	Verifier string
+}
+
+// Unsupported returns the number of claims not supported.
+func (r *Result) Unsupported() int {
+	n := 0
+	for _, c := range r.Claims {
+		if !c.Supported {
+			n++
+		}
+	}
+	return n
+}
+
+// Verifier verifies answers.
+type Verifier interface {
+	// Verify returns the verification of answer against the passages it was
+	// generated from. It does not fail: a verifier that cannot do better
+	// returns the lexical verification.
+	Verify(ctx context.Context, answer string, passages []string) *Result
+}
+
+// Lexical verifies claims by the words they share with the content: a claim
+// is supported by a sentence, or two consecutive ones, that contains its
+// keywords, mostly in the same order, with the same numbers and the same
+// negation. It is fast and free, but takes a paraphrase for an unsupported
+// claim and cannot tell what the words mean.
+type Lexical struct {
+	// Threshold is the support below which claims are unsupported.
+	Threshold float64
+}
+
+var (
+	// negations reverse the meaning of a sentence.
+	negations = map[string]bool{"not": true, "no": true, "never": true, "none": true, "nor": true, "cannot": true, "without": true}
+	// abbreviations end with a period that does not end a sentence.
+	abbreviations = map[string]bool{"e.g": true, "i.e": true, "etc": true, "vs": true, "mr": true, "mrs": true, "ms": true, "dr": true, "inc": true}
+
+	listMarker = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s+`)
+)
+
+// Verify implements Verifier.
+func (l Lexical) Verify(ctx context.Context, answer string, passages []string) *Result {
+	var windows []*terms
+	for _, p := range passages {
+		sentences := Sentences(p)
+		for i, s := range sentences {
+			windows = append(windows, newTerms(s))
+			if i+1 < len(sentences) {
+				windows = append(windows, newTerms(s+" "+sentences[i+1]))
+			}
+		}
+	}
+	res := &Result{Verifier: "lexical"}
+	for _, text := range Claims(answer) {
+		claim := newTerms(text)
+		c := Claim{Text: text}
+		for _, w := range windows {
+			if score := claim.supportIn(w); score > c.Support {
+				c.Support, c.Evidence = score, w.text
+			}
+		}
+		c.Supported = c.Support >= l.Threshold
+		res.Claims = append(res.Claims, c)
+	}
+	return res
+}
+
+// terms are the words of a text that matter for verification.
+type terms struct {
+	text    string
+	lower   string
+	words   []string
+	set     map[string]bool
+	bigrams map[[2]string]bool
+	numbers []string
+	negated bool
+}
+
+func newTerms(text string) *terms {
+	t := &terms{text: text, lower: strings.ToLower(text), set: map[string]bool{}, bigrams: map[[2]string]bool{}}
+	for _, w := range strings.Fields(t.lower) {
+		w = strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' })
+		if negations[w] || strings.HasSuffix(w, "n't") {
+			t.negated = !t.negated
+		}
+	}
+	for _, w := range strings.Fields(query.Keywords(text)) {
+		if negations[w] || strings.HasSuffix(w, "n't") {
+			continue
+		}
+		if strings.ContainsFunc(w, unicode.IsDigit) {
+			t.numbers = append(t.numbers, w)
+		}
+		w = stem(w)
+		if n := len(t.words); n > 0 {
+			t.bigrams[[2]string{t.words[n-1], w}] = true
+		}
+		t.words = append(t.words, w)
+		t.set[w] = true
+	}
+	return t
+}
+
+// supportIn scores how well the text of w supports the claim t.
+func (t *terms) supportIn(w *terms) float64 {
+	if len(t.words) == 0 {
+		return 0
+	}
+	matched := 0
+	for _, word := range t.words {
+		if w.set[word] {
+			matched++
+		}
+	}
+	coverage := float64(matched) / float64(len(t.words))
+	order := coverage
+	if len(t.words) > 1 {
+		pairs := 0
+		for i := 1; i < len(t.words); i++ {
+			if w.bigrams[[2]string{t.words[i-1], t.words[i]}] {
+				pairs++
+			}
+		}
+		order = float64(pairs) / float64(len(t.words)-1)
+	}
+	score := 0.7*coverage + 0.3*order
+	for _, n := range t.numbers {
+		if !strings.Contains(w.lower, n) {
+			// A number the content does not state is likely wrong.
+			score /= 2
+			break
+		}
+	}
+	if t.negated != w.negated {
+		// The content likely says the opposite.
+		score /= 4
+	}
+	return score
+}
+
+// stem removes the common English inflections of a lowercased word, so
+// "released" matches "release".
+func stem(w string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if len(w) > len(suffix)+2 && strings.HasSuffix(w, suffix) {
+			w = w[:len(w)-len(suffix)]
+			break
+		}
+	}
+	if len(w) > 3 {
+		w = strings.TrimSuffix(w, "e")
+	}
+	return w
+}
+
+// Claims returns the claims of answer: its sentences that state something,
+// leaving out questions and sentences of fewer than two keywords, such as
+// "Sure!".
+func Claims(answer string) []string {
+	var claims []string
+	for _, s := range Sentences(answer) {
+		if !strings.HasSuffix(s, "?") && len(strings.Fields(query.Keywords(s))) >= 2 {
+			claims = append(claims, s)
+		}
+	}
+	return claims
+}
+
+// Sentences splits text into sentences. Lines, such as list items, are
+// sentences of their own, without their list markers.
+func Sentences(text string) []string {
+	var sentences []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(listMarker.ReplaceAllString(line, ""))
+		start := 0
+		for i := 0; i < len(line); i++ {
+			if !strings.ContainsRune(".!?", rune(line[i])) || !sentenceEnds(line, start, i) {
+				continue
+			}
+			// Closing quotes and brackets belong to the sentence.
+			end := i + 1
+			for end < len(line) && strings.ContainsRune(`"')]`, rune(line[end])) {
+				end++
+			}
+			if s := strings.TrimSpace(line[start:end]); s != "" {
+				sentences = append(sentences, s)
+			}
+			start, i = end, end-1
+		}
+		if s := strings.TrimSpace(line[start:]); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
+
+// sentenceEnds reports whether the punctuation at line[i] ends the sentence
+// started at line[start]: it is followed by a space and an upper case
+// letter, a digit or a quote, and is not the period of an abbreviation.
+func sentenceEnds(line string, start, i int) bool {
+	j := i + 1
+	for j < len(line) && strings.ContainsRune(`"')]`, rune(line[j])) {
+		j++
+	}
+	if j >= len(line) || line[j] != ' ' {
+		return false
+	}
+	next := strings.TrimLeft(line[j:], " ")
+	if next == "" {
+		return false
+	}
+	if r := rune(next[0]); !unicode.IsUpper(r) && !unicode.IsDigit(r) && r != '"' && r != '\'' {
+		return false
+	}
+	if line[i] == '.' {
+		fields := strings.Fields(line[start:i])
+		if len(fields) == 0 {
+			return false
+		}
+		word := strings.ToLower(strings.TrimLeft(fields[len(fields)-1], `"'(`))
+		if abbreviations[word] || len(word) == 1 {
+			return false
+		}
+	}
+	return true
+}
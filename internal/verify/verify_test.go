@@ -0,0 +1,59 @@
+package verify
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+const content = `Colly is a scraping framework for Go. It was released in 2017 under the Apache 2.0 license.
+Colly does not execute JavaScript. Pages are fetched concurrently, e.g. with the Async option.`
+
+func TestSentences(t *testing.T) {
+	got := Sentences("Colly is fast, e.g. 1k pages/s. Version 2.1 is out! Is it stable?\n- First item\n2. Second item (see \"docs.\") Done.")
+	want := []string{"Colly is fast, e.g. 1k pages/s.", "Version 2.1 is out!", "Is it stable?", "First item", "Second item (see \"docs.\")", "Done."}
+	if !slices.Equal(got, want) {
+		t.Errorf("Sentences() = %q, want %q", got, want)
+	}
+}
+
+func TestLexical(t *testing.T) {
+	tests := []struct {
+		claim     string
+		supported bool
+	}{
+		{"Colly is a Go scraping framework.", true},
+		{"Colly was released in 2017.", true},
+		{"It uses the Apache 2.0 license.", true},
+		{"Colly was released in 2019.", false},
+		{"Colly executes JavaScript.", false},
+		{"Colly does not execute JavaScript.", true},
+		{"Colly is written in Rust and supports WebAssembly plugins.", false},
+		{"Pages are fetched concurrently with the Async option.", true},
+	}
+	for _, tt := range tests {
+		res := Lexical{Threshold: DefaultThreshold}.Verify(context.Background(), tt.claim, []string{content})
+		if len(res.Claims) != 1 {
+			t.Fatalf("Verify(%q) has %d claims, want 1", tt.claim, len(res.Claims))
+		}
+		c := res.Claims[0]
+		if c.Supported != tt.supported {
+			t.Errorf("Verify(%q) = support %.2f from %q, want supported %v", tt.claim, c.Support, c.Evidence, tt.supported)
+		}
+	}
+}
+
+func TestLexicalClaims(t *testing.T) {
+	res := Lexical{Threshold: DefaultThreshold}.Verify(context.Background(),
+		"Sure! Colly was released in 2017. Do you want to know more? It is maintained by Google.", []string{content})
+	var claims []string
+	for _, c := range res.Claims {
+		claims = append(claims, c.Text)
+	}
+	if want := []string{"Colly was released in 2017.", "It is maintained by Google."}; !slices.Equal(claims, want) {
+		t.Fatalf("claims = %q, want %q", claims, want)
+	}
+	if res.Unsupported() != 1 || res.Claims[0].Evidence == "" || res.Verifier != "lexical" {
+		t.Errorf("Verify() = %+v, want the second claim unsupported", res)
+	}
+}
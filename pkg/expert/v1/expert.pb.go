@@ -7,8 +7,10 @@
 package v1
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -20,6 +22,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ExpertLevel is the place of an expert in the hierarchy.
+type ExpertLevel int32
+
+const (
+	ExpertLevel_EXPERT_LEVEL_UNSPECIFIED ExpertLevel = 0
+	ExpertLevel_EXPERT_LEVEL_ROOT        ExpertLevel = 1
+	ExpertLevel_EXPERT_LEVEL_MIDDLEMAN   ExpertLevel = 2
+	ExpertLevel_EXPERT_LEVEL_LEAF        ExpertLevel = 3
+)
+
+// Enum value maps for ExpertLevel.
+var (
+	ExpertLevel_name = map[int32]string{
+		0: "EXPERT_LEVEL_UNSPECIFIED",
+		1: "EXPERT_LEVEL_ROOT",
+		2: "EXPERT_LEVEL_MIDDLEMAN",
+		3: "EXPERT_LEVEL_LEAF",
+	}
+	ExpertLevel_value = map[string]int32{
+		"EXPERT_LEVEL_UNSPECIFIED": 0,
+		"EXPERT_LEVEL_ROOT":        1,
+		"EXPERT_LEVEL_MIDDLEMAN":   2,
+		"EXPERT_LEVEL_LEAF":        3,
+	}
+)
+
+func (x ExpertLevel) Enum() *ExpertLevel {
+	p := new(ExpertLevel)
+	*p = x
+	return p
+}
+
+func (x ExpertLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExpertLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_expert_v1_expert_proto_enumTypes[0].Descriptor()
+}
+
+func (ExpertLevel) Type() protoreflect.EnumType {
+	return &file_api_expert_v1_expert_proto_enumTypes[0]
+}
+
+func (x ExpertLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExpertLevel.Descriptor instead.
+func (ExpertLevel) EnumDescriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{0}
+}
+
 type ExpertType int32
 
 const (
@@ -53,11 +108,11 @@ func (x ExpertType) String() string {
 }
 
 func (ExpertType) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_expert_v1_expert_proto_enumTypes[0].Descriptor()
+	return file_api_expert_v1_expert_proto_enumTypes[1].Descriptor()
 }
 
 func (ExpertType) Type() protoreflect.EnumType {
-	return &file_api_expert_v1_expert_proto_enumTypes[0]
+	return &file_api_expert_v1_expert_proto_enumTypes[1]
 }
 
 func (x ExpertType) Number() protoreflect.EnumNumber {
@@ -66,7 +121,59 @@ func (x ExpertType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ExpertType.Descriptor instead.
 func (ExpertType) EnumDescriptor() ([]byte, []int) {
-	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{0}
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{1}
+}
+
+// ExpertStatus tells whether a QueryExpertResponse holds an answer.
+type ExpertStatus int32
+
+const (
+	ExpertStatus_EXPERT_STATUS_UNSPECIFIED ExpertStatus = 0
+	// The expert answered.
+	ExpertStatus_EXPERT_STATUS_READY ExpertStatus = 1
+	// The expert is still being created from its page; there is no answer yet.
+	ExpertStatus_EXPERT_STATUS_PENDING ExpertStatus = 2
+)
+
+// Enum value maps for ExpertStatus.
+var (
+	ExpertStatus_name = map[int32]string{
+		0: "EXPERT_STATUS_UNSPECIFIED",
+		1: "EXPERT_STATUS_READY",
+		2: "EXPERT_STATUS_PENDING",
+	}
+	ExpertStatus_value = map[string]int32{
+		"EXPERT_STATUS_UNSPECIFIED": 0,
+		"EXPERT_STATUS_READY":       1,
+		"EXPERT_STATUS_PENDING":     2,
+	}
+)
+
+func (x ExpertStatus) Enum() *ExpertStatus {
+	p := new(ExpertStatus)
+	*p = x
+	return p
+}
+
+func (x ExpertStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExpertStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_expert_v1_expert_proto_enumTypes[2].Descriptor()
+}
+
+func (ExpertStatus) Type() protoreflect.EnumType {
+	return &file_api_expert_v1_expert_proto_enumTypes[2]
+}
+
+func (x ExpertStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExpertStatus.Descriptor instead.
+func (ExpertStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{2}
 }
 
 type CreateOrUpdateExpertRequest struct {
@@ -77,6 +184,8 @@ type CreateOrUpdateExpertRequest struct {
 	Url        string     `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
 	Content    string     `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
 	ExpertType ExpertType `protobuf:"varint,3,opt,name=expert_type,json=expertType,proto3,enum=expert.v1.ExpertType" json:"expert_type,omitempty"`
+	// language is the language tag the page declares, e.g. "en-US", if any.
+	Language string `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
 }
 
 func (x *CreateOrUpdateExpertRequest) Reset() {
@@ -132,6 +241,13 @@ func (x *CreateOrUpdateExpertRequest) GetExpertType() ExpertType {
 	return ExpertType_EXPERT_TYPE_UNSPECIFIED
 }
 
+func (x *CreateOrUpdateExpertRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
 type CreateOrUpdateExpertResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -185,7 +301,16 @@ type QueryExpertRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Url   string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	Query string `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"` // We can add conversation history later if needed.
+	Query string `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	// fetch_if_missing creates the expert from the page at url when none
+	// exists yet, instead of failing with NOT_FOUND. If the page cannot be
+	// indexed in time the response is PENDING; repeat the request to poll.
+	FetchIfMissing bool `protobuf:"varint,3,opt,name=fetch_if_missing,json=fetchIfMissing,proto3" json:"fetch_if_missing,omitempty"`
+	// retrieval_queries are other forms of the query, such as keyword
+	// rewrites or the parts of a compound question. RAG experts retrieve
+	// context for each of them as well as for the query; the answer is still
+	// to the query.
+	RetrievalQueries []string `protobuf:"bytes,4,rep,name=retrieval_queries,json=retrievalQueries,proto3" json:"retrieval_queries,omitempty"` // We can add conversation history later if needed.
 }
 
 func (x *QueryExpertRequest) Reset() {
@@ -234,12 +359,35 @@ func (x *QueryExpertRequest) GetQuery() string {
 	return ""
 }
 
+func (x *QueryExpertRequest) GetFetchIfMissing() bool {
+	if x != nil {
+		return x.FetchIfMissing
+	}
+	return false
+}
+
+func (x *QueryExpertRequest) GetRetrievalQueries() []string {
+	if x != nil {
+		return x.RetrievalQueries
+	}
+	return nil
+}
+
 type QueryExpertResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Answer string `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`
+	Answer string       `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`
+	Status ExpertStatus `protobuf:"varint,2,opt,name=status,proto3,enum=expert.v1.ExpertStatus" json:"status,omitempty"`
+	// retry_after_seconds is how long to wait before polling a PENDING expert.
+	RetryAfterSeconds int32 `protobuf:"varint,3,opt,name=retry_after_seconds,json=retryAfterSeconds,proto3" json:"retry_after_seconds,omitempty"`
+	// verification checks each claim of the answer against the content it
+	// was generated from. It is unset if verification is disabled.
+	Verification *AnswerVerification `protobuf:"bytes,4,opt,name=verification,proto3" json:"verification,omitempty"`
+	// passages are the parts of the page the answer was generated from, most
+	// relevant to the query first.
+	Passages []*Passage `protobuf:"bytes,5,rep,name=passages,proto3" json:"passages,omitempty"`
 }
 
 func (x *QueryExpertResponse) Reset() {
@@ -281,132 +429,1519 @@ func (x *QueryExpertResponse) GetAnswer() string {
 	return ""
 }
 
-var File_api_expert_v1_expert_proto protoreflect.FileDescriptor
-
-var file_api_expert_v1_expert_proto_rawDesc = []byte{
-	0x0a, 0x1a, 0x61, 0x70, 0x69, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2f, 0x76, 0x31, 0x2f,
-	0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x65, 0x78,
-	0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x22, 0x81, 0x01, 0x0a, 0x1b, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e,
-	0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x12, 0x36, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x5f, 0x74, 0x79,
-	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72,
-	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52,
-	0x0a, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x22, 0x3b, 0x0a, 0x1c, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70,
-	0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x65,
-	0x78, 0x70, 0x65, 0x72, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x49, 0x64, 0x22, 0x3c, 0x0a, 0x12, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10,
-	0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c,
-	0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x22, 0x2d, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x45,
-	0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a,
-	0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61,
-	0x6e, 0x73, 0x77, 0x65, 0x72, 0x2a, 0x56, 0x0a, 0x0a, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54,
-	0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x17, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f, 0x54, 0x59,
-	0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
-	0x12, 0x16, 0x0a, 0x12, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
-	0x53, 0x49, 0x4d, 0x50, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x58, 0x50, 0x45,
-	0x52, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x41, 0x47, 0x10, 0x02, 0x32, 0xca, 0x01,
-	0x0a, 0x0d, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
-	0x69, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x12, 0x26, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74,
-	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x27, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0b, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x12, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x65,
-	0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x45, 0x78, 0x70, 0x65, 0x72,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72,
-	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x21, 0x5a, 0x1f, 0x70, 0x6f,
-	0x72, 0x74, 0x61, 0x6c, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2f,
-	0x70, 0x6b, 0x67, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *QueryExpertResponse) GetStatus() ExpertStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ExpertStatus_EXPERT_STATUS_UNSPECIFIED
 }
 
-var (
-	file_api_expert_v1_expert_proto_rawDescOnce sync.Once
-	file_api_expert_v1_expert_proto_rawDescData = file_api_expert_v1_expert_proto_rawDesc
-)
+func (x *QueryExpertResponse) GetRetryAfterSeconds() int32 {
+	if x != nil {
+		return x.RetryAfterSeconds
+	}
+	return 0
+}
 
-func file_api_expert_v1_expert_proto_rawDescGZIP() []byte {
-	file_api_expert_v1_expert_proto_rawDescOnce.Do(func() {
-		file_api_expert_v1_expert_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_expert_v1_expert_proto_rawDescData)
-	})
-	return file_api_expert_v1_expert_proto_rawDescData
+func (x *QueryExpertResponse) GetVerification() *AnswerVerification {
+	if x != nil {
+		return x.Verification
+	}
+	return nil
 }
 
-var file_api_expert_v1_expert_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_api_expert_v1_expert_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_api_expert_v1_expert_proto_goTypes = []interface{}{
-	(ExpertType)(0),                      // 0: expert.v1.ExpertType
-	(*CreateOrUpdateExpertRequest)(nil),  // 1: expert.v1.CreateOrUpdateExpertRequest
-	(*CreateOrUpdateExpertResponse)(nil), // 2: expert.v1.CreateOrUpdateExpertResponse
-	(*QueryExpertRequest)(nil),           // 3: expert.v1.QueryExpertRequest
-	(*QueryExpertResponse)(nil),          // 4: expert.v1.QueryExpertResponse
+func (x *QueryExpertResponse) GetPassages() []*Passage {
+	if x != nil {
+		return x.Passages
+	}
+	return nil
 }
-var file_api_expert_v1_expert_proto_depIdxs = []int32{
-	0, // 0: expert.v1.CreateOrUpdateExpertRequest.expert_type:type_name -> expert.v1.ExpertType
-	1, // 1: expert.v1.ExpertService.CreateOrUpdateExpert:input_type -> expert.v1.CreateOrUpdateExpertRequest
-	3, // 2: expert.v1.ExpertService.QueryExpert:input_type -> expert.v1.QueryExpertRequest
-	2, // 3: expert.v1.ExpertService.CreateOrUpdateExpert:output_type -> expert.v1.CreateOrUpdateExpertResponse
-	4, // 4: expert.v1.ExpertService.QueryExpert:output_type -> expert.v1.QueryExpertResponse
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+
+// Passage is a part of a page's content.
+type Passage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// chunk_index is the position of the passage in the page, from 0: the
+	// index of the chunk for RAG experts, and of the line for simple experts.
+	ChunkIndex int32 `protobuf:"varint,2,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	// start_offset and end_offset are the offsets of the passage in the page's
+	// content, in characters (Unicode code points); end_offset is exclusive.
+	// Both are 0 for chunks indexed before offsets were recorded.
+	StartOffset int32 `protobuf:"varint,3,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"`
+	EndOffset   int32 `protobuf:"varint,4,opt,name=end_offset,json=endOffset,proto3" json:"end_offset,omitempty"`
+	// heading is the heading of the section the passage starts in, if any.
+	Heading string `protobuf:"bytes,5,opt,name=heading,proto3" json:"heading,omitempty"`
+	// score is how relevant the passage is to the query, from 0 to 1: the
+	// similarity of the chunk for RAG experts, and the share of the query's
+	// keywords it contains for simple experts.
+	Score float32 `protobuf:"fixed32,6,opt,name=score,proto3" json:"score,omitempty"`
 }
 
-func init() { file_api_expert_v1_expert_proto_init() }
-func file_api_expert_v1_expert_proto_init() {
-	if File_api_expert_v1_expert_proto != nil {
-		return
+func (x *Passage) Reset() {
+	*x = Passage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_api_expert_v1_expert_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateOrUpdateExpertRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+}
+
+func (x *Passage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Passage) ProtoMessage() {}
+
+func (x *Passage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_api_expert_v1_expert_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateOrUpdateExpertResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Passage.ProtoReflect.Descriptor instead.
+func (*Passage) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Passage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Passage) GetChunkIndex() int32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+func (x *Passage) GetStartOffset() int32 {
+	if x != nil {
+		return x.StartOffset
+	}
+	return 0
+}
+
+func (x *Passage) GetEndOffset() int32 {
+	if x != nil {
+		return x.EndOffset
+	}
+	return 0
+}
+
+func (x *Passage) GetHeading() string {
+	if x != nil {
+		return x.Heading
+	}
+	return ""
+}
+
+func (x *Passage) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// Claim is a sentence of an answer.
+type Claim struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// support is how well the content backs the claim, from 0 (not at all)
+	// to 1 (stated by the content).
+	Support float32 `protobuf:"fixed32,2,opt,name=support,proto3" json:"support,omitempty"`
+	// supported is false for claims whose support is below the threshold of
+	// the verifier; they may be made up.
+	Supported bool `protobuf:"varint,3,opt,name=supported,proto3" json:"supported,omitempty"`
+	// evidence is the passage of the content that backs the claim best, if
+	// any.
+	Evidence string `protobuf:"bytes,4,opt,name=evidence,proto3" json:"evidence,omitempty"`
+}
+
+func (x *Claim) Reset() {
+	*x = Claim{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Claim) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Claim) ProtoMessage() {}
+
+func (x *Claim) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_api_expert_v1_expert_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryExpertRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Claim.ProtoReflect.Descriptor instead.
+func (*Claim) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Claim) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Claim) GetSupport() float32 {
+	if x != nil {
+		return x.Support
+	}
+	return 0
+}
+
+func (x *Claim) GetSupported() bool {
+	if x != nil {
+		return x.Supported
+	}
+	return false
+}
+
+func (x *Claim) GetEvidence() string {
+	if x != nil {
+		return x.Evidence
+	}
+	return ""
+}
+
+type AnswerVerification struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Claims []*Claim `protobuf:"bytes,1,rep,name=claims,proto3" json:"claims,omitempty"`
+	// unsupported_claims is the number of claims not supported.
+	UnsupportedClaims int32 `protobuf:"varint,2,opt,name=unsupported_claims,json=unsupportedClaims,proto3" json:"unsupported_claims,omitempty"`
+	// verifier names what verified the claims, such as "lexical".
+	Verifier string `protobuf:"bytes,3,opt,name=verifier,proto3" json:"verifier,omitempty"`
+}
+
+func (x *AnswerVerification) Reset() {
+	*x = AnswerVerification{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnswerVerification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerVerification) ProtoMessage() {}
+
+func (x *AnswerVerification) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_api_expert_v1_expert_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryExpertResponse); i {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerVerification.ProtoReflect.Descriptor instead.
+func (*AnswerVerification) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AnswerVerification) GetClaims() []*Claim {
+	if x != nil {
+		return x.Claims
+	}
+	return nil
+}
+
+func (x *AnswerVerification) GetUnsupportedClaims() int32 {
+	if x != nil {
+		return x.UnsupportedClaims
+	}
+	return 0
+}
+
+func (x *AnswerVerification) GetVerifier() string {
+	if x != nil {
+		return x.Verifier
+	}
+	return ""
+}
+
+type GetContentVersionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Urls []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+}
+
+func (x *GetContentVersionsRequest) Reset() {
+	*x = GetContentVersionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetContentVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContentVersionsRequest) ProtoMessage() {}
+
+func (x *GetContentVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContentVersionsRequest.ProtoReflect.Descriptor instead.
+func (*GetContentVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetContentVersionsRequest) GetUrls() []string {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+type GetContentVersionsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// versions maps the canonical URL of each existing expert to its content
+	// version, which changes whenever CreateOrUpdateExpert changes the expert.
+	// URLs without an expert are left out.
+	Versions map[string]int64 `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *GetContentVersionsResponse) Reset() {
+	*x = GetContentVersionsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetContentVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContentVersionsResponse) ProtoMessage() {}
+
+func (x *GetContentVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContentVersionsResponse.ProtoReflect.Descriptor instead.
+func (*GetContentVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetContentVersionsResponse) GetVersions() map[string]int64 {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type Expert struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// url is empty for experts above the leaves.
+	Url   string      `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Level ExpertLevel `protobuf:"varint,4,opt,name=level,proto3,enum=expert.v1.ExpertLevel" json:"level,omitempty"`
+	// expert_type is unspecified for experts above the leaves.
+	ExpertType ExpertType             `protobuf:"varint,5,opt,name=expert_type,json=expertType,proto3,enum=expert.v1.ExpertType" json:"expert_type,omitempty"`
+	ChunkCount int32                  `protobuf:"varint,6,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// language is the lowercased language tag of the page, if known.
+	Language string `protobuf:"bytes,9,opt,name=language,proto3" json:"language,omitempty"`
+}
+
+func (x *Expert) Reset() {
+	*x = Expert{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Expert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Expert) ProtoMessage() {}
+
+func (x *Expert) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Expert.ProtoReflect.Descriptor instead.
+func (*Expert) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Expert) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Expert) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Expert) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Expert) GetLevel() ExpertLevel {
+	if x != nil {
+		return x.Level
+	}
+	return ExpertLevel_EXPERT_LEVEL_UNSPECIFIED
+}
+
+func (x *Expert) GetExpertType() ExpertType {
+	if x != nil {
+		return x.ExpertType
+	}
+	return ExpertType_EXPERT_TYPE_UNSPECIFIED
+}
+
+func (x *Expert) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *Expert) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Expert) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Expert) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type ListExpertsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// page_size defaults to 50 and is capped at 500.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Filters; unset filters match every expert.
+	ExpertType ExpertType `protobuf:"varint,3,opt,name=expert_type,json=expertType,proto3,enum=expert.v1.ExpertType" json:"expert_type,omitempty"`
+	// domain matches experts whose URL host is one of the domains or a
+	// subdomain.
+	Domain []string `protobuf:"bytes,4,rep,name=domain,proto3" json:"domain,omitempty"`
+	// updated_before matches experts not updated since, i.e. stale ones.
+	UpdatedBefore *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_before,json=updatedBefore,proto3" json:"updated_before,omitempty"`
+	// exclude_domain skips experts whose URL host is one of the domains or a
+	// subdomain.
+	ExcludeDomain []string `protobuf:"bytes,6,rep,name=exclude_domain,json=excludeDomain,proto3" json:"exclude_domain,omitempty"`
+	// language matches experts whose page is in the language or a variant
+	// of it, e.g. "en" matches "en-us".
+	Language string `protobuf:"bytes,7,opt,name=language,proto3" json:"language,omitempty"`
+	// updated_after matches experts updated since.
+	UpdatedAfter *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_after,json=updatedAfter,proto3" json:"updated_after,omitempty"`
+	Level        ExpertLevel            `protobuf:"varint,9,opt,name=level,proto3,enum=expert.v1.ExpertLevel" json:"level,omitempty"`
+}
+
+func (x *ListExpertsRequest) Reset() {
+	*x = ListExpertsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListExpertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExpertsRequest) ProtoMessage() {}
+
+func (x *ListExpertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExpertsRequest.ProtoReflect.Descriptor instead.
+func (*ListExpertsRequest) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListExpertsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListExpertsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListExpertsRequest) GetExpertType() ExpertType {
+	if x != nil {
+		return x.ExpertType
+	}
+	return ExpertType_EXPERT_TYPE_UNSPECIFIED
+}
+
+func (x *ListExpertsRequest) GetDomain() []string {
+	if x != nil {
+		return x.Domain
+	}
+	return nil
+}
+
+func (x *ListExpertsRequest) GetUpdatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedBefore
+	}
+	return nil
+}
+
+func (x *ListExpertsRequest) GetExcludeDomain() []string {
+	if x != nil {
+		return x.ExcludeDomain
+	}
+	return nil
+}
+
+func (x *ListExpertsRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ListExpertsRequest) GetUpdatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAfter
+	}
+	return nil
+}
+
+func (x *ListExpertsRequest) GetLevel() ExpertLevel {
+	if x != nil {
+		return x.Level
+	}
+	return ExpertLevel_EXPERT_LEVEL_UNSPECIFIED
+}
+
+type ListExpertsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Experts []*Expert `protobuf:"bytes,1,rep,name=experts,proto3" json:"experts,omitempty"`
+	// next_page_token is empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *ListExpertsResponse) Reset() {
+	*x = ListExpertsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListExpertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExpertsResponse) ProtoMessage() {}
+
+func (x *ListExpertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExpertsResponse.ProtoReflect.Descriptor instead.
+func (*ListExpertsResponse) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListExpertsResponse) GetExperts() []*Expert {
+	if x != nil {
+		return x.Experts
+	}
+	return nil
+}
+
+func (x *ListExpertsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetExpertRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetExpertRequest) Reset() {
+	*x = GetExpertRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetExpertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExpertRequest) ProtoMessage() {}
+
+func (x *GetExpertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExpertRequest.ProtoReflect.Descriptor instead.
+func (*GetExpertRequest) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetExpertRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetExpertResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Expert *Expert `protobuf:"bytes,1,opt,name=expert,proto3" json:"expert,omitempty"`
+	// embedded_chunk_counts is the number of chunks embedded by each model.
+	EmbeddedChunkCounts map[string]int32 `protobuf:"bytes,2,rep,name=embedded_chunk_counts,json=embeddedChunkCounts,proto3" json:"embedded_chunk_counts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Parents             []*Expert        `protobuf:"bytes,3,rep,name=parents,proto3" json:"parents,omitempty"`
+	Children            []*Expert        `protobuf:"bytes,4,rep,name=children,proto3" json:"children,omitempty"`
+}
+
+func (x *GetExpertResponse) Reset() {
+	*x = GetExpertResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetExpertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExpertResponse) ProtoMessage() {}
+
+func (x *GetExpertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExpertResponse.ProtoReflect.Descriptor instead.
+func (*GetExpertResponse) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetExpertResponse) GetExpert() *Expert {
+	if x != nil {
+		return x.Expert
+	}
+	return nil
+}
+
+func (x *GetExpertResponse) GetEmbeddedChunkCounts() map[string]int32 {
+	if x != nil {
+		return x.EmbeddedChunkCounts
+	}
+	return nil
+}
+
+func (x *GetExpertResponse) GetParents() []*Expert {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
+func (x *GetExpertResponse) GetChildren() []*Expert {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type DeleteExpertRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteExpertRequest) Reset() {
+	*x = DeleteExpertRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteExpertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteExpertRequest) ProtoMessage() {}
+
+func (x *DeleteExpertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteExpertRequest.ProtoReflect.Descriptor instead.
+func (*DeleteExpertRequest) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteExpertRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteExpertResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeletedChunks int32 `protobuf:"varint,1,opt,name=deleted_chunks,json=deletedChunks,proto3" json:"deleted_chunks,omitempty"`
+}
+
+func (x *DeleteExpertResponse) Reset() {
+	*x = DeleteExpertResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteExpertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteExpertResponse) ProtoMessage() {}
+
+func (x *DeleteExpertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteExpertResponse.ProtoReflect.Descriptor instead.
+func (*DeleteExpertResponse) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteExpertResponse) GetDeletedChunks() int32 {
+	if x != nil {
+		return x.DeletedChunks
+	}
+	return 0
+}
+
+type ReindexExpertRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *ReindexExpertRequest) Reset() {
+	*x = ReindexExpertRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReindexExpertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReindexExpertRequest) ProtoMessage() {}
+
+func (x *ReindexExpertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReindexExpertRequest.ProtoReflect.Descriptor instead.
+func (*ReindexExpertRequest) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReindexExpertRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ReindexExpertResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// url is the page the crawler was asked to fetch.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+}
+
+func (x *ReindexExpertResponse) Reset() {
+	*x = ReindexExpertResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_expert_v1_expert_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReindexExpertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReindexExpertResponse) ProtoMessage() {}
+
+func (x *ReindexExpertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_expert_v1_expert_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReindexExpertResponse.ProtoReflect.Descriptor instead.
+func (*ReindexExpertResponse) Descriptor() ([]byte, []int) {
+	return file_api_expert_v1_expert_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReindexExpertResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+var File_api_expert_v1_expert_proto protoreflect.FileDescriptor
+
+var file_api_expert_v1_expert_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x61, 0x70, 0x69, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2f, 0x76, 0x31, 0x2f,
+	0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x65, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9d, 0x01, 0x0a, 0x1b, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x12, 0x36, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a,
+	0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61,
+	0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61,
+	0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x22, 0x3b, 0x0a, 0x1c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x78, 0x70, 0x65, 0x72,
+	0x74, 0x49, 0x64, 0x22, 0x93, 0x01, 0x0a, 0x12, 0x51, 0x75, 0x65, 0x72, 0x79, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x28, 0x0a, 0x10, 0x66, 0x65, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x66, 0x5f, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x66, 0x65,
+	0x74, 0x63, 0x68, 0x49, 0x66, 0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x12, 0x2b, 0x0a, 0x11,
+	0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76, 0x61, 0x6c, 0x5f, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x74, 0x72, 0x69, 0x65, 0x76,
+	0x61, 0x6c, 0x51, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x22, 0x81, 0x02, 0x0a, 0x13, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x65, 0x78, 0x70, 0x65,
+	0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x72, 0x65,
+	0x74, 0x72, 0x79, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11, 0x72, 0x65, 0x74, 0x72, 0x79, 0x41, 0x66,
+	0x74, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x41, 0x0a, 0x0c, 0x76, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x73,
+	0x77, 0x65, 0x72, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0c, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a,
+	0x08, 0x70, 0x61, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0xb0, 0x01,
+	0x0a, 0x07, 0x50, 0x61, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x1f, 0x0a,
+	0x0b, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x21,
+	0x0a, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x4f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x6e, 0x64, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63,
+	0x6f, 0x72, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x02, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65,
+	0x22, 0x6f, 0x0a, 0x05, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x07,
+	0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x75, 0x70, 0x70, 0x6f,
+	0x72, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x73, 0x75, 0x70, 0x70,
+	0x6f, 0x72, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63,
+	0x65, 0x22, 0x89, 0x01, 0x0a, 0x12, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x06, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x06, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x75, 0x6e, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x65,
+	0x64, 0x5f, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11,
+	0x75, 0x6e, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x43, 0x6c, 0x61, 0x69, 0x6d,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x2f, 0x0a,
+	0x19, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x72,
+	0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x22, 0xaa,
+	0x01, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a,
+	0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x33, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x3b,
+	0x0a, 0x0d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xd7, 0x02, 0x0a, 0x06,
+	0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
+	0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x2c, 0x0a, 0x05,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x65, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x36, 0x0a, 0x0b, 0x65, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x15, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65,
+	0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61,
+	0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39,
+	0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x22, 0x95, 0x03, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09,
+	0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67,
+	0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70,
+	0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x36, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x65,
+	0x72, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e,
+	0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x41, 0x0a, 0x0e, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x65,
+	0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0d, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x44, 0x6f, 0x6d, 0x61,
+	0x69, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x3f,
+	0x0a, 0x0d, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x0c, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12,
+	0x2c, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16,
+	0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72,
+	0x74, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0x6a, 0x0a,
+	0x13, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x07, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x07, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74,
+	0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74,
+	0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x22, 0x0a, 0x10, 0x47, 0x65, 0x74,
+	0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0xcd, 0x02,
+	0x0a, 0x11, 0x47, 0x65, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x06, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x06, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x12, 0x69,
+	0x0a, 0x15, 0x65, 0x6d, 0x62, 0x65, 0x64, 0x64, 0x65, 0x64, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e,
+	0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x45, 0x6d, 0x62, 0x65,
+	0x64, 0x64, 0x65, 0x64, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x13, 0x65, 0x6d, 0x62, 0x65, 0x64, 0x64, 0x65, 0x64, 0x43, 0x68,
+	0x75, 0x6e, 0x6b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x07, 0x70, 0x61, 0x72,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x07, 0x70,
+	0x61, 0x72, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72,
+	0x65, 0x6e, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x08, 0x63, 0x68, 0x69,
+	0x6c, 0x64, 0x72, 0x65, 0x6e, 0x1a, 0x46, 0x0a, 0x18, 0x45, 0x6d, 0x62, 0x65, 0x64, 0x64, 0x65,
+	0x64, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x25, 0x0a,
+	0x13, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x22, 0x3d, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x45, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x25, 0x0a, 0x0e,
+	0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x73, 0x22, 0x26, 0x0a, 0x14, 0x52, 0x65, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x45, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x29, 0x0a, 0x15, 0x52,
+	0x65, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x2a, 0x75, 0x0a, 0x0b, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74,
+	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x1c, 0x0a, 0x18, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f,
+	0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f, 0x4c, 0x45,
+	0x56, 0x45, 0x4c, 0x5f, 0x52, 0x4f, 0x4f, 0x54, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x45, 0x58,
+	0x50, 0x45, 0x52, 0x54, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x4d, 0x49, 0x44, 0x44, 0x4c,
+	0x45, 0x4d, 0x41, 0x4e, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54,
+	0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x4c, 0x45, 0x41, 0x46, 0x10, 0x03, 0x2a, 0x56, 0x0a,
+	0x0a, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x17, 0x45,
+	0x58, 0x50, 0x45, 0x52, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x45, 0x58, 0x50, 0x45,
+	0x52, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x49, 0x4d, 0x50, 0x4c, 0x45, 0x10, 0x01,
+	0x12, 0x13, 0x0a, 0x0f, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x52, 0x41, 0x47, 0x10, 0x02, 0x2a, 0x61, 0x0a, 0x0c, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1d, 0x0a, 0x19, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f,
+	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f, 0x53,
+	0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x45, 0x41, 0x44, 0x59, 0x10, 0x01, 0x12, 0x19, 0x0a,
+	0x15, 0x45, 0x58, 0x50, 0x45, 0x52, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50,
+	0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x32, 0xc0, 0x06, 0x0a, 0x0d, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x89, 0x01, 0x0a, 0x14, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x12, 0x26, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x65, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x72,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x3a, 0x01, 0x2a, 0x22,
+	0x15, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x65,
+	0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x12, 0x6e, 0x0a, 0x0b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x45,
+	0x78, 0x70, 0x65, 0x72, 0x74, 0x12, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x3a, 0x01, 0x2a, 0x22,
+	0x15, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73,
+	0x3a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x61, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x24, 0x2e, 0x65,
+	0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x25, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6b, 0x0a, 0x0b, 0x4c, 0x69, 0x73,
+	0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x12, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1d, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17, 0x12,
+	0x15, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x65,
+	0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x12, 0x6a, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x12, 0x1b, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1c, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x12, 0x1a, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f,
+	0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x2f, 0x7b, 0x69,
+	0x64, 0x7d, 0x12, 0x73, 0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65,
+	0x72, 0x74, 0x12, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x2a, 0x1a, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72,
+	0x74, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x12, 0x81, 0x01, 0x0a, 0x0d, 0x52, 0x65, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x12, 0x1f, 0x2e, 0x65, 0x78, 0x70, 0x65,
+	0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x65, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x45, 0x78,
+	0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2d, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x27, 0x3a, 0x01, 0x2a, 0x22, 0x22, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31,
+	0x2f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x2f, 0x7b,
+	0x69, 0x64, 0x7d, 0x2f, 0x72, 0x65, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x42, 0x21, 0x5a, 0x1f, 0x70,
+	0x6f, 0x72, 0x74, 0x61, 0x6c, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x6c,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x2f, 0x76, 0x31, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_expert_v1_expert_proto_rawDescOnce sync.Once
+	file_api_expert_v1_expert_proto_rawDescData = file_api_expert_v1_expert_proto_rawDesc
+)
+
+func file_api_expert_v1_expert_proto_rawDescGZIP() []byte {
+	file_api_expert_v1_expert_proto_rawDescOnce.Do(func() {
+		file_api_expert_v1_expert_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_expert_v1_expert_proto_rawDescData)
+	})
+	return file_api_expert_v1_expert_proto_rawDescData
+}
+
+var file_api_expert_v1_expert_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_api_expert_v1_expert_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_api_expert_v1_expert_proto_goTypes = []interface{}{
+	(ExpertLevel)(0),                     // 0: expert.v1.ExpertLevel
+	(ExpertType)(0),                      // 1: expert.v1.ExpertType
+	(ExpertStatus)(0),                    // 2: expert.v1.ExpertStatus
+	(*CreateOrUpdateExpertRequest)(nil),  // 3: expert.v1.CreateOrUpdateExpertRequest
+	(*CreateOrUpdateExpertResponse)(nil), // 4: expert.v1.CreateOrUpdateExpertResponse
+	(*QueryExpertRequest)(nil),           // 5: expert.v1.QueryExpertRequest
+	(*QueryExpertResponse)(nil),          // 6: expert.v1.QueryExpertResponse
+	(*Passage)(nil),                      // 7: expert.v1.Passage
+	(*Claim)(nil),                        // 8: expert.v1.Claim
+	(*AnswerVerification)(nil),           // 9: expert.v1.AnswerVerification
+	(*GetContentVersionsRequest)(nil),    // 10: expert.v1.GetContentVersionsRequest
+	(*GetContentVersionsResponse)(nil),   // 11: expert.v1.GetContentVersionsResponse
+	(*Expert)(nil),                       // 12: expert.v1.Expert
+	(*ListExpertsRequest)(nil),           // 13: expert.v1.ListExpertsRequest
+	(*ListExpertsResponse)(nil),          // 14: expert.v1.ListExpertsResponse
+	(*GetExpertRequest)(nil),             // 15: expert.v1.GetExpertRequest
+	(*GetExpertResponse)(nil),            // 16: expert.v1.GetExpertResponse
+	(*DeleteExpertRequest)(nil),          // 17: expert.v1.DeleteExpertRequest
+	(*DeleteExpertResponse)(nil),         // 18: expert.v1.DeleteExpertResponse
+	(*ReindexExpertRequest)(nil),         // 19: expert.v1.ReindexExpertRequest
+	(*ReindexExpertResponse)(nil),        // 20: expert.v1.ReindexExpertResponse
+	nil,                                  // 21: expert.v1.GetContentVersionsResponse.VersionsEntry
+	nil,                                  // 22: expert.v1.GetExpertResponse.EmbeddedChunkCountsEntry
+	(*timestamppb.Timestamp)(nil),        // 23: google.protobuf.Timestamp
+}
+var file_api_expert_v1_expert_proto_depIdxs = []int32{
+	1,  // 0: expert.v1.CreateOrUpdateExpertRequest.expert_type:type_name -> expert.v1.ExpertType
+	2,  // 1: expert.v1.QueryExpertResponse.status:type_name -> expert.v1.ExpertStatus
+	9,  // 2: expert.v1.QueryExpertResponse.verification:type_name -> expert.v1.AnswerVerification
+	7,  // 3: expert.v1.QueryExpertResponse.passages:type_name -> expert.v1.Passage
+	8,  // 4: expert.v1.AnswerVerification.claims:type_name -> expert.v1.Claim
+	21, // 5: expert.v1.GetContentVersionsResponse.versions:type_name -> expert.v1.GetContentVersionsResponse.VersionsEntry
+	0,  // 6: expert.v1.Expert.level:type_name -> expert.v1.ExpertLevel
+	1,  // 7: expert.v1.Expert.expert_type:type_name -> expert.v1.ExpertType
+	23, // 8: expert.v1.Expert.created_at:type_name -> google.protobuf.Timestamp
+	23, // 9: expert.v1.Expert.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 10: expert.v1.ListExpertsRequest.expert_type:type_name -> expert.v1.ExpertType
+	23, // 11: expert.v1.ListExpertsRequest.updated_before:type_name -> google.protobuf.Timestamp
+	23, // 12: expert.v1.ListExpertsRequest.updated_after:type_name -> google.protobuf.Timestamp
+	0,  // 13: expert.v1.ListExpertsRequest.level:type_name -> expert.v1.ExpertLevel
+	12, // 14: expert.v1.ListExpertsResponse.experts:type_name -> expert.v1.Expert
+	12, // 15: expert.v1.GetExpertResponse.expert:type_name -> expert.v1.Expert
+	22, // 16: expert.v1.GetExpertResponse.embedded_chunk_counts:type_name -> expert.v1.GetExpertResponse.EmbeddedChunkCountsEntry
+	12, // 17: expert.v1.GetExpertResponse.parents:type_name -> expert.v1.Expert
+	12, // 18: expert.v1.GetExpertResponse.children:type_name -> expert.v1.Expert
+	3,  // 19: expert.v1.ExpertService.CreateOrUpdateExpert:input_type -> expert.v1.CreateOrUpdateExpertRequest
+	5,  // 20: expert.v1.ExpertService.QueryExpert:input_type -> expert.v1.QueryExpertRequest
+	10, // 21: expert.v1.ExpertService.GetContentVersions:input_type -> expert.v1.GetContentVersionsRequest
+	13, // 22: expert.v1.ExpertService.ListExperts:input_type -> expert.v1.ListExpertsRequest
+	15, // 23: expert.v1.ExpertService.GetExpert:input_type -> expert.v1.GetExpertRequest
+	17, // 24: expert.v1.ExpertService.DeleteExpert:input_type -> expert.v1.DeleteExpertRequest
+	19, // 25: expert.v1.ExpertService.ReindexExpert:input_type -> expert.v1.ReindexExpertRequest
+	4,  // 26: expert.v1.ExpertService.CreateOrUpdateExpert:output_type -> expert.v1.CreateOrUpdateExpertResponse
+	6,  // 27: expert.v1.ExpertService.QueryExpert:output_type -> expert.v1.QueryExpertResponse
+	11, // 28: expert.v1.ExpertService.GetContentVersions:output_type -> expert.v1.GetContentVersionsResponse
+	14, // 29: expert.v1.ExpertService.ListExperts:output_type -> expert.v1.ListExpertsResponse
+	16, // 30: expert.v1.ExpertService.GetExpert:output_type -> expert.v1.GetExpertResponse
+	18, // 31: expert.v1.ExpertService.DeleteExpert:output_type -> expert.v1.DeleteExpertResponse
+	20, // 32: expert.v1.ExpertService.ReindexExpert:output_type -> expert.v1.ReindexExpertResponse
+	26, // [26:33] is the sub-list for method output_type
+	19, // [19:26] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_api_expert_v1_expert_proto_init() }
+func file_api_expert_v1_expert_proto_init() {
+	if File_api_expert_v1_expert_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_api_expert_v1_expert_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateOrUpdateExpertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateOrUpdateExpertResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryExpertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryExpertResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Passage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Claim); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnswerVerification); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetContentVersionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetContentVersionsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Expert); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListExpertsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListExpertsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetExpertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetExpertResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteExpertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteExpertResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReindexExpertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_expert_v1_expert_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReindexExpertResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -423,8 +1958,8 @@ func file_api_expert_v1_expert_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_expert_v1_expert_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   4,
+			NumEnums:      3,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
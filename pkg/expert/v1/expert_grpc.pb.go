@@ -21,6 +21,11 @@ const _ = grpc.SupportPackageIsVersion8
 const (
 	ExpertService_CreateOrUpdateExpert_FullMethodName = "/expert.v1.ExpertService/CreateOrUpdateExpert"
 	ExpertService_QueryExpert_FullMethodName          = "/expert.v1.ExpertService/QueryExpert"
+	ExpertService_GetContentVersions_FullMethodName   = "/expert.v1.ExpertService/GetContentVersions"
+	ExpertService_ListExperts_FullMethodName          = "/expert.v1.ExpertService/ListExperts"
+	ExpertService_GetExpert_FullMethodName            = "/expert.v1.ExpertService/GetExpert"
+	ExpertService_DeleteExpert_FullMethodName         = "/expert.v1.ExpertService/DeleteExpert"
+	ExpertService_ReindexExpert_FullMethodName        = "/expert.v1.ExpertService/ReindexExpert"
 )
 
 // ExpertServiceClient is the client API for ExpertService service.
@@ -30,9 +35,26 @@ const (
 // ExpertService manages the lifecycle and querying of Leaf Experts.
 type ExpertServiceClient interface {
 	// CreateOrUpdateExpert creates a new expert or updates an existing one.
+	// Its route is for administrators, as it sets the content answers are
+	// drawn from.
 	CreateOrUpdateExpert(ctx context.Context, in *CreateOrUpdateExpertRequest, opts ...grpc.CallOption) (*CreateOrUpdateExpertResponse, error)
 	// QueryExpert gets a response from a specific Leaf Expert.
 	QueryExpert(ctx context.Context, in *QueryExpertRequest, opts ...grpc.CallOption) (*QueryExpertResponse, error)
+	// GetContentVersions returns the content version of the experts for the
+	// given URLs, which callers include in the keys of cached results. It has
+	// no REST route.
+	GetContentVersions(ctx context.Context, in *GetContentVersionsRequest, opts ...grpc.CallOption) (*GetContentVersionsResponse, error)
+	// ListExperts lists experts, newest first, a page at a time.
+	ListExperts(ctx context.Context, in *ListExpertsRequest, opts ...grpc.CallOption) (*ListExpertsResponse, error)
+	// GetExpert returns an expert with its chunk counts and its place in the
+	// hierarchy.
+	GetExpert(ctx context.Context, in *GetExpertRequest, opts ...grpc.CallOption) (*GetExpertResponse, error)
+	// DeleteExpert deletes an expert with its chunks, embeddings and links in
+	// the hierarchy.
+	DeleteExpert(ctx context.Context, in *DeleteExpertRequest, opts ...grpc.CallOption) (*DeleteExpertResponse, error)
+	// ReindexExpert asks the crawler to fetch the expert's page again, which
+	// replaces its content and chunks once the page has been indexed.
+	ReindexExpert(ctx context.Context, in *ReindexExpertRequest, opts ...grpc.CallOption) (*ReindexExpertResponse, error)
 }
 
 type expertServiceClient struct {
@@ -63,6 +85,56 @@ func (c *expertServiceClient) QueryExpert(ctx context.Context, in *QueryExpertRe
 	return out, nil
 }
 
+func (c *expertServiceClient) GetContentVersions(ctx context.Context, in *GetContentVersionsRequest, opts ...grpc.CallOption) (*GetContentVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetContentVersionsResponse)
+	err := c.cc.Invoke(ctx, ExpertService_GetContentVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *expertServiceClient) ListExperts(ctx context.Context, in *ListExpertsRequest, opts ...grpc.CallOption) (*ListExpertsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListExpertsResponse)
+	err := c.cc.Invoke(ctx, ExpertService_ListExperts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *expertServiceClient) GetExpert(ctx context.Context, in *GetExpertRequest, opts ...grpc.CallOption) (*GetExpertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetExpertResponse)
+	err := c.cc.Invoke(ctx, ExpertService_GetExpert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *expertServiceClient) DeleteExpert(ctx context.Context, in *DeleteExpertRequest, opts ...grpc.CallOption) (*DeleteExpertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteExpertResponse)
+	err := c.cc.Invoke(ctx, ExpertService_DeleteExpert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *expertServiceClient) ReindexExpert(ctx context.Context, in *ReindexExpertRequest, opts ...grpc.CallOption) (*ReindexExpertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReindexExpertResponse)
+	err := c.cc.Invoke(ctx, ExpertService_ReindexExpert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ExpertServiceServer is the server API for ExpertService service.
 // All implementations must embed UnimplementedExpertServiceServer
 // for forward compatibility
@@ -70,9 +142,26 @@ func (c *expertServiceClient) QueryExpert(ctx context.Context, in *QueryExpertRe
 // ExpertService manages the lifecycle and querying of Leaf Experts.
 type ExpertServiceServer interface {
 	// CreateOrUpdateExpert creates a new expert or updates an existing one.
+	// Its route is for administrators, as it sets the content answers are
+	// drawn from.
 	CreateOrUpdateExpert(context.Context, *CreateOrUpdateExpertRequest) (*CreateOrUpdateExpertResponse, error)
 	// QueryExpert gets a response from a specific Leaf Expert.
 	QueryExpert(context.Context, *QueryExpertRequest) (*QueryExpertResponse, error)
+	// GetContentVersions returns the content version of the experts for the
+	// given URLs, which callers include in the keys of cached results. It has
+	// no REST route.
+	GetContentVersions(context.Context, *GetContentVersionsRequest) (*GetContentVersionsResponse, error)
+	// ListExperts lists experts, newest first, a page at a time.
+	ListExperts(context.Context, *ListExpertsRequest) (*ListExpertsResponse, error)
+	// GetExpert returns an expert with its chunk counts and its place in the
+	// hierarchy.
+	GetExpert(context.Context, *GetExpertRequest) (*GetExpertResponse, error)
+	// DeleteExpert deletes an expert with its chunks, embeddings and links in
+	// the hierarchy.
+	DeleteExpert(context.Context, *DeleteExpertRequest) (*DeleteExpertResponse, error)
+	// ReindexExpert asks the crawler to fetch the expert's page again, which
+	// replaces its content and chunks once the page has been indexed.
+	ReindexExpert(context.Context, *ReindexExpertRequest) (*ReindexExpertResponse, error)
 	mustEmbedUnimplementedExpertServiceServer()
 }
 
@@ -86,6 +175,21 @@ func (UnimplementedExpertServiceServer) CreateOrUpdateExpert(context.Context, *C
 func (UnimplementedExpertServiceServer) QueryExpert(context.Context, *QueryExpertRequest) (*QueryExpertResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryExpert not implemented")
 }
+func (UnimplementedExpertServiceServer) GetContentVersions(context.Context, *GetContentVersionsRequest) (*GetContentVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContentVersions not implemented")
+}
+func (UnimplementedExpertServiceServer) ListExperts(context.Context, *ListExpertsRequest) (*ListExpertsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListExperts not implemented")
+}
+func (UnimplementedExpertServiceServer) GetExpert(context.Context, *GetExpertRequest) (*GetExpertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExpert not implemented")
+}
+func (UnimplementedExpertServiceServer) DeleteExpert(context.Context, *DeleteExpertRequest) (*DeleteExpertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteExpert not implemented")
+}
+func (UnimplementedExpertServiceServer) ReindexExpert(context.Context, *ReindexExpertRequest) (*ReindexExpertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReindexExpert not implemented")
+}
 func (UnimplementedExpertServiceServer) mustEmbedUnimplementedExpertServiceServer() {}
 
 // UnsafeExpertServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -135,6 +239,96 @@ func _ExpertService_QueryExpert_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ExpertService_GetContentVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContentVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExpertServiceServer).GetContentVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExpertService_GetContentVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExpertServiceServer).GetContentVersions(ctx, req.(*GetContentVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExpertService_ListExperts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListExpertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExpertServiceServer).ListExperts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExpertService_ListExperts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExpertServiceServer).ListExperts(ctx, req.(*ListExpertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExpertService_GetExpert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetExpertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExpertServiceServer).GetExpert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExpertService_GetExpert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExpertServiceServer).GetExpert(ctx, req.(*GetExpertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExpertService_DeleteExpert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteExpertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExpertServiceServer).DeleteExpert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExpertService_DeleteExpert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExpertServiceServer).DeleteExpert(ctx, req.(*DeleteExpertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExpertService_ReindexExpert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReindexExpertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExpertServiceServer).ReindexExpert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExpertService_ReindexExpert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExpertServiceServer).ReindexExpert(ctx, req.(*ReindexExpertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ExpertService_ServiceDesc is the grpc.ServiceDesc for ExpertService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -150,6 +344,26 @@ var ExpertService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "QueryExpert",
 			Handler:    _ExpertService_QueryExpert_Handler,
 		},
+		{
+			MethodName: "GetContentVersions",
+			Handler:    _ExpertService_GetContentVersions_Handler,
+		},
+		{
+			MethodName: "ListExperts",
+			Handler:    _ExpertService_ListExperts_Handler,
+		},
+		{
+			MethodName: "GetExpert",
+			Handler:    _ExpertService_GetExpert_Handler,
+		},
+		{
+			MethodName: "DeleteExpert",
+			Handler:    _ExpertService_DeleteExpert_Handler,
+		},
+		{
+			MethodName: "ReindexExpert",
+			Handler:    _ExpertService_ReindexExpert_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/expert/v1/expert.proto",
@@ -0,0 +1,415 @@
+//go:build e2e
+
+package tests
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq" // Postgres driver
+	"github.com/nats-io/nats-server/v2/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// The harness runs a whole Portal deployment on this machine: an embedded
+// NATS server, a Postgres database, and every service built from this
+// repository and started on free localhost ports. Nothing is shared with the
+// Docker Compose stack, so tests can run alongside it.
+//
+// Postgres, with the pgvector extension, comes from one of:
+//
+//   - PORTAL_E2E_DATABASE_URL, the postgres:// URL of a server the tests
+//     create and drop a database in;
+//   - an ephemeral instance started from the initdb and pg_ctl binaries of
+//     PORTAL_E2E_PG_BIN, the PATH, or /usr/lib/postgresql/*/bin.
+//
+// Tests are skipped if neither is available. A server given by
+// PORTAL_E2E_DATABASE_URL must have pgvector, or the tests fail, so the e2e
+// job of .github/workflows/ci.yml, which sets it, always runs them.
+
+// readyTimeout bounds the start of each service.
+const readyTimeout = time.Minute
+
+// startNATS starts an embedded NATS server and returns its URL.
+func startNATS(t *testing.T) string {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatalf("failed to create NATS server: %v", err)
+	}
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+	if !ns.ReadyForConnections(10 * time.Second) {
+		t.Fatal("NATS server did not start")
+	}
+	return ns.ClientURL()
+}
+
+// startPostgres returns the connection string of an empty database, and a
+// connection to it. The database is dropped when the test ends.
+func startPostgres(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	admin := os.Getenv("PORTAL_E2E_DATABASE_URL")
+	if admin == "" {
+		admin = startEphemeralPostgres(t)
+	}
+	u, err := url.Parse(admin)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		t.Fatalf("PORTAL_E2E_DATABASE_URL must be a postgres:// URL, got %q", admin)
+	}
+	adminDB, err := sql.Open("postgres", admin)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { adminDB.Close() })
+
+	name := "portal_e2e_" + randomHex(t, 4)
+	if _, err := adminDB.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := adminDB.Exec("DROP DATABASE IF EXISTS " + name + " WITH (FORCE)"); err != nil {
+			t.Logf("failed to drop database %s: %v", name, err)
+		}
+	})
+	u.Path = "/" + name
+	dsn := u.String()
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector"); err != nil {
+		if os.Getenv("PORTAL_E2E_DATABASE_URL") != "" {
+			t.Fatalf("the pgvector extension is not available on PORTAL_E2E_DATABASE_URL: %v", err)
+		}
+		t.Skipf("the pgvector extension is not available: %v", err)
+	}
+	return dsn, db
+}
+
+// startEphemeralPostgres initializes and starts a throwaway Postgres
+// instance, and returns the URL of its postgres database.
+func startEphemeralPostgres(t *testing.T) string {
+	t.Helper()
+	bin := postgresBin()
+	if bin == "" {
+		t.Skip("no Postgres available: set PORTAL_E2E_DATABASE_URL, or install Postgres with pgvector")
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("initdb cannot run as root: set PORTAL_E2E_DATABASE_URL instead")
+	}
+	// The socket path of the data directory must stay short.
+	dir, err := os.MkdirTemp("", "portal-pg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	data := filepath.Join(dir, "data")
+	run(t, filepath.Join(bin, "initdb"), "-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-locale", "-N")
+
+	port := freePort(t)
+	run(t, filepath.Join(bin, "pg_ctl"), "start", "-D", data, "-l", filepath.Join(dir, "postgres.log"), "-w", "-t", "60",
+		"-o", fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir))
+	t.Cleanup(func() {
+		if out, err := exec.Command(filepath.Join(bin, "pg_ctl"), "stop", "-D", data, "-m", "immediate").CombinedOutput(); err != nil {
+			t.Logf("failed to stop Postgres: %v: %s", err, out)
+		}
+	})
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port)
+}
+
+// postgresBin returns the directory of the initdb and pg_ctl binaries, or
+// "" if there are none.
+func postgresBin() string {
+	if dir := os.Getenv("PORTAL_E2E_PG_BIN"); dir != "" {
+		return dir
+	}
+	if path, err := exec.LookPath("pg_ctl"); err == nil {
+		return filepath.Dir(path)
+	}
+	// Debian and Ubuntu keep the binaries of each version off the PATH.
+	dirs, _ := filepath.Glob("/usr/lib/postgresql/*/bin")
+	sort.Slice(dirs, func(i, j int) bool { return version(dirs[i]) > version(dirs[j]) })
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, "initdb")); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// version returns the version of a /usr/lib/postgresql/<version>/bin path.
+func version(dir string) int {
+	v, _ := strconv.Atoi(filepath.Base(filepath.Dir(dir)))
+	return v
+}
+
+// run runs a command to completion, failing the test if it fails.
+func run(t *testing.T, name string, args ...string) {
+	t.Helper()
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		t.Fatalf("%s failed: %v\n%s", filepath.Base(name), err, out)
+	}
+}
+
+// services are the commands the harness builds.
+var services = []string{"api-gateway", "query-orchestrator", "expert-service", "rag-service", "indexing-job", "crawler-service"}
+
+// buildServices builds the services into a temporary directory and returns
+// it.
+func buildServices(t *testing.T) string {
+	t.Helper()
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	args := []string{"build", "-o", bin + string(filepath.Separator)}
+	for _, name := range services {
+		args = append(args, "./cmd/"+name)
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build services: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// freePort returns a localhost port nothing listens on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func randomHex(t *testing.T, n int) string {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// process is a service running for a test.
+type process struct {
+	name string
+	log  string
+	done chan struct{}
+	err  error
+}
+
+// start starts a service with the options every service shares in tests:
+// text logs, no metrics endpoint and a short shutdown. The service is
+// stopped when the test ends, and its log is printed if the test failed.
+func start(t *testing.T, bin, name string, args ...string) *process {
+	t.Helper()
+	p := &process{name: name, log: filepath.Join(t.TempDir(), name+".log"), done: make(chan struct{})}
+	out, err := os.Create(p.log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args = append([]string{"-log-format=text", "-log-level=debug", "-metrics-addr=", "-shutdown-timeout=5s"}, args...)
+	cmd := exec.Command(filepath.Join(bin, name), args...)
+	cmd.Stdout, cmd.Stderr = out, out
+	// Options set in the environment of the test must not leak in.
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "PORTAL_") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start %s: %v", name, err)
+	}
+	go func() {
+		p.err = cmd.Wait()
+		out.Close()
+		close(p.done)
+	}()
+	t.Cleanup(func() {
+		cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-p.done:
+		case <-time.After(15 * time.Second):
+			cmd.Process.Kill()
+			<-p.done
+		}
+		if t.Failed() {
+			t.Logf("--- %s log ---\n%s", name, p.tail(100))
+		}
+	})
+	return p
+}
+
+// tail returns the last n lines of the process's log.
+func (p *process) tail(n int) string {
+	f, err := os.Open(p.log)
+	if err != nil {
+		return err.Error()
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return strings.Join(lines[max(0, len(lines)-n):], "\n")
+}
+
+// waitFor polls ready until it succeeds, failing the test if the process
+// exits or readyTimeout expires first.
+func (p *process) waitFor(t *testing.T, ready func(ctx context.Context) error) {
+	t.Helper()
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := ready(ctx)
+		cancel()
+		if err == nil {
+			return
+		}
+		select {
+		case <-p.done:
+			t.Fatalf("%s exited before it was ready: %v\n%s", p.name, p.err, p.tail(30))
+		case <-time.After(200 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s was not ready within %s: %v\n%s", p.name, readyTimeout, err, p.tail(30))
+		}
+	}
+}
+
+// waitGRPC waits until the gRPC service at addr reports it is serving.
+func (p *process) waitGRPC(t *testing.T, addr string) {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	p.waitFor(t, func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return errors.New(res.Status.String())
+		}
+		return nil
+	})
+}
+
+// waitHTTP waits until url answers 200 OK.
+func (p *process) waitHTTP(t *testing.T, url string) {
+	t.Helper()
+	p.waitFor(t, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// stack is a Portal deployment started by the harness.
+type stack struct {
+	// GatewayURL is the base URL of the API Gateway.
+	GatewayURL string
+	// DB is a connection to the services' database.
+	DB *sql.DB
+}
+
+// stackOptions configure a stack.
+type stackOptions struct {
+	// StartURL is the page the crawler starts from. Only its host is
+	// crawled.
+	StartURL string
+	// RAGThreshold is the length above which pages get a RAG expert.
+	RAGThreshold int
+}
+
+// startStack starts every service, in dependency order, and waits until
+// each is ready. The crawler starts last, once the indexing job listens for
+// the pages it publishes.
+func startStack(t *testing.T, opts stackOptions) *stack {
+	t.Helper()
+	natsURL := startNATS(t)
+	dsn, db := startPostgres(t)
+	bin := buildServices(t)
+	startURL, err := url.Parse(opts.StartURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ragAddr := fmt.Sprintf("127.0.0.1:%d", freePort(t))
+	rag := start(t, bin, "rag-service", "-grpc-port="+port(ragAddr), "-db-conn="+dsn, "-auto-migrate")
+	rag.waitGRPC(t, ragAddr)
+
+	expertAddr := fmt.Sprintf("127.0.0.1:%d", freePort(t))
+	expert := start(t, bin, "expert-service", "-grpc-port="+port(expertAddr), "-db-conn="+dsn, "-auto-migrate",
+		"-rag-svc-addr="+ragAddr, "-nats-url="+natsURL)
+	expert.waitGRPC(t, expertAddr)
+
+	orchAddr := fmt.Sprintf("127.0.0.1:%d", freePort(t))
+	orch := start(t, bin, "query-orchestrator", "-grpc-port="+port(orchAddr), "-expert-svc-addr="+expertAddr,
+		"-search-budget=30s", "-hedge-quantile=0")
+	orch.waitGRPC(t, orchAddr)
+
+	gatewayURL := fmt.Sprintf("http://127.0.0.1:%d", freePort(t))
+	gateway := start(t, bin, "api-gateway", "-http-port="+port(gatewayURL), "-expert-svc-addr="+expertAddr,
+		"-orch-svc-addr="+orchAddr, "-nats-url="+natsURL, "-ip-rate=1000", "-ip-burst=1000")
+	gateway.waitHTTP(t, gatewayURL+"/readyz")
+
+	indexingHealth := fmt.Sprintf("127.0.0.1:%d", freePort(t))
+	indexing := start(t, bin, "indexing-job", "-nats-url="+natsURL, "-expert-svc-addr="+expertAddr,
+		"-rag-threshold="+strconv.Itoa(opts.RAGThreshold), "-health-addr="+indexingHealth)
+	indexing.waitHTTP(t, "http://"+indexingHealth+"/readyz")
+
+	crawlerHealth := fmt.Sprintf("127.0.0.1:%d", freePort(t))
+	crawler := start(t, bin, "crawler-service", "-nats-url="+natsURL, "-start-url="+opts.StartURL,
+		"-allowed-domains="+startURL.Hostname(), "-health-addr="+crawlerHealth)
+	crawler.waitHTTP(t, "http://"+crawlerHealth+"/readyz")
+
+	return &stack{GatewayURL: gatewayURL, DB: db}
+}
+
+// port returns the port of a host:port address or an http URL.
+func port(addr string) string {
+	_, p, _ := net.SplitHostPort(strings.TrimPrefix(addr, "http://"))
+	return p
+}